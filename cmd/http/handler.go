@@ -2,16 +2,21 @@ package http
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"errors"
 
+	"github.com/awslabs/aws-lambda-go-api-proxy/core"
 	"github.com/golang-jwt/jwt/v4"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/craguilar/event-management-service/internal/app"
 	"github.com/gorilla/mux"
+	"golang.org/x/exp/slices"
 )
 
 type EventServiceHandler struct {
@@ -20,15 +25,35 @@ type EventServiceHandler struct {
 	guestService       app.GuestService
 	taskService        app.TaskService
 	expenseService     app.ExpenseService
+	commentService     app.CommentService
+	auditService       app.AuditService
+	vendorService      app.VendorService
+	attachmentService  app.AttachmentService
+	scheduleService    app.ScheduleService
+	calendarService    app.CalendarService
+	websiteService     app.WebsiteService
+	campaignService    app.CampaignService
+	outboxService      app.OutboxService
+	preferencesService app.PreferencesService
 }
 
-func NewServiceHandler(event app.EventService, actions app.EventActions, guest app.GuestService, task app.TaskService, expense app.ExpenseService) *EventServiceHandler {
+func NewServiceHandler(event app.EventService, actions app.EventActions, guest app.GuestService, task app.TaskService, expense app.ExpenseService, comment app.CommentService, audit app.AuditService, vendor app.VendorService, attachment app.AttachmentService, schedule app.ScheduleService, calendar app.CalendarService, website app.WebsiteService, campaign app.CampaignService, outbox app.OutboxService, preferences app.PreferencesService) *EventServiceHandler {
 	return &EventServiceHandler{
 		eventService:       event,
 		eventActionService: actions,
 		guestService:       guest,
 		taskService:        task,
 		expenseService:     expense,
+		commentService:     comment,
+		auditService:       audit,
+		vendorService:      vendor,
+		attachmentService:  attachment,
+		scheduleService:    schedule,
+		calendarService:    calendar,
+		websiteService:     website,
+		campaignService:    campaign,
+		outboxService:      outbox,
+		preferencesService: preferences,
 	}
 }
 
@@ -98,8 +123,68 @@ func (c *EventServiceHandler) ListEvent(w http.ResponseWriter, r *http.Request)
 		WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
+	// Archived events are only listed when asked for, ?status=ARCHIVED,COMPLETED filters by state
+	states := []string{}
+	if value := r.URL.Query().Get("status"); value != "" {
+		states = strings.Split(strings.ToUpper(value), ",")
+	}
+	filtered := []*app.EventSummary{}
+	for _, event := range events {
+		if (len(states) == 0 && !event.IsArchived()) || slices.Contains(states, event.State) {
+			filtered = append(filtered, event)
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(filtered))
+}
+
+// TransitionEvent moves the event to the status in the body, {"status": "ARCHIVED"}
+func (c *EventServiceHandler) TransitionEvent(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	vars := mux.Vars(r)
+	eventId, ok := vars["eventId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	request := struct {
+		Status string `json:"status"`
+	}{}
+	if err = json.NewDecoder(r.Body).Decode(&request); err != nil || request.Status == "" {
+		log.Warn("Error when decoding Body", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Body parameter"))
+		return
+	}
+	event, err := c.eventService.Get(eventId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if event == nil {
+		WriteError(w, http.StatusNotFound, nil)
+		return
+	}
+	event.Status = strings.ToUpper(request.Status)
+	event, err = c.eventService.CreateOrUpdate(user, event)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		log.Error("Error when transitioning event ", err)
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
 	w.WriteHeader(http.StatusOK)
-	w.Write(SerializeData(events))
+	w.Write(SerializeData(event))
 }
 
 func (c *EventServiceHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
@@ -127,12 +212,200 @@ func (c *EventServiceHandler) DeleteEvent(w http.ResponseWriter, r *http.Request
 
 func (c *EventServiceHandler) SendNotifications(w http.ResponseWriter, r *http.Request) {
 	log.Info("Hit send notifications")
-	err := c.eventActionService.SendPendingTasksNotifications()
+	report, err := c.eventActionService.SendPendingTasksNotifications()
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(report))
+}
+
+// SendDueNotifications notifies the events whose reminder is due, it is called by the scheduled run
+func (c *EventServiceHandler) SendDueNotifications(w http.ResponseWriter, r *http.Request) {
+	log.Info("Hit send due notifications")
+	report, err := c.eventActionService.SendDueNotifications(time.Now())
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(report))
+}
+
+// DispatchOutbox sends the outbox messages due, it is called by the scheduled run
+func (c *EventServiceHandler) DispatchOutbox(w http.ResponseWriter, r *http.Request) {
+	log.Info("Hit dispatch outbox")
+	report, err := c.outboxService.Dispatch(time.Now())
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(report))
+}
+
+// ListOutbox returns the outbox messages, filtered by the status query parameter if any
+func (c *EventServiceHandler) ListOutbox(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	messages, err := c.outboxService.List(user, r.URL.Query().Get("status"))
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not an outbox admin"))
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(messages))
+}
+
+func (c *EventServiceHandler) GetOutboxMessage(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	vars := mux.Vars(r)
+	messageId, ok := vars["messageId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	message, err := c.outboxService.Get(user, messageId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not an outbox admin"))
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if message == nil {
+		WriteError(w, http.StatusNotFound, nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(message))
+}
+
+// ReplayOutboxMessage sends again a failed or dead outbox message
+func (c *EventServiceHandler) ReplayOutboxMessage(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	vars := mux.Vars(r)
+	messageId, ok := vars["messageId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	message, err := c.outboxService.Replay(user, messageId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not an outbox admin"))
+		return
+	}
+	if err != nil {
+		log.Error("Error when replaying outbox message ", err)
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if message == nil {
+		WriteError(w, http.StatusNotFound, nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(message))
+}
+
+// Notification preferences of the caller
+func (c *EventServiceHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	preferences, err := c.preferencesService.Get(user)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(preferences))
+}
+
+func (c *EventServiceHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	var preferences app.NotificationPreferences
+	err = json.NewDecoder(r.Body).Decode(&preferences)
+	if err != nil {
+		log.Warn("Error when decoding Body", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Body parameter"))
+		return
+	}
+	updated, err := c.preferencesService.CreateOrUpdate(user, &preferences)
+	if err != nil {
+		log.Error("Error when updating preferences ", err)
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(updated))
+}
+
+// ConfirmUnsubscribe is the page of the link in the email, a GET never unsubscribes as mail clients
+// and link scanners follow links (RFC 8058). The form in the page posts the token to Unsubscribe.
+func (c *EventServiceHandler) ConfirmUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	page, err := app.TemplateUnsubscribe(r.URL.Query().Get("token"))
+	if err != nil {
+		log.Error("Error when rendering unsubscribe ", err)
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(page.Bytes())
+}
+
+// Unsubscribe is public, the token query parameter is signed so it only unsubscribes the email it was
+// sent to. It is the one-click unsubscribe of mail clients (RFC 8058) and the form of ConfirmUnsubscribe.
+func (c *EventServiceHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	err := c.preferencesService.Unsubscribe(r.URL.Query().Get("token"))
+	if errors.Is(err, app.ErrInvalidUnsubscribe) {
+		WriteError(w, http.StatusBadRequest, err)
+		return
+	}
 	if err != nil {
+		log.Error("Error when unsubscribing ", err)
 		WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(map[string]bool{"isUnsubscribed": true}))
 }
 
 func (c *EventServiceHandler) ListOwners(w http.ResponseWriter, r *http.Request) {
@@ -188,108 +461,1533 @@ func (c *EventServiceHandler) AddOwner(w http.ResponseWriter, r *http.Request) {
 	w.Write(SerializeData(sharedEmails))
 }
 
-// Tasks
-
-func (c *EventServiceHandler) AddTask(w http.ResponseWriter, r *http.Request) {
-
-	eventId := r.URL.Query().Get("eventId")
-	if eventId == "" {
-		log.Warn("Expected eventId")
+func (c *EventServiceHandler) CloneEvent(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
 		w.WriteHeader(http.StatusBadRequest)
-		w.Write(SerializeError(http.StatusBadRequest, "Expectde eventId as query parameter"))
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
 		return
 	}
-
-	var task app.Task
-	err := json.NewDecoder(r.Body).Decode(&task)
+	// Body decode
+	var cloneRequest app.CloneEventRequest
+	err = json.NewDecoder(r.Body).Decode(&cloneRequest)
 	if err != nil {
 		log.Warn("Error when decoding Body", err)
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write(SerializeError(http.StatusBadRequest, "Invalid Body parameter"))
 		return
 	}
-	createdTask, err := c.taskService.CreateOrUpdate(eventId, &task)
+	cloned, err := c.eventService.Clone(user, &cloneRequest)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		log.Error("Error when cloning event ", err)
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(cloned))
+}
+
+func (c *EventServiceHandler) GetEventSummary(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	vars := mux.Vars(r)
+	eventId, ok := vars["eventId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	summary, err := c.eventService.Summary(user, eventId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if summary == nil {
+		WriteError(w, http.StatusNotFound, nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(summary))
+}
+
+func (c *EventServiceHandler) ListActivity(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	vars := mux.Vars(r)
+	eventId, ok := vars["eventId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	page := &app.PageRequest{NextToken: r.URL.Query().Get("nextToken")}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		page.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(SerializeError(http.StatusBadRequest, "Expected a numeric limit"))
+			return
+		}
+	}
+	activity, err := c.auditService.List(user, eventId, page)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(activity))
+}
+
+func (c *EventServiceHandler) GetBudget(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	eventId, ok := vars["eventId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	budget, err := c.expenseService.Budget(eventId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if budget == nil {
+		WriteError(w, http.StatusNotFound, nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(budget))
+}
+
+func (c *EventServiceHandler) GetSettlement(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	eventId, ok := vars["eventId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	settlement, err := c.expenseService.Settlement(eventId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if settlement == nil {
+		WriteError(w, http.StatusNotFound, nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(settlement))
+}
+
+// GetGuestCostAnalytics accepts the attendance scenarios to project as seats=100,150
+func (c *EventServiceHandler) GetGuestCostAnalytics(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	vars := mux.Vars(r)
+	eventId, ok := vars["eventId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	scenarios := []int{}
+	if value := r.URL.Query().Get("seats"); value != "" {
+		for _, seats := range strings.Split(value, ",") {
+			scenario, err := strconv.Atoi(strings.TrimSpace(seats))
+			if err != nil || scenario < 0 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write(SerializeError(http.StatusBadRequest, "Expected a comma separated list of seats"))
+				return
+			}
+			scenarios = append(scenarios, scenario)
+		}
+	}
+	// The guest list is authorized, read it before the rest of the event
+	guests, err := c.guestService.List(user, eventId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	event, err := c.eventService.Get(eventId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if event == nil {
+		WriteError(w, http.StatusNotFound, nil)
+		return
+	}
+	categories, err := c.expenseService.List(eventId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(app.NewGuestCostAnalytics(event, categories, guests, scenarios)))
+}
+
+// ListUpcomingPayments returns the unpaid scheduled payments due in the next days (30 by default)
+func (c *EventServiceHandler) ListUpcomingPayments(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	eventId, ok := vars["eventId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	days := 30
+	if value := r.URL.Query().Get("days"); value != "" {
+		var err error
+		days, err = strconv.Atoi(value)
+		if err != nil || days < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(SerializeError(http.StatusBadRequest, "Expected a positive number of days"))
+			return
+		}
+	}
+	payments, err := c.expenseService.UpcomingPayments(eventId, time.Now().AddDate(0, 0, days))
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(payments))
+}
+
+// Tasks
+
+func (c *EventServiceHandler) AddTask(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expectde eventId as query parameter"))
+		return
+	}
+
+	var task app.Task
+	err = json.NewDecoder(r.Body).Decode(&task)
+	if err != nil {
+		log.Warn("Error when decoding Body", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Body parameter"))
+		return
+	}
+	createdTask, err := c.taskService.CreateOrUpdate(user, eventId, &task)
+	if err != nil {
+		log.Error("Error when creating task ", err)
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(createdTask))
+}
+
+func (c *EventServiceHandler) GetTask(w http.ResponseWriter, r *http.Request) {
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	vars := mux.Vars(r)
+	taskId, ok := vars["taskId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	task, err := c.taskService.Get(eventId, taskId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if task == nil {
+		WriteError(w, http.StatusNotFound, nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(task))
+}
+
+func (c *EventServiceHandler) ListTask(w http.ResponseWriter, r *http.Request) {
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warnf("Expected eventId got %s", eventId)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	tasks, err := c.taskService.List(eventId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(tasks))
+}
+
+func (c *EventServiceHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+
+	vars := mux.Vars(r)
+	taskId, ok := vars["taskId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	err = c.taskService.Delete(user, eventId, taskId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Guests
+
+func (c *EventServiceHandler) AddGuest(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	var guest app.Guest
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expectde eventId as query parameter"))
+		return
+	}
+
+	err = json.NewDecoder(r.Body).Decode(&guest)
+	if err != nil {
+		log.Warn("Error when decoding Body", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Body parameter"))
+		return
+	}
+	createdGuest, err := c.guestService.CreateOrUpdate(user, eventId, &guest)
+	if err != nil {
+		log.Error("Error when creating event ", err)
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(createdGuest))
+}
+
+func (c *EventServiceHandler) CopyGuests(w http.ResponseWriter, r *http.Request) {
+
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	// Body decode
+	var copyRequest app.CopyGuestRequest
+	err = json.NewDecoder(r.Body).Decode(&copyRequest)
+	if err != nil {
+		log.Warn("Error when decoding Body", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Body parameter"))
+		return
+	}
+	err = c.guestService.CopyFrom(user, eventId, &copyRequest)
+	if err != nil {
+		log.Error("Error when creating event ", err)
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (c *EventServiceHandler) GetGuest(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expectde eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expectde eventId as query parameter"))
+		return
+	}
+	vars := mux.Vars(r)
+	guestId, ok := vars["guestId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	car, err := c.guestService.Get(user, eventId, guestId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if car == nil {
+		WriteError(w, http.StatusNotFound, nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(car))
+}
+
+func (c *EventServiceHandler) ListGuest(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warnf("Expected eventId got %s", eventId)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	guests, err := c.guestService.List(user, eventId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(guests))
+}
+
+func (c *EventServiceHandler) DeleteGuest(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expectde eventId as query parameter"))
+		return
+	}
+
+	vars := mux.Vars(r)
+	guestId, ok := vars["guestId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	err = c.guestService.Delete(user, eventId, guestId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Expenses
+func (c *EventServiceHandler) AddExpense(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+
+	var expense app.ExpenseCategory
+	err = json.NewDecoder(r.Body).Decode(&expense)
+	if err != nil {
+		log.Warn("Error when decoding Body", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Body parameter"))
+		return
+	}
+	createdExpense, err := c.expenseService.CreateOrUpdate(user, eventId, &expense)
+	if err != nil {
+		log.Error("Error when creating event ", err)
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(createdExpense))
+}
+
+func (c *EventServiceHandler) GetExpense(w http.ResponseWriter, r *http.Request) {
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	vars := mux.Vars(r)
+	expenseId, ok := vars["expenseId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	expense, err := c.expenseService.Get(eventId, expenseId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if expense == nil {
+		WriteError(w, http.StatusNotFound, nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(expense))
+}
+
+func (c *EventServiceHandler) ListExpenses(w http.ResponseWriter, r *http.Request) {
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warnf("Expected eventId got %s", eventId)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	expenses, err := c.expenseService.List(eventId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(expenses))
+}
+
+func (c *EventServiceHandler) DeleteExpense(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expectde eventId as query parameter"))
+		return
+	}
+
+	vars := mux.Vars(r)
+	expenseId, ok := vars["expenseId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	err = c.expenseService.Delete(user, eventId, expenseId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Expense items, individual payments of an expense category
+func (c *EventServiceHandler) AddExpenseItem(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	vars := mux.Vars(r)
+	expenseId, ok := vars["expenseId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+
+	var item app.Expense
+	err = json.NewDecoder(r.Body).Decode(&item)
+	if err != nil {
+		log.Warn("Error when decoding Body", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Body parameter"))
+		return
+	}
+	createdItem, err := c.expenseService.CreateOrUpdateItem(user, eventId, expenseId, &item)
+	if err != nil {
+		log.Error("Error when creating expense item ", err)
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(createdItem))
+}
+
+func (c *EventServiceHandler) GetExpenseItem(w http.ResponseWriter, r *http.Request) {
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	vars := mux.Vars(r)
+	expenseId, ok := vars["expenseId"]
+	itemId, okItem := vars["itemId"]
+	if !ok || !okItem {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	item, err := c.expenseService.GetItem(eventId, expenseId, itemId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if item == nil {
+		WriteError(w, http.StatusNotFound, nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(item))
+}
+
+func (c *EventServiceHandler) ListExpenseItems(w http.ResponseWriter, r *http.Request) {
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warnf("Expected eventId got %s", eventId)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	vars := mux.Vars(r)
+	expenseId, ok := vars["expenseId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	items, err := c.expenseService.ListItems(eventId, expenseId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(items))
+}
+
+func (c *EventServiceHandler) DeleteExpenseItem(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	vars := mux.Vars(r)
+	expenseId, ok := vars["expenseId"]
+	itemId, okItem := vars["itemId"]
+	if !ok || !okItem {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	err = c.expenseService.DeleteItem(user, eventId, expenseId, itemId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Vendors
+func (c *EventServiceHandler) AddVendor(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+
+	var vendor app.Vendor
+	err = json.NewDecoder(r.Body).Decode(&vendor)
+	if err != nil {
+		log.Warn("Error when decoding Body", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Body parameter"))
+		return
+	}
+	createdVendor, err := c.vendorService.CreateOrUpdate(user, eventId, &vendor)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		log.Error("Error when creating vendor ", err)
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(createdVendor))
+}
+
+func (c *EventServiceHandler) GetVendor(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	vars := mux.Vars(r)
+	vendorId, ok := vars["vendorId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	vendor, err := c.vendorService.Get(user, eventId, vendorId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if vendor == nil {
+		WriteError(w, http.StatusNotFound, nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(vendor))
+}
+
+func (c *EventServiceHandler) ListVendors(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warnf("Expected eventId got %s", eventId)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	vendors, err := c.vendorService.List(user, eventId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(vendors))
+}
+
+func (c *EventServiceHandler) DeleteVendor(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	vars := mux.Vars(r)
+	vendorId, ok := vars["vendorId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	err = c.vendorService.Delete(user, eventId, vendorId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (c *EventServiceHandler) ListVendorSummaries(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	vars := mux.Vars(r)
+	eventId, ok := vars["eventId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	summaries, err := c.vendorService.Summary(user, eventId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(summaries))
+}
+
+// Attachments
+func (c *EventServiceHandler) AddScheduleItem(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+
+	var item app.ScheduleItem
+	err = json.NewDecoder(r.Body).Decode(&item)
+	if err != nil {
+		log.Warn("Error when decoding Body", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Body parameter"))
+		return
+	}
+	createdItem, err := c.scheduleService.CreateOrUpdate(user, eventId, &item)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		log.Error("Error when creating schedule item ", err)
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(createdItem))
+}
+
+func (c *EventServiceHandler) GetScheduleItem(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	vars := mux.Vars(r)
+	scheduleId, ok := vars["scheduleId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	item, err := c.scheduleService.Get(user, eventId, scheduleId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if item == nil {
+		WriteError(w, http.StatusNotFound, nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(item))
+}
+
+func (c *EventServiceHandler) ListScheduleItems(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	items, err := c.scheduleService.List(user, eventId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(items))
+}
+
+func (c *EventServiceHandler) DeleteScheduleItem(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	vars := mux.Vars(r)
+	scheduleId, ok := vars["scheduleId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	err = c.scheduleService.Delete(user, eventId, scheduleId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// RespondScheduleItem records the RSVP of a guest to a schedule item
+func (c *EventServiceHandler) RespondScheduleItem(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	vars := mux.Vars(r)
+	scheduleId, ok := vars["scheduleId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	var rsvp app.ScheduleRsvp
+	err = json.NewDecoder(r.Body).Decode(&rsvp)
+	if err != nil {
+		log.Warn("Error when decoding Body", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Body parameter"))
+		return
+	}
+	item, err := c.scheduleService.Respond(user, eventId, scheduleId, &rsvp)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		log.Error("Error when recording RSVP ", err)
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(item))
+}
+
+func (c *EventServiceHandler) AddAttachment(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+
+	var attachment app.Attachment
+	err = json.NewDecoder(r.Body).Decode(&attachment)
+	if err != nil {
+		log.Warn("Error when decoding Body", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Body parameter"))
+		return
+	}
+	createdAttachment, err := c.attachmentService.Create(user, eventId, &attachment)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		log.Error("Error when creating attachment ", err)
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(createdAttachment))
+}
+
+func (c *EventServiceHandler) CompleteAttachment(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	vars := mux.Vars(r)
+	attachmentId, ok := vars["attachmentId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	attachment, err := c.attachmentService.Complete(user, eventId, attachmentId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(attachment))
+}
+
+func (c *EventServiceHandler) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	vars := mux.Vars(r)
+	attachmentId, ok := vars["attachmentId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	attachment, err := c.attachmentService.Get(user, eventId, attachmentId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if attachment == nil {
+		WriteError(w, http.StatusNotFound, nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(attachment))
+}
+
+func (c *EventServiceHandler) ListAttachments(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	entityType := r.URL.Query().Get("entityType")
+	entityId := r.URL.Query().Get("entityId")
+	if eventId == "" || entityType == "" || entityId == "" {
+		log.Warnf("Expected eventId, entityType and entityId got %s %s %s", eventId, entityType, entityId)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId, entityType and entityId as query parameters"))
+		return
+	}
+	attachments, err := c.attachmentService.List(user, eventId, entityType, entityId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(attachments))
+}
+
+func (c *EventServiceHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	vars := mux.Vars(r)
+	attachmentId, ok := vars["attachmentId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	err = c.attachmentService.Delete(user, eventId, attachmentId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// Comments
+
+func (c *EventServiceHandler) AddComment(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	var comment app.Comment
+	err = json.NewDecoder(r.Body).Decode(&comment)
+	if err != nil {
+		log.Warn("Error when decoding Body", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Body parameter"))
+		return
+	}
+	createdComment, err := c.commentService.CreateOrUpdate(user, eventId, &comment)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not the comment author or a valid owner"))
+		return
+	}
+	if err != nil {
+		log.Error("Error when creating comment ", err)
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(createdComment))
+}
+
+func (c *EventServiceHandler) GetComment(w http.ResponseWriter, r *http.Request) {
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	vars := mux.Vars(r)
+	commentId, ok := vars["commentId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	comment, err := c.commentService.Get(eventId, commentId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if comment == nil {
+		WriteError(w, http.StatusNotFound, nil)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(comment))
+}
+
+func (c *EventServiceHandler) ListComments(w http.ResponseWriter, r *http.Request) {
+	eventId := r.URL.Query().Get("eventId")
+	entityId := r.URL.Query().Get("entityId")
+	if eventId == "" || entityId == "" {
+		log.Warnf("Expected eventId and entityId got %s %s", eventId, entityId)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId and entityId as query parameters"))
+		return
+	}
+	comments, err := c.commentService.List(eventId, entityId)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(comments))
+}
+
+func (c *EventServiceHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	eventId := r.URL.Query().Get("eventId")
+	if eventId == "" {
+		log.Warn("Expected eventId")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		return
+	}
+	vars := mux.Vars(r)
+	commentId, ok := vars["commentId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	err = c.commentService.Delete(user, eventId, commentId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not the comment author"))
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetCalendarFeed returns the feed of the event in the eventId query parameter, or of all the events of
+// the user without it.
+func (c *EventServiceHandler) GetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	feed, err := c.calendarService.GetFeed(user, r.URL.Query().Get("eventId"))
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	feed.Path = calendarPath(feed.Token)
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(feed))
+}
+
+func (c *EventServiceHandler) RotateCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	feed, err := c.calendarService.RotateFeed(user, r.URL.Query().Get("eventId"))
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
 	if err != nil {
-		log.Error("Error when creating task ", err)
 		WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
+	feed.Path = calendarPath(feed.Token)
 	w.WriteHeader(http.StatusOK)
-	w.Write(SerializeData(createdTask))
+	w.Write(SerializeData(feed))
 }
 
-func (c *EventServiceHandler) GetTask(w http.ResponseWriter, r *http.Request) {
-	eventId := r.URL.Query().Get("eventId")
-	if eventId == "" {
-		log.Warn("Expected eventId")
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
-		return
-	}
+// GetCalendar is public, calendar clients can not send the Authorization header so the secret token
+// in the path is the only credential.
+func (c *EventServiceHandler) GetCalendar(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	taskId, ok := vars["taskId"]
+	token, ok := vars["token"]
 	if !ok {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
 		return
 	}
-	task, err := c.taskService.Get(eventId, taskId)
+	calendar, err := c.calendarService.Render(token)
 	if err != nil {
 		WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
-	if task == nil {
+	if calendar == nil {
 		WriteError(w, http.StatusNotFound, nil)
 		return
 	}
+	w.Header().Set("Content-Type", "text/calendar; charset=UTF-8")
 	w.WriteHeader(http.StatusOK)
-	w.Write(SerializeData(task))
+	w.Write([]byte(calendar.String()))
 }
 
-func (c *EventServiceHandler) ListTask(w http.ResponseWriter, r *http.Request) {
-	eventId := r.URL.Query().Get("eventId")
-	if eventId == "" {
-		log.Warnf("Expected eventId got %s", eventId)
+// SendInvitations accepts an optional body {"guestIds": []} to invite only some guests
+func (c *EventServiceHandler) SendInvitations(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
 		w.WriteHeader(http.StatusBadRequest)
-		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
+		return
+	}
+	vars := mux.Vars(r)
+	eventId, ok := vars["eventId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	request := struct {
+		GuestIds []string `json:"guestIds"`
+	}{}
+	if r.ContentLength != 0 {
+		if err = json.NewDecoder(r.Body).Decode(&request); err != nil {
+			log.Warn("Error when decoding Body", err)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(SerializeError(http.StatusBadRequest, "Invalid Body parameter"))
+			return
+		}
+	}
+	sent, err := c.calendarService.SendInvitations(user, eventId, request.GuestIds)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
 		return
 	}
-	tasks, err := c.taskService.List(eventId)
 	if err != nil {
+		log.Error("Error when sending invitations ", err)
 		WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
-	w.Write(SerializeData(tasks))
+	w.Write(SerializeData(map[string]int{"sent": sent}))
 }
 
-func (c *EventServiceHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
-	eventId := r.URL.Query().Get("eventId")
-	if eventId == "" {
-		log.Warn("Expected eventId")
+func (c *EventServiceHandler) GetWebsite(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
 		w.WriteHeader(http.StatusBadRequest)
-		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
 		return
 	}
-
 	vars := mux.Vars(r)
-	taskId, ok := vars["taskId"]
+	eventId, ok := vars["eventId"]
 	if !ok {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
 		return
 	}
-	err := c.taskService.Delete(eventId, taskId)
+	website, err := c.websiteService.Get(user, eventId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
 	if err != nil {
 		WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
+	if website == nil {
+		WriteError(w, http.StatusNotFound, nil)
+		return
+	}
 	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(website))
 }
 
-// Guests
-
-func (c *EventServiceHandler) AddGuest(w http.ResponseWriter, r *http.Request) {
+func (c *EventServiceHandler) UpdateWebsite(w http.ResponseWriter, r *http.Request) {
 	user, err := getUser(r)
 	if err != nil {
 		log.Warn("Error when decoding Authorization ", err)
@@ -297,41 +1995,37 @@ func (c *EventServiceHandler) AddGuest(w http.ResponseWriter, r *http.Request) {
 		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
 		return
 	}
-	var guest app.Guest
-	eventId := r.URL.Query().Get("eventId")
-	if eventId == "" {
-		log.Warn("Expected eventId")
+	vars := mux.Vars(r)
+	eventId, ok := vars["eventId"]
+	if !ok {
 		w.WriteHeader(http.StatusBadRequest)
-		w.Write(SerializeError(http.StatusBadRequest, "Expectde eventId as query parameter"))
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
 		return
 	}
-
-	err = json.NewDecoder(r.Body).Decode(&guest)
+	var website app.EventWebsite
+	err = json.NewDecoder(r.Body).Decode(&website)
 	if err != nil {
 		log.Warn("Error when decoding Body", err)
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write(SerializeError(http.StatusBadRequest, "Invalid Body parameter"))
 		return
 	}
-	createdGuest, err := c.guestService.CreateOrUpdate(user, eventId, &guest)
+	updated, err := c.websiteService.CreateOrUpdate(user, eventId, &website)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
 	if err != nil {
-		log.Error("Error when creating event ", err)
+		log.Error("Error when updating website ", err)
 		WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
-	w.Write(SerializeData(createdGuest))
+	w.Write(SerializeData(updated))
 }
 
-func (c *EventServiceHandler) CopyGuests(w http.ResponseWriter, r *http.Request) {
-
-	eventId := r.URL.Query().Get("eventId")
-	if eventId == "" {
-		log.Warn("Expected eventId")
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
-		return
-	}
+// Campaigns
+func (c *EventServiceHandler) AddCampaign(w http.ResponseWriter, r *http.Request) {
 	user, err := getUser(r)
 	if err != nil {
 		log.Warn("Error when decoding Authorization ", err)
@@ -339,25 +2033,36 @@ func (c *EventServiceHandler) CopyGuests(w http.ResponseWriter, r *http.Request)
 		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
 		return
 	}
-	// Body decode
-	var copyRequest app.CopyGuestRequest
-	err = json.NewDecoder(r.Body).Decode(&copyRequest)
+	vars := mux.Vars(r)
+	eventId, ok := vars["eventId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	var campaign app.Campaign
+	err = json.NewDecoder(r.Body).Decode(&campaign)
 	if err != nil {
 		log.Warn("Error when decoding Body", err)
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write(SerializeError(http.StatusBadRequest, "Invalid Body parameter"))
 		return
 	}
-	err = c.guestService.CopyFrom(user, eventId, &copyRequest)
+	created, err := c.campaignService.CreateOrUpdate(user, eventId, &campaign)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
 	if err != nil {
-		log.Error("Error when creating event ", err)
+		log.Error("Error when creating campaign ", err)
 		WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
-	w.WriteHeader(http.StatusAccepted)
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(created))
 }
 
-func (c *EventServiceHandler) GetGuest(w http.ResponseWriter, r *http.Request) {
+func (c *EventServiceHandler) GetCampaign(w http.ResponseWriter, r *http.Request) {
 	user, err := getUser(r)
 	if err != nil {
 		log.Warn("Error when decoding Authorization ", err)
@@ -365,34 +2070,37 @@ func (c *EventServiceHandler) GetGuest(w http.ResponseWriter, r *http.Request) {
 		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
 		return
 	}
-	eventId := r.URL.Query().Get("eventId")
-	if eventId == "" {
-		log.Warn("Expectde eventId")
+	vars := mux.Vars(r)
+	eventId, ok := vars["eventId"]
+	if !ok {
 		w.WriteHeader(http.StatusBadRequest)
-		w.Write(SerializeError(http.StatusBadRequest, "Expectde eventId as query parameter"))
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
 		return
 	}
-	vars := mux.Vars(r)
-	guestId, ok := vars["guestId"]
+	campaignId, ok := vars["campaignId"]
 	if !ok {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
 		return
 	}
-	car, err := c.guestService.Get(user, eventId, guestId)
+	campaign, err := c.campaignService.Get(user, eventId, campaignId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
 	if err != nil {
 		WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
-	if car == nil {
+	if campaign == nil {
 		WriteError(w, http.StatusNotFound, nil)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
-	w.Write(SerializeData(car))
+	w.Write(SerializeData(campaign))
 }
 
-func (c *EventServiceHandler) ListGuest(w http.ResponseWriter, r *http.Request) {
+func (c *EventServiceHandler) ListCampaigns(w http.ResponseWriter, r *http.Request) {
 	user, err := getUser(r)
 	if err != nil {
 		log.Warn("Error when decoding Authorization ", err)
@@ -400,23 +2108,27 @@ func (c *EventServiceHandler) ListGuest(w http.ResponseWriter, r *http.Request)
 		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
 		return
 	}
-	eventId := r.URL.Query().Get("eventId")
-	if eventId == "" {
-		log.Warnf("Expected eventId got %s", eventId)
+	vars := mux.Vars(r)
+	eventId, ok := vars["eventId"]
+	if !ok {
 		w.WriteHeader(http.StatusBadRequest)
-		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	campaigns, err := c.campaignService.List(user, eventId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
 		return
 	}
-	guests, err := c.guestService.List(user, eventId)
 	if err != nil {
 		WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
-	w.Write(SerializeData(guests))
+	w.Write(SerializeData(campaigns))
 }
 
-func (c *EventServiceHandler) DeleteGuest(w http.ResponseWriter, r *http.Request) {
+func (c *EventServiceHandler) DeleteCampaign(w http.ResponseWriter, r *http.Request) {
 	user, err := getUser(r)
 	if err != nil {
 		log.Warn("Error when decoding Authorization ", err)
@@ -424,22 +2136,24 @@ func (c *EventServiceHandler) DeleteGuest(w http.ResponseWriter, r *http.Request
 		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
 		return
 	}
-	eventId := r.URL.Query().Get("eventId")
-	if eventId == "" {
-		log.Warn("Expected eventId")
+	vars := mux.Vars(r)
+	eventId, ok := vars["eventId"]
+	if !ok {
 		w.WriteHeader(http.StatusBadRequest)
-		w.Write(SerializeError(http.StatusBadRequest, "Expectde eventId as query parameter"))
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
 		return
 	}
-
-	vars := mux.Vars(r)
-	guestId, ok := vars["guestId"]
+	campaignId, ok := vars["campaignId"]
 	if !ok {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
 		return
 	}
-	err = c.guestService.Delete(user, eventId, guestId)
+	err = c.campaignService.Delete(user, eventId, campaignId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
 	if err != nil {
 		WriteError(w, http.StatusInternalServerError, err)
 		return
@@ -447,102 +2161,165 @@ func (c *EventServiceHandler) DeleteGuest(w http.ResponseWriter, r *http.Request
 	w.WriteHeader(http.StatusOK)
 }
 
-// Expenses
-func (c *EventServiceHandler) AddExpense(w http.ResponseWriter, r *http.Request) {
-
-	eventId := r.URL.Query().Get("eventId")
-	if eventId == "" {
-		log.Warn("Expected eventId")
+// SendCampaign emails the audience of a draft campaign
+func (c *EventServiceHandler) SendCampaign(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
 		w.WriteHeader(http.StatusBadRequest)
-		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
 		return
 	}
-
-	var expense app.ExpenseCategory
-	err := json.NewDecoder(r.Body).Decode(&expense)
-	if err != nil {
-		log.Warn("Error when decoding Body", err)
+	vars := mux.Vars(r)
+	eventId, ok := vars["eventId"]
+	if !ok {
 		w.WriteHeader(http.StatusBadRequest)
-		w.Write(SerializeError(http.StatusBadRequest, "Invalid Body parameter"))
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	campaignId, ok := vars["campaignId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
+		return
+	}
+	campaign, err := c.campaignService.Send(user, eventId, campaignId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
 		return
 	}
-	createdExpense, err := c.expenseService.CreateOrUpdate(eventId, &expense)
 	if err != nil {
-		log.Error("Error when creating event ", err)
+		log.Error("Error when sending campaign ", err)
 		WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
-	w.Write(SerializeData(createdExpense))
+	w.Write(SerializeData(campaign))
 }
 
-func (c *EventServiceHandler) GetExpense(w http.ResponseWriter, r *http.Request) {
-	eventId := r.URL.Query().Get("eventId")
-	if eventId == "" {
-		log.Warn("Expected eventId")
+// ResendCampaign emails again the recipients a campaign failed to
+func (c *EventServiceHandler) ResendCampaign(w http.ResponseWriter, r *http.Request) {
+	user, err := getUser(r)
+	if err != nil {
+		log.Warn("Error when decoding Authorization ", err)
 		w.WriteHeader(http.StatusBadRequest)
-		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Authorization header"))
 		return
 	}
 	vars := mux.Vars(r)
-	expenseId, ok := vars["expenseId"]
+	eventId, ok := vars["eventId"]
 	if !ok {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
 		return
 	}
-	expense, err := c.expenseService.Get(eventId, expenseId)
-	if err != nil {
-		WriteError(w, http.StatusInternalServerError, err)
+	campaignId, ok := vars["campaignId"]
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
 		return
 	}
-	if expense == nil {
-		WriteError(w, http.StatusNotFound, nil)
+	campaign, err := c.campaignService.Resend(user, eventId, campaignId)
+	if err != nil && err.Error() == "unauthorized" {
+		WriteError(w, 403, errors.New("not a valid owner"))
+		return
+	}
+	if err != nil {
+		log.Error("Error when resending campaign ", err)
+		WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
-	w.Write(SerializeData(expense))
+	w.Write(SerializeData(campaign))
 }
 
-func (c *EventServiceHandler) ListExpenses(w http.ResponseWriter, r *http.Request) {
-	eventId := r.URL.Query().Get("eventId")
-	if eventId == "" {
-		log.Warnf("Expected eventId got %s", eventId)
+// GetPublicEvent is public, it only returns the whitelisted fields of the event, see app.PublicEvent
+func (c *EventServiceHandler) GetPublicEvent(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	slug, ok := vars["slug"]
+	if !ok {
 		w.WriteHeader(http.StatusBadRequest)
-		w.Write(SerializeError(http.StatusBadRequest, "Expected eventId as query parameter"))
+		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
 		return
 	}
-	expenses, err := c.expenseService.List(eventId)
+	page, err := c.websiteService.GetPublic(slug)
 	if err != nil {
 		WriteError(w, http.StatusInternalServerError, err)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
-	w.Write(SerializeData(expenses))
-}
-
-func (c *EventServiceHandler) DeleteExpense(w http.ResponseWriter, r *http.Request) {
-	eventId := r.URL.Query().Get("eventId")
-	if eventId == "" {
-		log.Warn("Expected eventId")
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write(SerializeError(http.StatusBadRequest, "Expectde eventId as query parameter"))
+	if page == nil {
+		WriteError(w, http.StatusNotFound, nil)
 		return
 	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(page))
+}
 
+// RespondPublicEvent is public, the answers of every client are rate limited by its address
+func (c *EventServiceHandler) RespondPublicEvent(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	expenseId, ok := vars["expenseId"]
+	slug, ok := vars["slug"]
 	if !ok {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write(SerializeError(http.StatusBadRequest, "BadRequest"))
 		return
 	}
-	err := c.expenseService.Delete(eventId, expenseId)
+	var rsvp app.PublicRsvp
+	err := json.NewDecoder(r.Body).Decode(&rsvp)
+	if err == nil {
+		err = rsvp.Validate()
+	}
 	if err != nil {
+		log.Warn("Error when decoding Body", err)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(SerializeError(http.StatusBadRequest, "Invalid Body parameter"))
+		return
+	}
+	result, err := c.websiteService.Respond(slug, clientAddress(r), &rsvp)
+	switch {
+	case errors.Is(err, app.ErrRateLimited):
+		WriteError(w, http.StatusTooManyRequests, err)
+		return
+	case errors.Is(err, app.ErrGuestNotFound):
+		WriteError(w, http.StatusNotFound, err)
+		return
+	case errors.Is(err, app.ErrRsvpClosed):
+		WriteError(w, http.StatusConflict, err)
+		return
+	case errors.Is(err, app.ErrTooManySeats):
+		WriteError(w, http.StatusBadRequest, err)
+		return
+	case err != nil:
+		log.Error("Error when recording rsvp ", err)
 		WriteError(w, http.StatusInternalServerError, err)
 		return
+	case result == nil:
+		WriteError(w, http.StatusNotFound, nil)
+		return
 	}
 	w.WriteHeader(http.StatusOK)
+	w.Write(SerializeData(result))
+}
+
+// clientAddress returns the address of the client. X-Forwarded-For is set by the client and is not
+// trusted, in Lambda the source IP comes from the API Gateway request context the adapter always
+// overrides.
+func clientAddress(r *http.Request) string {
+	if !IsLocal() {
+		accessor := core.RequestAccessor{}
+		if context, err := accessor.GetAPIGatewayContext(r); err == nil && context.Identity.SourceIP != "" {
+			return context.Identity.SourceIP
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func calendarPath(token string) string {
+	return BASE_PATH + "/calendar/" + token + ".ics"
 }
 
 // IMPORTANT: Checks for syntactically valid JWT check,  is NOT doing any validation.
@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"errors"
+	"path"
 
 	cmdHttp "github.com/craguilar/event-management-service/cmd/http"
 	log "github.com/sirupsen/logrus"
@@ -42,9 +43,12 @@ func (h *LambaHandler) Handler(raw map[string]interface{}) (events.APIGatewayPro
 		return getErrorResponse(err)
 	}
 	http := events.APIGatewayProxyRequest{}
-	isScheduled := slices.Contains[string](cmdHttp.TASKS_PATH, http.Path)
 	// Try parsing into a APIGatewayProxyRequest
-	if err := json.Unmarshal(request, &http); err == nil && !isScheduled && http.HTTPMethod != "" {
+	if err := json.Unmarshal(request, &http); err == nil && http.HTTPMethod != "" {
+		// Scheduled tasks run for every event, they are only reachable through InterceptScheduled
+		if slices.Contains[string](cmdHttp.TASKS_PATH, path.Clean(http.Path)) {
+			return getForbiddenResponse(http.Path)
+		}
 		return h.HandleHttp(http)
 	}
 	// Filter only allowed tasks
@@ -56,13 +60,21 @@ func (h *LambaHandler) Handler(raw map[string]interface{}) (events.APIGatewayPro
 	return getErrorResponse(errors.New("type not enabled"))
 }
 
+// Path handling every scheduled type
+var scheduledPaths = map[string]string{
+	"PENDING_TASKS":     cmdHttp.TASK_NOTIFICATION,
+	"DUE_NOTIFICATIONS": cmdHttp.DUE_NOTIFICATION,
+	"DISPATCH_OUTBOX":   cmdHttp.OUTBOX_DISPATCH,
+}
+
 func (h *LambaHandler) InterceptScheduled(scheduled ScheduledRequest) (events.APIGatewayProxyResponse, error) {
-	if scheduled.Type != "PENDING_TASKS" {
+	path, ok := scheduledPaths[scheduled.Type]
+	if !ok {
 		return getErrorResponse(errors.New("invalid scheduled type"))
 	}
 	// TODO: Could we do this in a better way ?
 	request := events.APIGatewayProxyRequest{
-		Path:       cmdHttp.TASK_NOTIFICATION,
+		Path:       path,
 		HTTPMethod: "POST",
 		Headers:    map[string]string{"Authorization": "Bearer dummy"},
 	}
@@ -85,6 +97,18 @@ func (h *LambaHandler) HandleHttp(request events.APIGatewayProxyRequest) (events
 	return *response.Version1(), nil
 }
 
+// Refuse the HTTP requests to path with status 403
+func getForbiddenResponse(path string) (events.APIGatewayProxyResponse, error) {
+	log.Warnf("Refused HTTP request to scheduled path %s", path)
+	return events.APIGatewayProxyResponse{
+		StatusCode: 403,
+		Body:       string(cmdHttp.SerializeError(403, "Unauthorized")),
+		Headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+	}, nil
+}
+
 // Take and convert it into an APIGatewayProxyResponse with status 500
 func getErrorResponse(err error) (events.APIGatewayProxyResponse, error) {
 	log.Error(err)
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/craguilar/event-management-service/cmd/http"
 	appHttp "github.com/craguilar/event-management-service/cmd/http"
+	"github.com/craguilar/event-management-service/internal/app"
 	"github.com/craguilar/event-management-service/internal/app/dynamo"
 	"github.com/craguilar/event-management-service/internal/app/mock"
 )
@@ -16,9 +20,19 @@ func TestPathRouterForScheduledActions(t *testing.T) {
 	event := mock.NewEventService()
 	guest := mock.NewGuestService(event)
 	task := &mock.TaskService{}
-	expense := dynamo.NewExpenseService(db)
-	action := mock.NewEventActionsService(event, task)
-	handler := appHttp.NewServiceHandler(event, action, guest, task, expense)
+	expense := dynamo.NewExpenseService(db, nil, event, nil, nil, nil, nil)
+	preferences := mock.NewPreferencesService(nil)
+	action := mock.NewEventActionsService(event, task, preferences, app.NewMemoryNotifier())
+	comment := mock.NewCommentService()
+	audit := mock.NewAuditService()
+	vendor := mock.NewVendorService(expense, task)
+	attachment := mock.NewAttachmentService(nil, app.DefaultAttachmentLimits)
+	schedule := mock.NewScheduleService(guest)
+	calendar := mock.NewCalendarService(event, guest, task, schedule)
+	website := mock.NewWebsiteService(event, guest, schedule, mock.NewRateLimiter())
+	campaign := mock.NewCampaignService(guest)
+	outbox := mock.NewOutboxService(app.NewMemoryNotifier(), nil)
+	handler := appHttp.NewServiceHandler(event, action, guest, task, expense, comment, audit, vendor, attachment, schedule, calendar, website, campaign, outbox, preferences)
 	router := appHttp.NewRouter(handler)
 	lambdHandler := NewLambaHandler(router)
 	// Prepare
@@ -33,4 +47,157 @@ func TestPathRouterForScheduledActions(t *testing.T) {
 	if response.StatusCode != 200 {
 		t.Fail()
 	}
+	if !strings.Contains(response.Body, `"skipped"`) {
+		t.Errorf("Expected the run report got %s", response.Body)
+	}
+}
+
+func TestScheduledDueNotifications(t *testing.T) {
+	response, err := createMockHandler().InterceptScheduled(ScheduledRequest{Type: "DUE_NOTIFICATIONS"})
+	if err != nil || response.StatusCode != 200 {
+		t.Errorf("Expected the due notifications to run got %d %v", response.StatusCode, err)
+	}
+}
+
+// sourceRecorder records the source of the RSVPs
+type sourceRecorder struct {
+	app.WebsiteService
+	source string
+}
+
+func (s *sourceRecorder) Respond(slug, source string, rsvp *app.PublicRsvp) (*app.PublicRsvpResult, error) {
+	s.source = source
+	return nil, nil
+}
+
+// mockHandlerWith returns the lambda handler of the mock services with website and preferences
+func mockHandlerWith(website func(event app.EventService, guest app.GuestService, schedule app.ScheduleService) app.WebsiteService, preferences app.PreferencesService) *LambaHandler {
+	event := mock.NewEventService()
+	guest := mock.NewGuestService(event)
+	task := &mock.TaskService{}
+	expense := &mock.ExpenseService{}
+	schedule := mock.NewScheduleService(guest)
+	handler := appHttp.NewServiceHandler(event, mock.NewEventActionsService(event, task, preferences, app.NewMemoryNotifier()), guest, task, expense, mock.NewCommentService(), mock.NewAuditService(), mock.NewVendorService(expense, task), mock.NewAttachmentService(nil, app.DefaultAttachmentLimits), schedule, mock.NewCalendarService(event, guest, task, schedule), website(event, guest, schedule), mock.NewCampaignService(guest), mock.NewOutboxService(app.NewMemoryNotifier(), nil), preferences)
+	return NewLambaHandler(appHttp.NewRouter(handler))
+}
+
+func TestRsvpSourceIgnoresForwardedFor(t *testing.T) {
+	t.Setenv("LAMBDA_TASK_ROOT", "/var/task")
+	recorder := &sourceRecorder{}
+	handler := mockHandlerWith(func(event app.EventService, guest app.GuestService, schedule app.ScheduleService) app.WebsiteService {
+		recorder.WebsiteService = mock.NewWebsiteService(event, guest, schedule, mock.NewRateLimiter())
+		return recorder
+	}, mock.NewPreferencesService(nil))
+	request := events.APIGatewayProxyRequest{
+		Path:       http.BASE_PATH + "/public/events/wedding/rsvp",
+		HTTPMethod: "POST",
+		Headers:    map[string]string{"X-Forwarded-For": "1.1.1.1, 203.0.113.7"},
+		Body:       `{"email":"ana@example.com","isAttending":true,"seats":1}`,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			Identity: events.APIGatewayRequestIdentity{SourceIP: "203.0.113.7"},
+		},
+	}
+	if _, err := handler.HandleHttp(request); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if recorder.source != "203.0.113.7" {
+		t.Errorf("Expected the source IP of API Gateway got %q", recorder.source)
+	}
+}
+
+func TestUnsubscribeOnlyOnPost(t *testing.T) {
+	links, _ := app.NewUnsubscribeLinks("0123456789abcdef", "https://example.com"+http.UNSUBSCRIBE_PATH)
+	preferences := mock.NewPreferencesService(links)
+	handler := mockHandlerWith(func(event app.EventService, guest app.GuestService, schedule app.ScheduleService) app.WebsiteService {
+		return mock.NewWebsiteService(event, guest, schedule, mock.NewRateLimiter())
+	}, preferences)
+	token := links.Token("ana@example.com", "")
+	request := events.APIGatewayProxyRequest{
+		Path:                  http.UNSUBSCRIBE_PATH,
+		HTTPMethod:            "GET",
+		QueryStringParameters: map[string]string{"token": token},
+	}
+	response, err := handler.HandleHttp(request)
+	if err != nil || response.StatusCode != 200 || !strings.Contains(response.Body, `<form method="post"`) {
+		t.Fatalf("Expected the confirmation page got %d %v", response.StatusCode, err)
+	}
+	if value, _ := preferences.Get("ana@example.com"); value.Unsubscribed {
+		t.Error("Expected GET not to unsubscribe")
+	}
+	request.HTTPMethod = "POST"
+	request.Body = "List-Unsubscribe=One-Click"
+	if response, err = handler.HandleHttp(request); err != nil || response.StatusCode != 200 {
+		t.Fatalf("Expected the unsubscribe got %d %v", response.StatusCode, err)
+	}
+	if value, _ := preferences.Get("ana@example.com"); !value.Unsubscribed {
+		t.Error("Expected POST to unsubscribe")
+	}
+}
+
+// proxyEvent returns the raw event API Gateway sends for an HTTP request to path
+func proxyEvent(t *testing.T, path string) map[string]interface{} {
+	raw, err := json.Marshal(events.APIGatewayProxyRequest{
+		Path:       path,
+		HTTPMethod: "POST",
+		Headers:    map[string]string{"Authorization": "Bearer token"},
+	})
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	event := map[string]interface{}{}
+	if err = json.Unmarshal(raw, &event); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	return event
+}
+
+func TestOutboxDispatchOnlyScheduled(t *testing.T) {
+	handler := createMockHandler()
+	response, err := handler.Handler(proxyEvent(t, http.OUTBOX_DISPATCH))
+	if err != nil || response.StatusCode != 403 {
+		t.Errorf("Expected HTTP requests to dispatch the outbox refused got %d %v", response.StatusCode, err)
+	}
+	response, err = handler.Handler(map[string]interface{}{"type": "DISPATCH_OUTBOX"})
+	if err != nil || response.StatusCode != 200 {
+		t.Errorf("Expected the scheduled dispatch to run got %d %v", response.StatusCode, err)
+	}
+}
+
+func TestDueNotificationsOnlyScheduled(t *testing.T) {
+	handler := createMockHandler()
+	// Unclean paths reach the same route
+	for _, path := range []string{http.DUE_NOTIFICATION, http.BASE_PATH + "/events/actions/../actions/notifyDueEvents"} {
+		response, err := handler.Handler(proxyEvent(t, path))
+		if err != nil || response.StatusCode != 403 {
+			t.Errorf("Expected HTTP requests to %s refused got %d %v", path, response.StatusCode, err)
+		}
+	}
+}
+
+// unauthorizedGuests refuses every guest list
+type unauthorizedGuests struct {
+	app.GuestService
+}
+
+func (g *unauthorizedGuests) List(eventManager, eventId string) ([]*app.Guest, error) {
+	return nil, errors.New("unauthorized")
+}
+
+func TestGuestCostAnalyticsUnauthorized(t *testing.T) {
+	event := mock.NewEventService()
+	guest := &unauthorizedGuests{GuestService: mock.NewGuestService(event)}
+	task := &mock.TaskService{}
+	expense := &mock.ExpenseService{}
+	schedule := mock.NewScheduleService(guest)
+	preferences := mock.NewPreferencesService(nil)
+	handler := appHttp.NewServiceHandler(event, mock.NewEventActionsService(event, task, preferences, app.NewMemoryNotifier()), guest, task, expense, mock.NewCommentService(), mock.NewAuditService(), mock.NewVendorService(expense, task), mock.NewAttachmentService(nil, app.DefaultAttachmentLimits), schedule, mock.NewCalendarService(event, guest, task, schedule), mock.NewWebsiteService(event, guest, schedule, mock.NewRateLimiter()), mock.NewCampaignService(guest), mock.NewOutboxService(app.NewMemoryNotifier(), nil), preferences)
+	request := events.APIGatewayProxyRequest{
+		Path:       http.BASE_PATH + "/events/1/analytics/guests",
+		HTTPMethod: "GET",
+		Headers:    map[string]string{"Authorization": "Bearer token"},
+	}
+	response, err := NewLambaHandler(appHttp.NewRouter(handler)).HandleHttp(request)
+	if err != nil || response.StatusCode != 403 {
+		t.Errorf("Expected an unauthorized guest list refused got %d %v", response.StatusCode, err)
+	}
 }
@@ -3,19 +3,19 @@ package main
 import (
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/ses"
 	appHttp "github.com/craguilar/event-management-service/cmd/http"
 	"github.com/craguilar/event-management-service/internal/app"
 	"github.com/craguilar/event-management-service/internal/app/dynamo"
 )
 
 var db *dynamo.DBConfig
-var emailConfig *app.EmailConfig
 
 func init() {
 	log.Println("Initializing DynamoDB lambda")
@@ -31,34 +31,88 @@ func init() {
 		}
 		db = dynamo.InitDb(dynamodb.New(awsSession), "events")
 	}
-	if emailConfig == nil {
-		region := os.Getenv("AWS_REGION")
-		sesSession, err := session.NewSession(&aws.Config{
-			Region: aws.String(region),
-		})
-		if err != nil {
-			log.Fatalf("Error found %s", err)
-			return
-		}
-		emailConfig = &app.EmailConfig{
-			SesService: ses.New(sesSession),
-		}
-	}
 	log.Println("Initialized lambda ", db.DbService.Endpoint)
 }
 
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// Audit entries expire after AUDIT_RETENTION_DAYS, defaults to a year
+func auditRetention() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("AUDIT_RETENTION_DAYS"))
+	if err != nil || days <= 0 {
+		days = 365
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// Attachments are limited to ATTACHMENTS_MAX_SIZE_MB, defaults to app.DefaultAttachmentLimits
+func attachmentLimits() *app.AttachmentLimits {
+	size, err := strconv.Atoi(os.Getenv("ATTACHMENTS_MAX_SIZE_MB"))
+	if err != nil || size <= 0 {
+		return app.DefaultAttachmentLimits
+	}
+	return &app.AttachmentLimits{
+		MaxSize:      int64(size) * 1024 * 1024,
+		ContentTypes: app.DefaultAttachmentLimits.ContentTypes,
+	}
+}
+
 func main() {
 	log.Printf("Lambda started")
+	exchangeRates, err := app.NewExchangeRateProvider(getEnv("EXCHANGE_RATES_PROVIDER", "file"), getEnv("EXCHANGE_RATES_LOCATION", "scripts/exchange-rates.json"))
+	if err != nil {
+		log.Fatalf("Error found %s", err)
+	}
+	budgetThresholds, err := app.ParseBudgetThresholds(os.Getenv("BUDGET_ALERT_THRESHOLDS"))
+	if err != nil {
+		log.Fatalf("Error found %s", err)
+	}
+	storage, err := app.NewBlobStorage(getEnv("ATTACHMENTS_STORAGE", "s3"), os.Getenv("ATTACHMENTS_LOCATION"), os.Getenv("ATTACHMENTS_ENDPOINT"), os.Getenv("AWS_REGION"))
+	if err != nil {
+		log.Fatalf("Error found %s", err)
+	}
+	channel, err := app.NewNotifier(&app.NotifierConfig{
+		Kind:     getEnv("NOTIFIER", "ses"),
+		Source:   os.Getenv("NOTIFIER_SOURCE"),
+		Endpoint: os.Getenv("NOTIFIER_ENDPOINT"),
+		Username: os.Getenv("NOTIFIER_USERNAME"),
+		Password: os.Getenv("NOTIFIER_PASSWORD"),
+		Region:   os.Getenv("AWS_REGION"),
+	})
+	if err != nil {
+		log.Fatalf("Error found %s", err)
+	}
+	// Every notification goes through the outbox
+	outbox := dynamo.NewOutboxService(db, channel, app.ParseOutboxAdmins(os.Getenv("OUTBOX_ADMINS")))
+	links, err := app.NewUnsubscribeLinks(os.Getenv("UNSUBSCRIBE_SECRET"), os.Getenv("PUBLIC_URL")+appHttp.UNSUBSCRIBE_PATH)
+	if err != nil {
+		log.Fatalf("Error found %s", err)
+	}
+	preferences := dynamo.NewPreferencesService(db, links)
+	// and is dropped or held by the preferences of its recipient
+	notification := app.NewPreferencesNotifier(preferences, links, outbox)
 	// Authorization
 	authorize := dynamo.NewAuthorizationService(db)
+	audit := dynamo.NewAuditService(db, authorize, auditRetention())
 	// Create services and provide it to handler
-	event := dynamo.NewEventService(db, authorize)
-	guest := dynamo.NewGuestService(db, authorize)
-	task := dynamo.NewTaskService(db)
-	expense := dynamo.NewExpenseService(db)
-	notification := app.NewEmailNotificationService(emailConfig)
-	actions := dynamo.NewEventActionsService(db, event, task, notification)
-	handler := appHttp.NewServiceHandler(event, actions, guest, task, expense)
+	event := dynamo.NewEventService(db, authorize, audit, storage)
+	guest := dynamo.NewGuestService(db, authorize, audit)
+	task := dynamo.NewTaskService(db, audit)
+	expense := dynamo.NewExpenseService(db, audit, event, exchangeRates, notification, budgetThresholds, storage)
+	actions := dynamo.NewEventActionsService(db, event, task, expense, preferences, notification)
+	comment := dynamo.NewCommentService(db, authorize, event, notification)
+	vendor := dynamo.NewVendorService(db, authorize, audit, expense, task, storage)
+	attachment := dynamo.NewAttachmentService(db, authorize, storage, attachmentLimits(), expense, vendor)
+	schedule := dynamo.NewScheduleService(db, authorize, audit, guest)
+	calendar := dynamo.NewCalendarService(db, authorize, event, guest, task, schedule, notification)
+	website := dynamo.NewWebsiteService(db, authorize, event, guest, schedule, dynamo.NewRateLimiter(db))
+	campaign := dynamo.NewCampaignService(db, authorize, audit, event, guest, schedule, notification)
+	handler := appHttp.NewServiceHandler(event, actions, guest, task, expense, comment, audit, vendor, attachment, schedule, calendar, website, campaign, outbox, preferences)
 	// Router and Lambda Handler
 	router := appHttp.NewRouter(handler)
 	lambdHandler := NewLambaHandler(router)
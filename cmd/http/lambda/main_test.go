@@ -5,6 +5,7 @@ import (
 
 	"github.com/aws/aws-lambda-go/events"
 	appHttp "github.com/craguilar/event-management-service/cmd/http"
+	"github.com/craguilar/event-management-service/internal/app"
 	"github.com/craguilar/event-management-service/internal/app/mock"
 	"github.com/stretchr/testify/assert"
 )
@@ -77,8 +78,18 @@ func createMockHandler() *LambaHandler {
 	guest := mock.NewGuestService(event)
 	task := &mock.TaskService{}
 	expense := &mock.ExpenseService{}
-	action := mock.NewEventActionsService(event, task)
-	handler := appHttp.NewServiceHandler(event, action, guest, task, expense)
+	preferences := mock.NewPreferencesService(nil)
+	action := mock.NewEventActionsService(event, task, preferences, app.NewMemoryNotifier())
+	comment := mock.NewCommentService()
+	audit := mock.NewAuditService()
+	vendor := mock.NewVendorService(expense, task)
+	attachment := mock.NewAttachmentService(nil, app.DefaultAttachmentLimits)
+	schedule := mock.NewScheduleService(guest)
+	calendar := mock.NewCalendarService(event, guest, task, schedule)
+	website := mock.NewWebsiteService(event, guest, schedule, mock.NewRateLimiter())
+	campaign := mock.NewCampaignService(guest)
+	outbox := mock.NewOutboxService(app.NewMemoryNotifier(), nil)
+	handler := appHttp.NewServiceHandler(event, action, guest, task, expense, comment, audit, vendor, attachment, schedule, calendar, website, campaign, outbox, preferences)
 	router := appHttp.NewRouter(handler)
 	return NewLambaHandler(router)
 }
@@ -1,13 +1,20 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/craguilar/event-management-service/internal/app"
+
 	log "github.com/sirupsen/logrus"
 )
 
-// Write HTTP Error out to http.ResponseWriter
+// Write HTTP Error out to http.ResponseWriter, writes to archived events and invalid transitions are
+// always a 409
 func WriteError(w http.ResponseWriter, statusCode int, err error) {
+	if errors.Is(err, app.ErrEventReadOnly) || errors.Is(err, app.ErrInvalidTransition) || errors.Is(err, app.ErrCampaignSent) || errors.Is(err, app.ErrCampaignSending) || errors.Is(err, app.ErrOutboxSent) {
+		statusCode = http.StatusConflict
+	}
 	w.WriteHeader(statusCode)
 	var errorCode string
 	switch statusCode {
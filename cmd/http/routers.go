@@ -21,8 +21,13 @@ type Route struct {
 }
 
 const TASK_NOTIFICATION = BASE_PATH + "/events/actions/notifyPendingTasks"
+const DUE_NOTIFICATION = BASE_PATH + "/events/actions/notifyDueEvents"
+const OUTBOX_DISPATCH = BASE_PATH + "/outbox/actions/dispatch"
 
-var TASKS_PATH = []string{TASK_NOTIFICATION}
+var TASKS_PATH = []string{TASK_NOTIFICATION, DUE_NOTIFICATION, OUTBOX_DISPATCH}
+
+// Public route of the unsubscribe links in every email
+const UNSUBSCRIBE_PATH = BASE_PATH + "/public/unsubscribe"
 
 const BASE_PATH = "/20230125"
 
@@ -44,6 +49,11 @@ func NewRouter(handler *EventServiceHandler) *mux.Router {
 			strings.ToUpper("Put"),
 			BASE_PATH + "/events/actions/share",
 			handler.AddOwner,
+		}, {
+			"ActionCloneEvent",
+			strings.ToUpper("Post"),
+			BASE_PATH + "/events/actions/clone",
+			handler.CloneEvent,
 		}, {
 			"ListOWners",
 			strings.ToUpper("GET"),
@@ -64,11 +74,90 @@ func NewRouter(handler *EventServiceHandler) *mux.Router {
 			strings.ToUpper("Delete"),
 			BASE_PATH + "/events/{eventId}",
 			handler.DeleteEvent,
+		}, {
+			"TransitionEvent",
+			strings.ToUpper("Post"),
+			BASE_PATH + "/events/{eventId}/actions/transition",
+			handler.TransitionEvent,
+		}, {
+			"ListEventActivity",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/events/{eventId}/activity",
+			handler.ListActivity,
+		}, {
+			"GetEventSummary",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/events/{eventId}/summary",
+			handler.GetEventSummary,
+		}, {
+			"GetBudget",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/events/{eventId}/budget",
+			handler.GetBudget,
+		}, {
+			"GetGuestCostAnalytics",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/events/{eventId}/analytics/guests",
+			handler.GetGuestCostAnalytics,
+		}, {
+			"GetSettlement",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/events/{eventId}/settlement",
+			handler.GetSettlement,
+		}, {
+			"ListUpcomingPayments",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/events/{eventId}/payments",
+			handler.ListUpcomingPayments,
+		}, {
+			"ListVendorSummaries",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/events/{eventId}/vendors",
+			handler.ListVendorSummaries,
 		}, {
 			"SendPendingTasksNotifications",
 			strings.ToUpper("Post"),
 			TASK_NOTIFICATION,
 			handler.SendNotifications,
+		}, {
+			"SendDueNotifications",
+			strings.ToUpper("Post"),
+			DUE_NOTIFICATION,
+			handler.SendDueNotifications,
+		}, {
+			"DispatchOutbox",
+			strings.ToUpper("Post"),
+			OUTBOX_DISPATCH,
+			handler.DispatchOutbox,
+		},
+		// Outbox
+		{
+			"ListOutbox",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/outbox",
+			handler.ListOutbox,
+		}, {
+			"GetOutboxMessage",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/outbox/{messageId}",
+			handler.GetOutboxMessage,
+		}, {
+			"ReplayOutboxMessage",
+			strings.ToUpper("Post"),
+			BASE_PATH + "/outbox/{messageId}/actions/replay",
+			handler.ReplayOutboxMessage,
+		},
+		// Notification preferences
+		{
+			"GetPreferences",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/preferences",
+			handler.GetPreferences,
+		}, {
+			"UpdatePreferences",
+			strings.ToUpper("Put"),
+			BASE_PATH + "/preferences",
+			handler.UpdatePreferences,
 		},
 		// Guests
 		{
@@ -141,6 +230,214 @@ func NewRouter(handler *EventServiceHandler) *mux.Router {
 			strings.ToUpper("Delete"),
 			BASE_PATH + "/expenses/{expenseId}",
 			handler.DeleteExpense,
+		}, {
+			"AddOrUpdateExpenseItem",
+			strings.ToUpper("Post"),
+			BASE_PATH + "/expenses/{expenseId}/items",
+			handler.AddExpenseItem,
+		}, {
+			"GetExpenseItem",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/expenses/{expenseId}/items/{itemId}",
+			handler.GetExpenseItem,
+		}, {
+			"ListExpenseItems",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/expenses/{expenseId}/items",
+			handler.ListExpenseItems,
+		}, {
+			"DeleteExpenseItem",
+			strings.ToUpper("Delete"),
+			BASE_PATH + "/expenses/{expenseId}/items/{itemId}",
+			handler.DeleteExpenseItem,
+		},
+		// Vendors
+		{
+			"AddOrUpdateVendor",
+			strings.ToUpper("Post"),
+			BASE_PATH + "/vendors",
+			handler.AddVendor,
+		}, {
+			"GetVendor",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/vendors/{vendorId}",
+			handler.GetVendor,
+		}, {
+			"ListVendors",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/vendors",
+			handler.ListVendors,
+		}, {
+			"DeleteVendor",
+			strings.ToUpper("Delete"),
+			BASE_PATH + "/vendors/{vendorId}",
+			handler.DeleteVendor,
+		},
+		// Schedule
+		{
+			"AddOrUpdateScheduleItem",
+			strings.ToUpper("Post"),
+			BASE_PATH + "/schedule",
+			handler.AddScheduleItem,
+		}, {
+			"GetScheduleItem",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/schedule/{scheduleId}",
+			handler.GetScheduleItem,
+		}, {
+			"ListScheduleItems",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/schedule",
+			handler.ListScheduleItems,
+		}, {
+			"DeleteScheduleItem",
+			strings.ToUpper("Delete"),
+			BASE_PATH + "/schedule/{scheduleId}",
+			handler.DeleteScheduleItem,
+		}, {
+			"RespondScheduleItem",
+			strings.ToUpper("Post"),
+			BASE_PATH + "/schedule/{scheduleId}/rsvp",
+			handler.RespondScheduleItem,
+		},
+		// Website
+		{
+			"GetWebsite",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/events/{eventId}/website",
+			handler.GetWebsite,
+		}, {
+			"UpdateWebsite",
+			strings.ToUpper("Put"),
+			BASE_PATH + "/events/{eventId}/website",
+			handler.UpdateWebsite,
+		},
+		// Campaigns
+		{
+			"AddOrUpdateCampaign",
+			strings.ToUpper("Post"),
+			BASE_PATH + "/events/{eventId}/campaigns",
+			handler.AddCampaign,
+		}, {
+			"GetCampaign",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/events/{eventId}/campaigns/{campaignId}",
+			handler.GetCampaign,
+		}, {
+			"ListCampaigns",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/events/{eventId}/campaigns",
+			handler.ListCampaigns,
+		}, {
+			"DeleteCampaign",
+			strings.ToUpper("Delete"),
+			BASE_PATH + "/events/{eventId}/campaigns/{campaignId}",
+			handler.DeleteCampaign,
+		}, {
+			"SendCampaign",
+			strings.ToUpper("Post"),
+			BASE_PATH + "/events/{eventId}/campaigns/{campaignId}/actions/send",
+			handler.SendCampaign,
+		}, {
+			"ResendCampaign",
+			strings.ToUpper("Post"),
+			BASE_PATH + "/events/{eventId}/campaigns/{campaignId}/actions/resend",
+			handler.ResendCampaign,
+		},
+		// Calendar
+		{
+			"GetCalendarFeed",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/calendar/feed",
+			handler.GetCalendarFeed,
+		}, {
+			"RotateCalendarFeed",
+			strings.ToUpper("Post"),
+			BASE_PATH + "/calendar/feed/actions/rotate",
+			handler.RotateCalendarFeed,
+		}, {
+			"SendInvitations",
+			strings.ToUpper("Post"),
+			BASE_PATH + "/events/{eventId}/actions/sendInvitations",
+			handler.SendInvitations,
+		},
+		// Attachments
+		{
+			"AddAttachment",
+			strings.ToUpper("Post"),
+			BASE_PATH + "/attachments",
+			handler.AddAttachment,
+		}, {
+			"CompleteAttachment",
+			strings.ToUpper("Post"),
+			BASE_PATH + "/attachments/{attachmentId}/actions/complete",
+			handler.CompleteAttachment,
+		}, {
+			"GetAttachment",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/attachments/{attachmentId}",
+			handler.GetAttachment,
+		}, {
+			"ListAttachments",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/attachments",
+			handler.ListAttachments,
+		}, {
+			"DeleteAttachment",
+			strings.ToUpper("Delete"),
+			BASE_PATH + "/attachments/{attachmentId}",
+			handler.DeleteAttachment,
+		},
+		// Comments
+		{
+			"AddOrUpdateComment",
+			strings.ToUpper("Post"),
+			BASE_PATH + "/comments",
+			handler.AddComment,
+		}, {
+			"GetComment",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/comments/{commentId}",
+			handler.GetComment,
+		}, {
+			"ListComments",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/comments",
+			handler.ListComments,
+		}, {
+			"DeleteComment",
+			strings.ToUpper("Delete"),
+			BASE_PATH + "/comments/{commentId}",
+			handler.DeleteComment,
+		},
+	}
+	// Routes without Authorization, MUST also be excluded from the API Gateway authorizer
+	var publicRoutes = []Route{
+		{
+			"GetCalendar",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/calendar/{token}.ics",
+			handler.GetCalendar,
+		}, {
+			"GetPublicEvent",
+			strings.ToUpper("Get"),
+			BASE_PATH + "/public/events/{slug}",
+			handler.GetPublicEvent,
+		}, {
+			"RespondPublicEvent",
+			strings.ToUpper("Post"),
+			BASE_PATH + "/public/events/{slug}/rsvp",
+			handler.RespondPublicEvent,
+		}, {
+			"ConfirmUnsubscribe",
+			strings.ToUpper("Get"),
+			UNSUBSCRIBE_PATH,
+			handler.ConfirmUnsubscribe,
+		}, {
+			"Unsubscribe",
+			strings.ToUpper("Post"),
+			UNSUBSCRIBE_PATH,
+			handler.Unsubscribe,
 		},
 	}
 	//
@@ -156,6 +453,13 @@ func NewRouter(handler *EventServiceHandler) *mux.Router {
 			Name(route.Name).
 			Handler(handler)
 	}
+	for _, route := range publicRoutes {
+		router.
+			Methods(route.Method).
+			Path(route.Pattern).
+			Name(route.Name).
+			Handler(SetupPublicMiddleware(route.HandlerFunc, route.Name))
+	}
 	// OPTIONS Method no op handler
 
 	router.
@@ -179,6 +483,10 @@ func SetupGlobalMiddleware(handler http.Handler, name string) http.Handler {
 	return LoggerMiddleWare(JsonContentTypeMiddleWare(Authorization(Cors(handler))), name)
 }
 
+func SetupPublicMiddleware(handler http.Handler, name string) http.Handler {
+	return LoggerMiddleWare(JsonContentTypeMiddleWare(Cors(handler)), name)
+}
+
 func Cors(inner http.Handler) http.Handler {
 	// Where ORIGIN_ALLOWED is like `scheme://dns[:port]`, or `*` (insecure)
 	headersOk := handlers.AllowedHeaders([]string{"*"})
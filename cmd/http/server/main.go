@@ -7,6 +7,7 @@ import (
 
 	"github.com/craguilar/event-management-service/cmd"
 	appHttp "github.com/craguilar/event-management-service/cmd/http"
+	"github.com/craguilar/event-management-service/internal/app"
 	"github.com/craguilar/event-management-service/internal/app/dynamo"
 	"github.com/craguilar/event-management-service/internal/app/mock"
 )
@@ -26,12 +27,50 @@ func main() {
 	log.Printf("Server started on port %s", cmd.GetConfig("PORT"))
 
 	// Create services and provide it to handler
+	audit := mock.NewAuditService()
 	event := mock.NewEventService()
 	guest := mock.NewGuestService(event)
 	task := &mock.TaskService{}
-	expense := dynamo.NewExpenseService(db)
-	action := mock.NewEventActionsService(event, task)
-	handler := appHttp.NewServiceHandler(event, action, guest, task, expense)
+	rates, err := app.NewExchangeRateProvider(cmd.GetConfig("EXCHANGE_RATES_PROVIDER"), cmd.GetConfig("EXCHANGE_RATES_LOCATION"))
+	if err != nil {
+		log.Fatalf("Error found %s", err)
+	}
+	budgetThresholds, err := app.ParseBudgetThresholds(cmd.GetConfig("BUDGET_ALERT_THRESHOLDS"))
+	if err != nil {
+		log.Fatalf("Error found %s", err)
+	}
+	channel, err := app.NewNotifier(&app.NotifierConfig{
+		Kind:     cmd.GetConfig("NOTIFIER"),
+		Source:   cmd.GetConfig("NOTIFIER_SOURCE"),
+		Endpoint: cmd.GetConfig("NOTIFIER_ENDPOINT"),
+		Username: cmd.GetConfig("NOTIFIER_USERNAME"),
+		Password: cmd.GetConfig("NOTIFIER_PASSWORD"),
+		Region:   cmd.GetConfig("AWS_REGION"),
+	})
+	if err != nil {
+		log.Fatalf("Error found %s", err)
+	}
+	outbox := mock.NewOutboxService(channel, app.ParseOutboxAdmins(cmd.GetConfig("OUTBOX_ADMINS")))
+	links, err := app.NewUnsubscribeLinks(cmd.GetConfig("UNSUBSCRIBE_SECRET"), cmd.GetConfig("PUBLIC_URL")+appHttp.UNSUBSCRIBE_PATH)
+	if err != nil {
+		log.Fatalf("Error found %s", err)
+	}
+	preferences := mock.NewPreferencesService(links)
+	notification := app.NewPreferencesNotifier(preferences, links, outbox)
+	storage, err := app.NewBlobStorage(cmd.GetConfig("ATTACHMENTS_STORAGE"), cmd.GetConfig("ATTACHMENTS_LOCATION"), cmd.GetConfig("ATTACHMENTS_ENDPOINT"), cmd.GetConfig("AWS_REGION"))
+	if err != nil {
+		log.Fatalf("Error found %s", err)
+	}
+	expense := dynamo.NewExpenseService(db, audit, event, rates, notification, budgetThresholds, storage)
+	action := mock.NewEventActionsService(event, task, preferences, notification)
+	comment := mock.NewCommentService()
+	vendor := mock.NewVendorService(expense, task)
+	attachment := mock.NewAttachmentService(storage, app.DefaultAttachmentLimits)
+	schedule := mock.NewScheduleService(guest)
+	calendar := mock.NewCalendarService(event, guest, task, schedule)
+	website := mock.NewWebsiteService(event, guest, schedule, mock.NewRateLimiter())
+	campaign := mock.NewCampaignService(guest)
+	handler := appHttp.NewServiceHandler(event, action, guest, task, expense, comment, audit, vendor, attachment, schedule, calendar, website, campaign, outbox, preferences)
 	// Router config
 	router := appHttp.NewRouter(handler)
 
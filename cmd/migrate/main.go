@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/craguilar/event-management-service/internal/app/dynamo"
+)
+
+// One off data migrations, run it with the credentials of the target account:
+//
+//	go run cmd/migrate/main.go -migration expenses -table events
+//	go run cmd/migrate/main.go -migration expenses -endpoint http://localhost:8000
+func main() {
+	migration := flag.String("migration", "", "Migration to run: expenses, outbox")
+	table := flag.String("table", "events", "DynamoDB table name")
+	endpoint := flag.String("endpoint", "", "DynamoDB endpoint override, for local testing")
+	flag.Parse()
+
+	var db *dynamo.DBConfig
+	if *endpoint != "" {
+		db = dynamo.InitLocalDb(*endpoint, *table)
+	} else {
+		awsSession, err := session.NewSession(&aws.Config{
+			Region: aws.String(os.Getenv("AWS_REGION")),
+		})
+		if err != nil {
+			log.Fatalf("Error found %s", err)
+		}
+		db = dynamo.InitDb(dynamodb.New(awsSession), *table)
+	}
+
+	switch *migration {
+	case "expenses":
+		// Amounts to Money and embedded expenses to items, exchange rates are not captured again so no
+		// provider is required
+		migrated, err := dynamo.NewExpenseService(db, nil, nil, nil, nil, nil, nil).Migrate()
+		if err != nil {
+			log.Fatalf("Error found %s", err)
+		}
+		log.Printf("Migrated %d expense categories", migrated)
+	case "outbox":
+		// Pending messages to the pending index, nothing is sent
+		migrated, err := dynamo.NewOutboxService(db, nil, nil).Migrate()
+		if err != nil {
+			log.Fatalf("Error found %s", err)
+		}
+		log.Printf("Indexed %d pending outbox messages", migrated)
+	default:
+		flag.Usage()
+		os.Exit(1)
+	}
+}
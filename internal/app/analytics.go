@@ -0,0 +1,118 @@
+package app
+
+import (
+	"math/big"
+
+	"golang.org/x/exp/slices"
+)
+
+// GuestCostAnalytics answers what each extra guest costs, every amount is in Currency and based on the
+// AmountTotal of the categories. Categories marked PerGuest are the VariableCost that grows with the
+// attendance, the rest is FixedCost. Seats are confirmed when the guest is neither tentative nor not
+// attending.
+type GuestCostAnalytics struct {
+	EventId        string                `json:"eventId"`
+	Currency       string                `json:"currency"`
+	ConfirmedSeats int                   `json:"confirmedSeats"`
+	TentativeSeats int                   `json:"tentativeSeats"`
+	Total          Money                 `json:"total"`
+	FixedCost      Money                 `json:"fixedCost"`
+	VariableCost   Money                 `json:"variableCost"`
+	CostPerSeat    Money                 `json:"costPerSeat"`
+	CostPerExtra   Money                 `json:"costPerExtraSeat"`
+	BySide         []*SideCost           `json:"bySide"`
+	Scenarios      []*AttendanceScenario `json:"scenarios"`
+}
+
+// SideCost is the share of the Total of the confirmed seats of the guests of GuestOf.
+type SideCost struct {
+	GuestOf string `json:"guestOf"`
+	Seats   int    `json:"seats"`
+	Cost    Money  `json:"cost"`
+}
+
+// AttendanceScenario is the projected Total if Seats attend.
+type AttendanceScenario struct {
+	Seats       int   `json:"seats"`
+	Total       Money `json:"total"`
+	CostPerSeat Money `json:"costPerSeat"`
+}
+
+// NewGuestCostAnalytics computes the analytics and projects the given attendance scenarios, when none
+// is given the confirmed seats and the confirmed plus tentative seats are projected.
+func NewGuestCostAnalytics(event *Event, categories []*ExpenseCategory, guests []*Guest, scenarios []int) *GuestCostAnalytics {
+	analytics := &GuestCostAnalytics{
+		EventId:   event.Id,
+		Currency:  event.Currency(),
+		BySide:    []*SideCost{},
+		Scenarios: []*AttendanceScenario{},
+	}
+	for _, category := range categories {
+		category.ComputeTotals()
+		if category.PerGuest {
+			analytics.VariableCost += category.AmountTotal
+		} else {
+			analytics.FixedCost += category.AmountTotal
+		}
+	}
+	analytics.Total = analytics.FixedCost + analytics.VariableCost
+
+	sides := map[string]*SideCost{}
+	for _, guest := range guests {
+		if guest.NotAttending {
+			continue
+		}
+		if guest.Tentative {
+			analytics.TentativeSeats += guest.NumberOfSeats
+			continue
+		}
+		analytics.ConfirmedSeats += guest.AttendingSeats()
+		if _, ok := sides[guest.GuestOf]; !ok {
+			sides[guest.GuestOf] = &SideCost{GuestOf: guest.GuestOf}
+			analytics.BySide = append(analytics.BySide, sides[guest.GuestOf])
+		}
+		sides[guest.GuestOf].Seats += guest.AttendingSeats()
+	}
+	slices.SortStableFunc(analytics.BySide, func(a, b *SideCost) bool {
+		return a.Seats > b.Seats
+	})
+	if analytics.ConfirmedSeats == 0 {
+		return analytics
+	}
+
+	seats := int64(analytics.ConfirmedSeats)
+	analytics.CostPerSeat = scaleMoney(analytics.Total, 1, seats)
+	analytics.CostPerExtra = scaleMoney(analytics.VariableCost, 1, seats)
+	weights := []*CostShare{}
+	for _, side := range analytics.BySide {
+		weights = append(weights, &CostShare{Weight: side.Seats})
+	}
+	for i, cost := range SplitMoney(analytics.Total, weights) {
+		analytics.BySide[i].Cost = cost
+	}
+
+	if len(scenarios) == 0 {
+		scenarios = []int{analytics.ConfirmedSeats}
+		if analytics.TentativeSeats > 0 {
+			scenarios = append(scenarios, analytics.ConfirmedSeats+analytics.TentativeSeats)
+		}
+	}
+	for _, attendance := range scenarios {
+		scenario := &AttendanceScenario{
+			Seats: attendance,
+			Total: analytics.FixedCost + scaleMoney(analytics.VariableCost, int64(attendance), seats),
+		}
+		if attendance > 0 {
+			scenario.CostPerSeat = scaleMoney(scenario.Total, 1, int64(attendance))
+		}
+		analytics.Scenarios = append(analytics.Scenarios, scenario)
+	}
+	return analytics
+}
+
+// scaleMoney returns m * numerator / denominator rounded to the closest minor unit.
+func scaleMoney(m Money, numerator, denominator int64) Money {
+	product := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(numerator))
+	value, _ := roundRat(new(big.Rat).SetFrac(product, big.NewInt(denominator)))
+	return value
+}
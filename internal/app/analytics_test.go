@@ -0,0 +1,49 @@
+package app
+
+import "testing"
+
+func TestNewGuestCostAnalytics(t *testing.T) {
+	categories := []*ExpenseCategory{
+		{Id: "EXPENSE_CATEGORY-VENUE", AmountProjected: 100000},
+		{Id: "EXPENSE_CATEGORY-FOOD", AmountProjected: 50000, PerGuest: true},
+	}
+	guests := []*Guest{
+		{GuestOf: "Bride", NumberOfSeats: 6},
+		{GuestOf: "Groom", NumberOfSeats: 4},
+		{GuestOf: "Groom", NumberOfSeats: 5, Tentative: true},
+		{GuestOf: "Bride", NumberOfSeats: 3, NotAttending: true},
+	}
+	analytics := NewGuestCostAnalytics(&Event{Id: "event"}, categories, guests, nil)
+	if analytics.ConfirmedSeats != 10 || analytics.TentativeSeats != 5 {
+		t.Errorf("Expected 10 confirmed and 5 tentative seats got %d and %d", analytics.ConfirmedSeats, analytics.TentativeSeats)
+	}
+	if analytics.Total != 150000 || analytics.FixedCost != 100000 || analytics.VariableCost != 50000 {
+		t.Errorf("Unexpected costs %+v", analytics)
+	}
+	if analytics.CostPerSeat != 15000 || analytics.CostPerExtra != 5000 {
+		t.Errorf("Expected 150.00 per seat and 50.00 per extra seat got %s and %s", analytics.CostPerSeat, analytics.CostPerExtra)
+	}
+	if len(analytics.BySide) != 2 {
+		t.Fatalf("Expected 2 sides got %d", len(analytics.BySide))
+	}
+	if side := analytics.BySide[0]; side.GuestOf != "Bride" || side.Seats != 6 || side.Cost != 90000 {
+		t.Errorf("Unexpected side %+v", side)
+	}
+	if side := analytics.BySide[1]; side.GuestOf != "Groom" || side.Seats != 4 || side.Cost != 60000 {
+		t.Errorf("Unexpected side %+v", side)
+	}
+	if len(analytics.Scenarios) != 2 {
+		t.Fatalf("Expected 2 scenarios got %d", len(analytics.Scenarios))
+	}
+	if scenario := analytics.Scenarios[1]; scenario.Seats != 15 || scenario.Total != 175000 || scenario.CostPerSeat != 11667 {
+		t.Errorf("Unexpected scenario %+v", scenario)
+	}
+}
+
+func TestNewGuestCostAnalyticsWithoutGuests(t *testing.T) {
+	categories := []*ExpenseCategory{{AmountProjected: 100000, PerGuest: true}}
+	analytics := NewGuestCostAnalytics(&Event{Id: "event"}, categories, []*Guest{}, []int{100})
+	if analytics.Total != 100000 || analytics.CostPerSeat != 0 || len(analytics.Scenarios) != 0 {
+		t.Errorf("Expected only the total without confirmed seats got %+v", analytics)
+	}
+}
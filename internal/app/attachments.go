@@ -0,0 +1,86 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/exp/slices"
+)
+
+// Entities an attachment can be attached to
+const (
+	AttachOnExpense = "EXPENSE"
+	AttachOnVendor  = "VENDOR"
+)
+
+// Attachment statuses, an attachment is PENDING until its upload is completed
+const (
+	AttachmentPending  = "PENDING"
+	AttachmentUploaded = "UPLOADED"
+)
+
+type AttachmentService interface {
+	Get(eventManager, eventId, id string) (*Attachment, error)
+	List(eventManager, eventId, entityType, entityId string) ([]*Attachment, error)
+	// Create stores the metadata and returns the attachment with a presigned UploadUrl
+	Create(eventManager, eventId string, u *Attachment) (*Attachment, error)
+	// Complete checks the uploaded content against the limits and marks the attachment UPLOADED
+	Complete(eventManager, eventId, id string) (*Attachment, error)
+	Delete(eventManager, eventId, id string) error
+}
+
+// Attachment : Required EntityType, EntityId, FileName, ContentType, Size. Receipts are attached to
+// expenses, which also require the CategoryId of the expense, and contracts to vendors. The content
+// is stored in BlobStorage under Key, UploadUrl and DownloadUrl are presigned and never stored.
+// EntityType is stored with a different name as entityType is the table sort key.
+type Attachment struct {
+	Id            string `json:"id"`
+	EntityType    string `json:"entityType" dynamodbav:"attachedEntityType" validate:"required,oneof=EXPENSE VENDOR"`
+	EntityId      string `json:"entityId" validate:"required"`
+	CategoryId    string `json:"categoryId" validate:"required_if=EntityType EXPENSE"`
+	FileName      string `json:"fileName" validate:"required,max=255"`
+	ContentType   string `json:"contentType" validate:"required"`
+	Size          int64  `json:"size" validate:"required,gt=0"`
+	Key           string `json:"key"`
+	Status        string `json:"status"`
+	UploadedBy    string `json:"uploadedBy"`
+	UploadUrl     string `json:"uploadUrl,omitempty" dynamodbav:"-"`
+	DownloadUrl   string `json:"downloadUrl,omitempty" dynamodbav:"-"`
+	v             *validator.Validate
+	TimeCreatedOn time.Time `json:"timeCreatedOn"`
+	TimeUpdatedOn time.Time `json:"timeUpdatedOn"`
+}
+
+// AttachmentLimits : MaxSize in bytes and the accepted ContentTypes
+type AttachmentLimits struct {
+	MaxSize      int64
+	ContentTypes []string
+}
+
+// Default limits, receipts and contracts are usually PDFs or pictures
+var DefaultAttachmentLimits = &AttachmentLimits{
+	MaxSize:      10 * 1024 * 1024,
+	ContentTypes: []string{"application/pdf", "image/jpeg", "image/png", "image/heic", "image/webp"},
+}
+
+func (a *Attachment) Validate() error {
+	if a.v == nil {
+		a.v = validator.New()
+	}
+	return a.v.Struct(a)
+}
+
+// Check returns an error if size or contentType are not within the limits.
+func (l *AttachmentLimits) Check(contentType string, size int64) error {
+	if size > l.MaxSize {
+		return fmt.Errorf("attachment of %d bytes exceeds the limit of %d bytes", size, l.MaxSize)
+	}
+	// Parameters like charset are ignored
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if !slices.Contains(l.ContentTypes, mediaType) {
+		return fmt.Errorf("content type %s not allowed", contentType)
+	}
+	return nil
+}
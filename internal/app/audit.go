@@ -0,0 +1,146 @@
+package app
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// Audit actions
+const (
+	AuditCreate = "CREATE"
+	AuditUpdate = "UPDATE"
+	AuditDelete = "DELETE"
+)
+
+// Audited entities
+const (
+	AuditEvent           = "EVENT"
+	AuditOwner           = "OWNER"
+	AuditGuest           = "GUEST"
+	AuditTask            = "TASK"
+	AuditExpenseCategory = "EXPENSE_CATEGORY"
+	AuditExpense         = "EXPENSE"
+	AuditVendor          = "VENDOR"
+	AuditSchedule        = "SCHEDULE"
+	AuditCampaign        = "CAMPAIGN"
+)
+
+// Actor used for mutations not triggered by a user, like the scheduled runs
+const SystemActor = "SYSTEM"
+
+// Fields not considered part of the diff as they change on every mutation
+var auditIgnoredFields = map[string]bool{
+	"timeCreatedOn": true,
+	"timeUpdatedOn": true,
+}
+
+type AuditService interface {
+	Record(entry *AuditEntry) error
+	List(eventManager, eventId string, page *PageRequest) (*AuditPage, error)
+}
+
+// AuditEntry records a single mutation on an entity of an event, Changes holds the before and after
+// value of every modified field.
+type AuditEntry struct {
+	Id            string                  `json:"id"`
+	EventId       string                  `json:"eventId"`
+	Actor         string                  `json:"actor"`
+	Action        string                  `json:"action"`
+	Entity        string                  `json:"entity"`
+	EntityId      string                  `json:"entityId"`
+	Changes       map[string]*FieldChange `json:"changes"`
+	TimeCreatedOn time.Time               `json:"timeCreatedOn"`
+}
+
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// PageRequest : Limit is the max number of items returned, NextToken is an opaque token returned
+// by a previous page.
+type PageRequest struct {
+	Limit     int
+	NextToken string
+}
+
+type AuditPage struct {
+	Items     []*AuditEntry `json:"items"`
+	NextToken string        `json:"nextToken,omitempty"`
+}
+
+// NewAuditEntry builds the entry for a mutation of entity from before to after, a nil before means
+// the entity was created and a nil after means it was deleted.
+func NewAuditEntry(actor, eventId, entity, entityId string, before, after interface{}) (*AuditEntry, error) {
+	changes, err := Diff(before, after)
+	if err != nil {
+		return nil, err
+	}
+	action := AuditUpdate
+	if isNil(before) {
+		action = AuditCreate
+	} else if isNil(after) {
+		action = AuditDelete
+	}
+	return &AuditEntry{
+		EventId:       eventId,
+		Actor:         actor,
+		Action:        action,
+		Entity:        entity,
+		EntityId:      entityId,
+		Changes:       changes,
+		TimeCreatedOn: time.Now(),
+	}, nil
+}
+
+// Diff compares the JSON representation of before and after and returns the modified fields.
+func Diff(before, after interface{}) (map[string]*FieldChange, error) {
+	beforeFields, err := toFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := toFields(after)
+	if err != nil {
+		return nil, err
+	}
+	changes := map[string]*FieldChange{}
+	for key, value := range beforeFields {
+		if auditIgnoredFields[key] {
+			continue
+		}
+		if !reflect.DeepEqual(value, afterFields[key]) {
+			changes[key] = &FieldChange{Before: value, After: afterFields[key]}
+		}
+	}
+	for key, value := range afterFields {
+		if _, exists := beforeFields[key]; exists || auditIgnoredFields[key] || value == nil {
+			continue
+		}
+		changes[key] = &FieldChange{After: value}
+	}
+	return changes, nil
+}
+
+func toFields(value interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if isNil(value) {
+		return fields, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
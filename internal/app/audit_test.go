@@ -0,0 +1,45 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuditEntryDiff(t *testing.T) {
+	before := &Task{Id: "TASK-1", Name: "Book the DJ", Status: "PENDING", TimeUpdatedOn: time.Now()}
+	after := &Task{Id: "TASK-1", Name: "Book the DJ", Status: "DONE", TimeUpdatedOn: time.Now().Add(time.Hour)}
+
+	entry, err := NewAuditEntry("JANE@EXAMPLE.COM", "event", AuditTask, after.Id, before, after)
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if entry.Action != AuditUpdate {
+		t.Errorf("Expected UPDATE got %s", entry.Action)
+	}
+	if len(entry.Changes) != 1 || entry.Changes["status"] == nil {
+		t.Fatalf("Expected only status to change got %v", entry.Changes)
+	}
+	if entry.Changes["status"].Before != "PENDING" || entry.Changes["status"].After != "DONE" {
+		t.Errorf("Unexpected change %v", entry.Changes["status"])
+	}
+}
+
+func TestAuditEntryCreateAndDelete(t *testing.T) {
+	var none *Task
+	task := &Task{Id: "TASK-1", Name: "Book the DJ", Status: "PENDING"}
+
+	created, err := NewAuditEntry("JANE@EXAMPLE.COM", "event", AuditTask, task.Id, none, task)
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if created.Action != AuditCreate || created.Changes["name"].After != "Book the DJ" {
+		t.Errorf("Unexpected create entry %v", created)
+	}
+	deleted, err := NewAuditEntry("JANE@EXAMPLE.COM", "event", AuditTask, task.Id, task, nil)
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if deleted.Action != AuditDelete || deleted.Changes["name"].Before != "Book the DJ" {
+		t.Errorf("Unexpected delete entry %v", deleted)
+	}
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Budget alert scopes and metrics
+const (
+	BudgetScopeEvent    = "EVENT"
+	BudgetScopeCategory = "CATEGORY"
+	BudgetPaid          = "PAID"
+	BudgetProjected     = "PROJECTED"
+)
+
+// Percentages of the budget alerted by default, see ParseBudgetThresholds
+var DefaultBudgetThresholds = []int{80, 100}
+
+// BudgetSummary compares the event Budget against the projected and paid amounts of its expense
+// categories, every amount is in Currency. Variance is what is left of the budget once the larger of
+// projected and paid is spent, a negative variance is an overspend.
+type BudgetSummary struct {
+	EventId    string            `json:"eventId"`
+	Currency   string            `json:"currency"`
+	Budget     Money             `json:"budget"`
+	Projected  Money             `json:"projected"`
+	Paid       Money             `json:"paid"`
+	Variance   Money             `json:"variance"`
+	Categories []*CategoryBudget `json:"categories"`
+}
+
+// CategoryBudget : Variance is computed against the category Limit, only when it has one.
+type CategoryBudget struct {
+	CategoryId string `json:"categoryId"`
+	Category   string `json:"category"`
+	Limit      Money  `json:"limit"`
+	Projected  Money  `json:"projected"`
+	Paid       Money  `json:"paid"`
+	Variance   Money  `json:"variance"`
+}
+
+// BudgetAlert is raised when Metric of the event (or of a category) reaches Threshold percent of
+// Limit.
+type BudgetAlert struct {
+	Scope      string `json:"scope"`
+	CategoryId string `json:"categoryId,omitempty"`
+	Category   string `json:"category,omitempty"`
+	Metric     string `json:"metric"`
+	Threshold  int    `json:"threshold"`
+	Amount     Money  `json:"amount"`
+	Limit      Money  `json:"limit"`
+}
+
+func NewBudgetSummary(event *Event, categories []*ExpenseCategory) *BudgetSummary {
+	summary := &BudgetSummary{
+		EventId:    event.Id,
+		Currency:   event.Currency(),
+		Budget:     event.Budget,
+		Categories: []*CategoryBudget{},
+	}
+	for _, category := range categories {
+		summary.Projected += category.AmountProjected
+		summary.Paid += category.AmountPaid
+		categoryBudget := &CategoryBudget{
+			CategoryId: category.Id,
+			Category:   category.Category,
+			Limit:      category.Limit,
+			Projected:  category.AmountProjected,
+			Paid:       category.AmountPaid,
+		}
+		if category.Limit > 0 {
+			categoryBudget.Variance = category.Limit - maxMoney(category.AmountProjected, category.AmountPaid)
+		}
+		summary.Categories = append(summary.Categories, categoryBudget)
+	}
+	summary.Variance = summary.Budget - maxMoney(summary.Projected, summary.Paid)
+	return summary
+}
+
+// BudgetAlerts returns the thresholds crossed upwards going from before to after, an amount going
+// down never raises an alert so alerts are only sent once per crossing. Events and categories without
+// a budget or limit are not alerted.
+func BudgetAlerts(before, after *BudgetSummary, thresholds []int) []*BudgetAlert {
+	alerts := []*BudgetAlert{}
+	if after.Budget > 0 {
+		for _, threshold := range thresholds {
+			if crossed(before.Budget, before.Paid, after.Budget, after.Paid, threshold) {
+				alerts = append(alerts, &BudgetAlert{Scope: BudgetScopeEvent, Metric: BudgetPaid, Threshold: threshold, Amount: after.Paid, Limit: after.Budget})
+			}
+			if crossed(before.Budget, before.Projected, after.Budget, after.Projected, threshold) {
+				alerts = append(alerts, &BudgetAlert{Scope: BudgetScopeEvent, Metric: BudgetProjected, Threshold: threshold, Amount: after.Projected, Limit: after.Budget})
+			}
+		}
+	}
+	previous := map[string]*CategoryBudget{}
+	for _, category := range before.Categories {
+		previous[category.CategoryId] = category
+	}
+	for _, category := range after.Categories {
+		if category.Limit <= 0 {
+			continue
+		}
+		old, ok := previous[category.CategoryId]
+		if !ok {
+			old = &CategoryBudget{Limit: category.Limit}
+		}
+		for _, threshold := range thresholds {
+			if crossed(old.Limit, old.Paid, category.Limit, category.Paid, threshold) {
+				alerts = append(alerts, &BudgetAlert{Scope: BudgetScopeCategory, CategoryId: category.CategoryId, Category: category.Category, Metric: BudgetPaid, Threshold: threshold, Amount: category.Paid, Limit: category.Limit})
+			}
+			if crossed(old.Limit, old.Projected, category.Limit, category.Projected, threshold) {
+				alerts = append(alerts, &BudgetAlert{Scope: BudgetScopeCategory, CategoryId: category.CategoryId, Category: category.Category, Metric: BudgetProjected, Threshold: threshold, Amount: category.Projected, Limit: category.Limit})
+			}
+		}
+	}
+	return alerts
+}
+
+// ParseBudgetThresholds parses a comma separated list of percentages, like 80,100.
+func ParseBudgetThresholds(value string) ([]int, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultBudgetThresholds, nil
+	}
+	thresholds := []int{}
+	for _, part := range strings.Split(value, ",") {
+		threshold, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || threshold <= 0 {
+			return nil, fmt.Errorf("invalid budget threshold %s", part)
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	return thresholds, nil
+}
+
+// crossed is true when amount reaches threshold percent of limit after but not before, comparisons
+// are done in cents multiplied by 100 so they are exact.
+func crossed(beforeLimit, beforeAmount, afterLimit, afterAmount Money, threshold int) bool {
+	reached := func(limit, amount Money) bool {
+		return limit > 0 && int64(amount)*100 >= int64(limit)*int64(threshold)
+	}
+	return reached(afterLimit, afterAmount) && !reached(beforeLimit, beforeAmount)
+}
+
+func maxMoney(a, b Money) Money {
+	if a > b {
+		return a
+	}
+	return b
+}
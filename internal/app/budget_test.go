@@ -0,0 +1,81 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewBudgetSummary(t *testing.T) {
+	event := &Event{Id: "event", Budget: 100000}
+	categories := []*ExpenseCategory{
+		{Id: "venue", Category: "Venue", AmountProjected: 60000, AmountPaid: 70000, Limit: 65000},
+		{Id: "food", Category: "Food", AmountProjected: 30000, AmountPaid: 10000},
+	}
+	summary := NewBudgetSummary(event, categories)
+	if summary.Currency != DefaultCurrency || summary.Projected != 90000 || summary.Paid != 80000 {
+		t.Errorf("Unexpected totals %+v", summary)
+	}
+	if summary.Variance != 10000 {
+		t.Errorf("Expected variance 100.00 got %s", summary.Variance)
+	}
+	if summary.Categories[0].Variance != -5000 || summary.Categories[1].Variance != 0 {
+		t.Errorf("Unexpected category variances %s %s", summary.Categories[0].Variance, summary.Categories[1].Variance)
+	}
+}
+
+func TestBudgetAlerts(t *testing.T) {
+	event := &Event{Id: "event", Budget: 100000}
+	before := NewBudgetSummary(event, []*ExpenseCategory{
+		{Id: "venue", Category: "Venue", AmountProjected: 70000, AmountPaid: 40000, Limit: 60000},
+	})
+	after := NewBudgetSummary(event, []*ExpenseCategory{
+		{Id: "venue", Category: "Venue", AmountProjected: 70000, AmountPaid: 80000, Limit: 60000},
+	})
+	alerts := BudgetAlerts(before, after, DefaultBudgetThresholds)
+	// Event paid reaches 80%, category paid reaches 80% and 100%. Projected was already over
+	if len(alerts) != 3 {
+		t.Fatalf("Expected 3 alerts got %d", len(alerts))
+	}
+	if alerts[0].Scope != BudgetScopeEvent || alerts[0].Metric != BudgetPaid || alerts[0].Threshold != 80 {
+		t.Errorf("Unexpected event alert %+v", alerts[0])
+	}
+	for _, alert := range alerts[1:] {
+		if alert.Scope != BudgetScopeCategory || alert.CategoryId != "venue" || alert.Metric != BudgetPaid {
+			t.Errorf("Unexpected category alert %+v", alert)
+		}
+	}
+	// Going down never alerts
+	if alerts := BudgetAlerts(after, before, DefaultBudgetThresholds); len(alerts) != 0 {
+		t.Errorf("Expected no alerts got %d", len(alerts))
+	}
+	// No budget no alerts
+	unbounded := NewBudgetSummary(&Event{}, nil)
+	if alerts := BudgetAlerts(unbounded, unbounded, DefaultBudgetThresholds); len(alerts) != 0 {
+		t.Errorf("Expected no alerts got %d", len(alerts))
+	}
+}
+
+func TestParseBudgetThresholds(t *testing.T) {
+	thresholds, err := ParseBudgetThresholds(" 50, 90 ,100")
+	if err != nil || len(thresholds) != 3 || thresholds[1] != 90 {
+		t.Errorf("Unexpected thresholds %v %s", thresholds, err)
+	}
+	if thresholds, _ := ParseBudgetThresholds(""); len(thresholds) != len(DefaultBudgetThresholds) {
+		t.Errorf("Expected default thresholds got %v", thresholds)
+	}
+	if _, err := ParseBudgetThresholds("80,abc"); err == nil {
+		t.Error("Expected error for an invalid threshold")
+	}
+}
+
+func TestTemplateBudgetAlertNotification(t *testing.T) {
+	alerts := []*BudgetAlert{{Scope: BudgetScopeCategory, Category: "<b>Venue</b>", Metric: BudgetPaid, Threshold: 100, Amount: 80000, Limit: 60000}}
+	buf, err := TemplateBudgetAlertNotification("Wedding", "USD", alerts)
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	body := buf.String()
+	if !strings.Contains(body, "800.00 USD") || strings.Contains(body, "<b>Venue</b>") {
+		t.Errorf("Unexpected template %s", body)
+	}
+}
@@ -0,0 +1,249 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// CalendarService renders the events of an owner as iCalendar (RFC 5545) feeds, calendar clients
+// subscribe to the feed URL so every feed is identified by a secret token instead of the JWT.
+type CalendarService interface {
+	// GetFeed returns the feed of eventId, or of all the events of eventManager when eventId is empty,
+	// creating its token the first time
+	GetFeed(eventManager, eventId string) (*CalendarFeed, error)
+	// RotateFeed replaces the token of the feed, the previous URL stops working
+	RotateFeed(eventManager, eventId string) (*CalendarFeed, error)
+	// Render returns the calendar of the feed identified by token, nil if there is none
+	Render(token string) (*Calendar, error)
+	// SendInvitations emails an invite to the guests with an email, all of them not attending when
+	// guestIds is empty, and returns how many were sent
+	SendInvitations(eventManager, eventId string, guestIds []string) (int, error)
+}
+
+// CalendarFeed : the feed of a single event when EventId is set, of every event of Owner otherwise.
+// Path is where calendar clients subscribe to.
+type CalendarFeed struct {
+	Token         string    `json:"token"`
+	Owner         string    `json:"owner"`
+	EventId       string    `json:"eventId"`
+	Path          string    `json:"path" dynamodbav:"-"`
+	TimeCreatedOn time.Time `json:"timeCreatedOn"`
+}
+
+// iTIP methods, feeds are published and invitations requested
+const (
+	CalendarPublish = "PUBLISH"
+	CalendarRequest = "REQUEST"
+)
+
+// Calendar holds the components of an iCalendar object, see String. Organizer and Attendee are the
+// emails set on the events of invitations.
+type Calendar struct {
+	Name       string
+	Method     string
+	Organizer  string
+	Attendee   string
+	components []string
+	now        time.Time
+}
+
+func NewCalendar(name, method string, now time.Time) *Calendar {
+	return &Calendar{Name: name, Method: method, now: now}
+}
+
+// AddEvent adds the event as a VEVENT, all-day events as a calendar date. Timed events last until the
+// end of the day as they have no duration.
+func (c *Calendar) AddEvent(event *Event) {
+	summary := event.ToSummary()
+	lines := []string{
+		"BEGIN:VEVENT",
+		"UID:" + event.Id + "@events",
+		"DTSTAMP:" + c.stamp(),
+		"SUMMARY:" + escapeText(event.Name),
+	}
+	if event.MainLocation != "" {
+		lines = append(lines, "LOCATION:"+escapeText(event.MainLocation))
+	}
+	if event.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+escapeText(event.Description))
+	}
+	lines = append(lines, c.participants()...)
+	if event.AllDay {
+		lines = append(lines,
+			"DTSTART;VALUE=DATE:"+formatCalendarDate(summary.Date()),
+			"DTEND;VALUE=DATE:"+formatCalendarDate(summary.Date().AddDate(0, 0, 1)))
+	} else {
+		end := summary.Date().AddDate(0, 0, 1)
+		lines = append(lines,
+			"DTSTART:"+formatCalendarTime(event.EventDay),
+			"DTEND:"+formatCalendarTime(time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, summary.Location())))
+	}
+	c.components = append(c.components, strings.Join(append(lines, "END:VEVENT"), "\r\n"))
+}
+
+// AddScheduleItem adds a sub-event of event as a VEVENT.
+func (c *Calendar) AddScheduleItem(event *Event, item *ScheduleItem) {
+	location := item.Venue
+	if item.Address != "" {
+		location = strings.TrimPrefix(location+", "+item.Address, ", ")
+	}
+	lines := []string{
+		"BEGIN:VEVENT",
+		"UID:" + item.Id + "." + event.Id + "@events",
+		"DTSTAMP:" + c.stamp(),
+		"SUMMARY:" + escapeText(item.Name+" - "+event.Name),
+		"DTSTART:" + formatCalendarTime(item.StartTime),
+	}
+	if !item.EndTime.IsZero() {
+		lines = append(lines, "DTEND:"+formatCalendarTime(item.EndTime))
+	}
+	if location != "" {
+		lines = append(lines, "LOCATION:"+escapeText(location))
+	}
+	if item.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+escapeText(item.Description))
+	}
+	lines = append(lines, c.participants()...)
+	c.components = append(c.components, strings.Join(append(lines, "END:VEVENT"), "\r\n"))
+}
+
+// AddTask adds a task of event with a due date as a VTODO, tasks without one are skipped.
+func (c *Calendar) AddTask(event *Event, task *Task) {
+	due, ok := task.ResolveDueDate(event.EventDay)
+	if !ok {
+		return
+	}
+	status := "NEEDS-ACTION"
+	if task.Status == "DONE" {
+		status = "COMPLETED"
+	}
+	lines := []string{
+		"BEGIN:VTODO",
+		"UID:" + task.Id + "." + event.Id + "@events",
+		"DTSTAMP:" + c.stamp(),
+		"SUMMARY:" + escapeText(task.Name+" - "+event.Name),
+		"STATUS:" + status,
+	}
+	if IsCalendarDate(due) {
+		lines = append(lines, "DUE;VALUE=DATE:"+formatCalendarDate(due))
+	} else {
+		lines = append(lines, "DUE:"+formatCalendarTime(due))
+	}
+	c.components = append(c.components, strings.Join(append(lines, "END:VTODO"), "\r\n"))
+}
+
+// AddEventWithSchedule adds the event, its schedule items and its tasks.
+func (c *Calendar) AddEventWithSchedule(event *Event, schedule []*ScheduleItem, tasks []*Task) {
+	c.AddEvent(event)
+	for _, item := range schedule {
+		c.AddScheduleItem(event, item)
+	}
+	for _, task := range tasks {
+		c.AddTask(event, task)
+	}
+}
+
+// String renders the calendar, lines end with CRLF and are folded at 75 octets as required.
+func (c *Calendar) String() string {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//event-management-service//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:" + c.Method,
+	}
+	if c.Name != "" {
+		lines = append(lines, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+	lines = append(lines, c.components...)
+	lines = append(lines, "END:VCALENDAR")
+	var b strings.Builder
+	for _, line := range strings.Split(strings.Join(lines, "\r\n"), "\r\n") {
+		b.WriteString(foldLine(line))
+		b.WriteString("\r\n")
+	}
+	return b.String()
+}
+
+func (c *Calendar) participants() []string {
+	lines := []string{}
+	if c.Organizer != "" {
+		lines = append(lines, "ORGANIZER:mailto:"+c.Organizer)
+	}
+	if c.Attendee != "" {
+		lines = append(lines, "ATTENDEE;ROLE=REQ-PARTICIPANT;PARTSTAT=NEEDS-ACTION;RSVP=FALSE:mailto:"+c.Attendee)
+	}
+	return lines
+}
+
+func (c *Calendar) stamp() string {
+	return formatCalendarTime(c.now)
+}
+
+func formatCalendarDate(t time.Time) string {
+	return t.Format("20060102")
+}
+
+// formatCalendarTime uses the UTC form so no VTIMEZONE definition is needed.
+func formatCalendarTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeText(value string) string {
+	return textEscaper.Replace(value)
+}
+
+// foldLine splits lines longer than 75 octets without breaking UTF-8 sequences, continuation lines
+// start with a space.
+func foldLine(line string) string {
+	if len(line) <= 75 {
+		return line
+	}
+	var b strings.Builder
+	size := 0
+	for _, r := range line {
+		n := len(string(r))
+		if size+n > 75 {
+			b.WriteString("\r\n ")
+			size = 1
+		}
+		b.WriteRune(r)
+		size += n
+	}
+	return b.String()
+}
+
+// NewInvitation returns the invite of guest to event with the schedule items the guest is invited to,
+// which are also returned.
+func NewInvitation(organizer string, event *Event, guest *Guest, schedule []*ScheduleItem, now time.Time) (*Calendar, []*ScheduleItem) {
+	invitation := NewCalendar(event.Name, CalendarRequest, now)
+	invitation.Organizer = strings.ToLower(organizer)
+	invitation.Attendee = guest.Email
+	invited := []*ScheduleItem{}
+	for _, item := range schedule {
+		if item.Invites(guest) {
+			invited = append(invited, item)
+		}
+	}
+	invitation.AddEventWithSchedule(event, invited, nil)
+	return invitation, invited
+}
+
+// InvitedGuests returns the guests with an email in guestIds, or every guest with an email attending
+// the event when empty.
+func InvitedGuests(guests []*Guest, guestIds []string) []*Guest {
+	invited := []*Guest{}
+	for _, guest := range guests {
+		if guest.Email == "" {
+			continue
+		}
+		if (len(guestIds) == 0 && !guest.NotAttending) || slices.Contains(guestIds, guest.Id) {
+			invited = append(invited, guest)
+		}
+	}
+	return invited
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendarAllDayEvent(t *testing.T) {
+	now := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	calendar := NewCalendar("Events", CalendarPublish, now)
+	calendar.AddEvent(&Event{
+		Id:           "1",
+		Name:         "Wedding; Ana, Luis",
+		MainLocation: "Puebla",
+		EventDay:     time.Date(2023, 6, 10, 0, 0, 0, 0, time.UTC),
+		AllDay:       true,
+	})
+	rendered := calendar.String()
+	for _, expected := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"METHOD:PUBLISH\r\n",
+		"DTSTAMP:20230501T100000Z\r\n",
+		"SUMMARY:Wedding\\; Ana\\, Luis\r\n",
+		"DTSTART;VALUE=DATE:20230610\r\n",
+		"DTEND;VALUE=DATE:20230611\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(rendered, expected) {
+			t.Errorf("Expected %q in %q", expected, rendered)
+		}
+	}
+}
+
+func TestCalendarTimedEventEndsWithItsDay(t *testing.T) {
+	calendar := NewCalendar("Events", CalendarPublish, time.Now())
+	calendar.AddEvent(&Event{
+		Id:       "1",
+		Name:     "Party",
+		EventDay: time.Date(2023, 6, 11, 1, 0, 0, 0, time.UTC),
+		TimeZone: "America/Mexico_City",
+	})
+	rendered := calendar.String()
+	// 7 pm of the 10th in Mexico City, the day ends at 6 am UTC of the 11th
+	if !strings.Contains(rendered, "DTSTART:20230611T010000Z\r\n") || !strings.Contains(rendered, "DTEND:20230611T060000Z\r\n") {
+		t.Errorf("Unexpected start or end in %q", rendered)
+	}
+}
+
+func TestCalendarTasksAndSchedule(t *testing.T) {
+	event := &Event{Id: "1", Name: "Wedding", EventDay: time.Date(2023, 6, 10, 0, 0, 0, 0, time.UTC), AllDay: true}
+	schedule := []*ScheduleItem{{
+		Id:        "SCHEDULE-1",
+		Name:      "Ceremony",
+		Venue:     "Church",
+		StartTime: time.Date(2023, 6, 10, 18, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2023, 6, 10, 19, 0, 0, 0, time.UTC),
+	}}
+	tasks := []*Task{
+		{Id: "TASK-1", Name: "Book DJ", Status: "DONE", DueDate: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Id: "TASK-2", Name: "Without due date", Status: "PENDING"},
+	}
+	calendar := NewCalendar("Wedding", CalendarPublish, time.Now())
+	calendar.AddEventWithSchedule(event, schedule, tasks)
+	rendered := calendar.String()
+	for _, expected := range []string{
+		"SUMMARY:Ceremony - Wedding\r\n",
+		"LOCATION:Church\r\n",
+		"DTEND:20230610T190000Z\r\n",
+		"BEGIN:VTODO\r\n",
+		"STATUS:COMPLETED\r\n",
+		"DUE;VALUE=DATE:20230501\r\n",
+	} {
+		if !strings.Contains(rendered, expected) {
+			t.Errorf("Expected %q in %q", expected, rendered)
+		}
+	}
+	if strings.Count(rendered, "BEGIN:VTODO") != 1 {
+		t.Errorf("Expected tasks without due date to be skipped")
+	}
+}
+
+func TestFoldLine(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("ñ", 80)
+	folded := foldLine(line)
+	for _, part := range strings.Split(folded, "\r\n") {
+		if len(part) > 75 {
+			t.Errorf("Expected at most 75 octets got %d", len(part))
+		}
+	}
+	if strings.ReplaceAll(folded, "\r\n ", "") != line {
+		t.Errorf("Expected unfolding to return the line")
+	}
+	if foldLine("SUMMARY:Short") != "SUMMARY:Short" {
+		t.Errorf("Expected short lines to be unchanged")
+	}
+}
+
+func TestInvitedGuests(t *testing.T) {
+	guests := []*Guest{
+		{Id: "GUEST-1", Email: "ana@example.com"},
+		{Id: "GUEST-2", Email: "luis@example.com", NotAttending: true},
+		{Id: "GUEST-3"},
+	}
+	if invited := InvitedGuests(guests, nil); len(invited) != 1 || invited[0].Id != "GUEST-1" {
+		t.Errorf("Expected only the attending guests with email got %v", invited)
+	}
+	if invited := InvitedGuests(guests, []string{"GUEST-2", "GUEST-3"}); len(invited) != 1 || invited[0].Id != "GUEST-2" {
+		t.Errorf("Expected only the selected guests with email got %v", invited)
+	}
+}
+
+func TestNewInvitation(t *testing.T) {
+	event := &Event{Id: "1", Name: "Wedding", EventDay: time.Date(2023, 6, 10, 0, 0, 0, 0, time.UTC), AllDay: true}
+	guest := &Guest{Id: "GUEST-1", Email: "ana@example.com"}
+	schedule := []*ScheduleItem{
+		{Id: "SCHEDULE-1", Name: "Ceremony", AllGuests: true, StartTime: event.EventDay},
+		{Id: "SCHEDULE-2", Name: "Rehearsal", GuestIds: []string{"GUEST-2"}, StartTime: event.EventDay},
+	}
+	invitation, invited := NewInvitation("OWNER@EXAMPLE.COM", event, guest, schedule, time.Now())
+	if len(invited) != 1 || invited[0].Id != "SCHEDULE-1" {
+		t.Errorf("Expected only the schedule items the guest is invited to got %v", invited)
+	}
+	rendered := invitation.String()
+	if !strings.Contains(rendered, "METHOD:REQUEST\r\n") || !strings.Contains(rendered, "ORGANIZER:mailto:owner@example.com\r\n") ||
+		!strings.Contains(strings.ReplaceAll(rendered, "\r\n ", ""), "mailto:ana@example.com\r\n") {
+		t.Errorf("Unexpected invitation %q", rendered)
+	}
+}
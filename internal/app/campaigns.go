@@ -0,0 +1,214 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/exp/slices"
+)
+
+// CampaignService sends emails to the guests of an event, the recipients are resolved out of the
+// Audience when the campaign is sent and the result of every recipient is kept in the campaign.
+type CampaignService interface {
+	Get(eventManager, eventId, id string) (*Campaign, error)
+	List(eventManager, eventId string) ([]*Campaign, error)
+	// CreateOrUpdate only updates campaigns not sent yet
+	CreateOrUpdate(eventManager, eventId string, u *Campaign) (*Campaign, error)
+	Delete(eventManager, eventId, id string) error
+	// Send emails every guest in the audience
+	Send(eventManager, eventId, id string) (*Campaign, error)
+	// Resend emails again the recipients the campaign failed to
+	Resend(eventManager, eventId, id string) (*Campaign, error)
+}
+
+// Campaign templates
+const (
+	CampaignInvitation  = "INVITATION"
+	CampaignSaveTheDate = "SAVE_THE_DATE"
+	CampaignReminder    = "REMINDER"
+)
+
+// Campaign and recipient status
+const (
+	CampaignDraft   = "DRAFT"
+	CampaignSending = "SENDING"
+	CampaignSent    = "SENT"
+	CampaignPending = "PENDING"
+	CampaignFailed  = "FAILED"
+	// The recipient disabled the campaigns, never emailed again
+	CampaignSuppressed = "SUPPRESSED"
+)
+
+// A campaign SENDING for longer than CampaignClaimTimeout was interrupted, e.g. by a timeout, and is
+// sent again by Resend.
+const CampaignClaimTimeout = 15 * time.Minute
+
+var (
+	ErrCampaignSent    = errors.New("campaign already sent")
+	ErrCampaignSending = errors.New("campaign is being sent")
+)
+
+// Campaign : Required Name and Template. Subject defaults to the one of the template and Message is
+// added to the email as plain text. Invitations include the .ics invite of the guest.
+type Campaign struct {
+	Id            string               `json:"id"`
+	Name          string               `json:"name" validate:"required"`
+	Template      string               `json:"template" validate:"required,oneof=INVITATION SAVE_THE_DATE REMINDER"`
+	Subject       string               `json:"subject" validate:"max=200"`
+	Message       string               `json:"message" validate:"max=5000"`
+	Audience      *CampaignAudience    `json:"audience"`
+	Status        string               `json:"status"`
+	Recipients    []*CampaignRecipient `json:"recipients"`
+	Sent          int                  `json:"sent" dynamodbav:"-"`
+	Failed        int                  `json:"failed" dynamodbav:"-"`
+	Suppressed    int                  `json:"suppressed" dynamodbav:"-"`
+	v             *validator.Validate
+	TimeSentOn    time.Time `json:"timeSentOn"`
+	TimeCreatedOn time.Time `json:"timeCreatedOn"`
+	TimeUpdatedOn time.Time `json:"timeUpdatedOn"`
+}
+
+// CampaignAudience : every guest with an Email attending the event by default, each filter set
+// narrows it down.
+type CampaignAudience struct {
+	GuestIds            []string `json:"guestIds"`
+	GuestOf             string   `json:"guestOf"`
+	RequiresInvite      bool     `json:"requiresInvite"`
+	NotResponded        bool     `json:"isNotResponded"`
+	IncludeNotAttending bool     `json:"includeNotAttending"`
+}
+
+type CampaignRecipient struct {
+	GuestId    string    `json:"guestId"`
+	Email      string    `json:"email"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error"`
+	Attempts   int       `json:"attempts"`
+	TimeSentOn time.Time `json:"timeSentOn"`
+}
+
+func (c *Campaign) Validate() error {
+	if c.v == nil {
+		c.v = validator.New()
+	}
+	return c.v.Struct(c)
+}
+
+// Matches returns true if guest is part of the audience.
+func (a *CampaignAudience) Matches(guest *Guest) bool {
+	if guest.Email == "" {
+		return false
+	}
+	if a == nil {
+		return !guest.NotAttending
+	}
+	if guest.NotAttending && !a.IncludeNotAttending {
+		return false
+	}
+	if len(a.GuestIds) > 0 && !slices.Contains(a.GuestIds, guest.Id) {
+		return false
+	}
+	if a.GuestOf != "" && !strings.EqualFold(a.GuestOf, guest.GuestOf) {
+		return false
+	}
+	if a.RequiresInvite && !guest.RequiresInvite {
+		return false
+	}
+	if a.NotResponded && !guest.TimeRespondedOn.IsZero() {
+		return false
+	}
+	return true
+}
+
+// Resolve sets a PENDING recipient for every guest in the audience.
+func (c *Campaign) Resolve(guests []*Guest) {
+	c.Recipients = []*CampaignRecipient{}
+	for _, guest := range guests {
+		if c.Audience.Matches(guest) {
+			c.Recipients = append(c.Recipients, &CampaignRecipient{GuestId: guest.Id, Email: guest.Email, Status: CampaignPending})
+		}
+	}
+}
+
+// Resendable returns nil if Resend can claim the campaign at now, sent campaigns or the ones whose
+// send was interrupted.
+func (c *Campaign) Resendable(now time.Time) error {
+	switch {
+	case c.Status == CampaignSent:
+		return nil
+	case c.Status == CampaignSending && now.Sub(c.TimeUpdatedOn) > CampaignClaimTimeout:
+		return nil
+	case c.Status == CampaignSending:
+		return ErrCampaignSending
+	}
+	return errors.New("campaign not sent yet")
+}
+
+// Claim marks the campaign SENDING, stored before any email is sent so a single Send or Resend
+// delivers it at a time.
+func (c *Campaign) Claim(now time.Time) {
+	c.Status = CampaignSending
+	c.TimeUpdatedOn = now
+}
+
+// Deliver calls send for every recipient not sent nor suppressed yet and records the result, the
+// campaign is SENT even if some recipients failed, see Resend. Recipients send returns
+// ErrNotificationSuppressed for are SUPPRESSED and not FAILED, they disabled the campaigns.
+func (c *Campaign) Deliver(send func(recipient *CampaignRecipient) error) {
+	for _, recipient := range c.Recipients {
+		if recipient.Status == CampaignSent || recipient.Status == CampaignSuppressed {
+			continue
+		}
+		recipient.Attempts++
+		err := send(recipient)
+		if errors.Is(err, ErrNotificationSuppressed) {
+			recipient.Status = CampaignSuppressed
+			recipient.Error = ""
+			continue
+		}
+		if err != nil {
+			recipient.Status = CampaignFailed
+			recipient.Error = err.Error()
+			continue
+		}
+		recipient.Status = CampaignSent
+		recipient.Error = ""
+		recipient.TimeSentOn = time.Now()
+	}
+	c.Status = CampaignSent
+	if c.TimeSentOn.IsZero() {
+		c.TimeSentOn = time.Now()
+	}
+	c.ComputeTotals()
+}
+
+// ComputeTotals counts the recipients sent, failed and suppressed.
+func (c *Campaign) ComputeTotals() {
+	c.Sent, c.Failed, c.Suppressed = 0, 0, 0
+	for _, recipient := range c.Recipients {
+		switch recipient.Status {
+		case CampaignSent:
+			c.Sent++
+		case CampaignFailed:
+			c.Failed++
+		case CampaignSuppressed:
+			c.Suppressed++
+		}
+	}
+}
+
+// DefaultSubject returns the Subject or the default one of the template.
+func (c *Campaign) DefaultSubject(eventName string) string {
+	if c.Subject != "" {
+		return c.Subject
+	}
+	switch c.Template {
+	case CampaignSaveTheDate:
+		return "Save the date: " + eventName
+	case CampaignReminder:
+		return "Reminder: " + eventName
+	}
+	return "Invitation to " + eventName
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCampaignAudienceMatches(t *testing.T) {
+	responded := &Guest{Id: "GUEST-1", Email: "ana@example.com", GuestOf: "Bride", RequiresInvite: true, TimeRespondedOn: time.Now()}
+	pending := &Guest{Id: "GUEST-2", Email: "luis@example.com", GuestOf: "Groom"}
+	declined := &Guest{Id: "GUEST-3", Email: "eva@example.com", NotAttending: true}
+	withoutEmail := &Guest{Id: "GUEST-4"}
+
+	var everyone *CampaignAudience
+	if !everyone.Matches(responded) || !everyone.Matches(pending) || everyone.Matches(declined) || everyone.Matches(withoutEmail) {
+		t.Errorf("Expected the attending guests with email by default")
+	}
+	if audience := (&CampaignAudience{RequiresInvite: true}); !audience.Matches(responded) || audience.Matches(pending) {
+		t.Errorf("Expected only the guests requiring invite")
+	}
+	if audience := (&CampaignAudience{NotResponded: true}); audience.Matches(responded) || !audience.Matches(pending) {
+		t.Errorf("Expected only the guests not responded yet")
+	}
+	if audience := (&CampaignAudience{GuestOf: "groom"}); audience.Matches(responded) || !audience.Matches(pending) {
+		t.Errorf("Expected only the guests of the groom")
+	}
+	if audience := (&CampaignAudience{IncludeNotAttending: true, GuestIds: []string{"GUEST-3"}}); !audience.Matches(declined) || audience.Matches(pending) {
+		t.Errorf("Expected only the selected guests")
+	}
+}
+
+func TestCampaignDeliverAndResend(t *testing.T) {
+	campaign := &Campaign{Name: "Invitations", Template: CampaignInvitation, Status: CampaignDraft}
+	campaign.Resolve([]*Guest{
+		{Id: "GUEST-1", Email: "ana@example.com"},
+		{Id: "GUEST-2", Email: "luis@example.com"},
+		{Id: "GUEST-3"},
+	})
+	if len(campaign.Recipients) != 2 {
+		t.Fatalf("Expected 2 recipients got %d", len(campaign.Recipients))
+	}
+	campaign.Deliver(func(recipient *CampaignRecipient) error {
+		if recipient.GuestId == "GUEST-2" {
+			return errors.New("mailbox unavailable")
+		}
+		return nil
+	})
+	if campaign.Status != CampaignSent || campaign.Sent != 1 || campaign.Failed != 1 || campaign.TimeSentOn.IsZero() {
+		t.Errorf("Expected a sent campaign with 1 failure got %s %d %d", campaign.Status, campaign.Sent, campaign.Failed)
+	}
+	if campaign.Recipients[1].Error != "mailbox unavailable" {
+		t.Errorf("Expected the error of the recipient got %s", campaign.Recipients[1].Error)
+	}
+	resent := []string{}
+	campaign.Deliver(func(recipient *CampaignRecipient) error {
+		resent = append(resent, recipient.GuestId)
+		return nil
+	})
+	if len(resent) != 1 || resent[0] != "GUEST-2" {
+		t.Errorf("Expected only the failed recipients to be resent got %v", resent)
+	}
+	if campaign.Sent != 2 || campaign.Failed != 0 || campaign.Recipients[1].Attempts != 2 || campaign.Recipients[1].Error != "" {
+		t.Errorf("Expected every recipient sent got %d %d", campaign.Sent, campaign.Failed)
+	}
+}
+
+func TestCampaignDefaultSubject(t *testing.T) {
+	campaign := &Campaign{Template: CampaignSaveTheDate}
+	if subject := campaign.DefaultSubject("Wedding"); subject != "Save the date: Wedding" {
+		t.Errorf("Unexpected subject %s", subject)
+	}
+	campaign.Subject = "Our wedding"
+	if subject := campaign.DefaultSubject("Wedding"); subject != "Our wedding" {
+		t.Errorf("Unexpected subject %s", subject)
+	}
+}
+
+func TestCampaignResendable(t *testing.T) {
+	now := time.Now()
+	campaign := &Campaign{Status: CampaignDraft}
+	if campaign.Resendable(now) == nil {
+		t.Error("Expected drafts not to be resent")
+	}
+	campaign.Claim(now)
+	if !errors.Is(campaign.Resendable(now.Add(time.Minute)), ErrCampaignSending) {
+		t.Error("Expected campaigns being sent not to be resent")
+	}
+	if campaign.Resendable(now.Add(CampaignClaimTimeout+time.Minute)) != nil {
+		t.Error("Expected interrupted sends to be resent")
+	}
+}
+
+func TestCampaignDeliverSuppressed(t *testing.T) {
+	campaign := &Campaign{Name: "Invitations", Template: CampaignInvitation, Status: CampaignDraft}
+	campaign.Resolve([]*Guest{{Id: "GUEST-1", Email: "ana@example.com"}})
+	campaign.Deliver(func(recipient *CampaignRecipient) error { return ErrNotificationSuppressed })
+	if campaign.Recipients[0].Status != CampaignSuppressed || campaign.Suppressed != 1 || campaign.Failed != 0 {
+		t.Errorf("Expected a suppressed recipient got %s %d %d", campaign.Recipients[0].Status, campaign.Suppressed, campaign.Failed)
+	}
+	campaign.Deliver(func(recipient *CampaignRecipient) error {
+		t.Errorf("Expected suppressed recipients not to be resent")
+		return nil
+	})
+}
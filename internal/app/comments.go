@@ -0,0 +1,62 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Entities a comment can be attached to
+const (
+	CommentOnTask            = "TASK"
+	CommentOnGuest           = "GUEST"
+	CommentOnExpenseCategory = "EXPENSE_CATEGORY"
+)
+
+var mentionRegex = regexp.MustCompile(`@([A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+
+type CommentService interface {
+	Get(eventId, id string) (*Comment, error)
+	List(eventId, entityId string) ([]*Comment, error)
+	CreateOrUpdate(author, eventId string, u *Comment) (*Comment, error)
+	Delete(author, eventId, id string) error
+}
+
+// Comment : Required EntityType, EntityId, Body. Author is always taken from the caller identity and
+// only the Author can edit or delete the comment. Mentions are the shared emails mentioned in Body as
+// @email. EntityType is stored with a different name as entityType is the table sort key.
+type Comment struct {
+	Id            string   `json:"id"`
+	EntityType    string   `json:"entityType" dynamodbav:"commentedEntityType" validate:"required,oneof=TASK GUEST EXPENSE_CATEGORY"`
+	EntityId      string   `json:"entityId" validate:"required"`
+	Author        string   `json:"author"`
+	Body          string   `json:"body" validate:"required,max=4000"`
+	Mentions      []string `json:"mentions"`
+	v             *validator.Validate
+	TimeCreatedOn time.Time `json:"timeCreatedOn"`
+	TimeUpdatedOn time.Time `json:"timeUpdatedOn"`
+}
+
+func (c *Comment) Validate() error {
+	if c.v == nil {
+		c.v = validator.New()
+	}
+	return c.v.Struct(c)
+}
+
+// ParseMentions returns the unique upper cased emails mentioned as @email in body.
+func ParseMentions(body string) []string {
+	mentions := []string{}
+	seen := map[string]bool{}
+	for _, match := range mentionRegex.FindAllStringSubmatch(body, -1) {
+		email := strings.ToUpper(strings.TrimRight(match[1], "."))
+		if seen[email] {
+			continue
+		}
+		seen[email] = true
+		mentions = append(mentions, email)
+	}
+	return mentions
+}
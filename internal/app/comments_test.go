@@ -0,0 +1,29 @@
+package app
+
+import (
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	mentions := ParseMentions("Hey @jane.doe@example.com can you call the DJ? cc @Bob@Example.com, @jane.doe@example.com.")
+	if len(mentions) != 2 {
+		t.Fatalf("Expected 2 mentions got %v", mentions)
+	}
+	if mentions[0] != "JANE.DOE@EXAMPLE.COM" || mentions[1] != "BOB@EXAMPLE.COM" {
+		t.Errorf("Unexpected mentions %v", mentions)
+	}
+	if len(ParseMentions("no mentions @here")) != 0 {
+		t.Errorf("No mentions expected")
+	}
+}
+
+func TestCommentValidation(t *testing.T) {
+	c := &Comment{EntityType: "EVENT", EntityId: "TASK-1", Body: "Hello"}
+	if err := c.Validate(); err == nil {
+		t.Error("Only TASK, GUEST and EXPENSE_CATEGORY comments are expected to be valid")
+	}
+	c.EntityType = CommentOnTask
+	if err := c.Validate(); err != nil {
+		t.Errorf("Test failed with error %s", err)
+	}
+}
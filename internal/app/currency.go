@@ -0,0 +1,156 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Currency used when an Event does not define its BaseCurrency
+const DefaultCurrency = "USD"
+
+// Currencies of ISO 4217 whose minor unit is not a hundredth, their amounts can not be represented as
+// Money so they are rejected.
+var unsupportedCurrencies = map[string]bool{
+	// No decimals
+	"BIF": true, "CLP": true, "DJF": true, "GNF": true, "ISK": true, "JPY": true, "KMF": true, "KRW": true,
+	"PYG": true, "RWF": true, "UGX": true, "UYI": true, "VND": true, "VUV": true, "XAF": true, "XOF": true,
+	"XPF": true,
+	// 3 decimals
+	"BHD": true, "IQD": true, "JOD": true, "KWD": true, "LYD": true, "OMR": true, "TND": true,
+	// 4 decimals
+	"CLF": true, "UYW": true,
+	// No minor unit, like precious metals and testing codes
+	"XAG": true, "XAU": true, "XBA": true, "XBB": true, "XBC": true, "XBD": true, "XDR": true, "XPD": true,
+	"XPT": true, "XSU": true, "XTS": true, "XUA": true, "XXX": true,
+}
+
+// checkCurrency returns an error when the amounts of currency do not have 2 decimals like Money.
+func checkCurrency(currency string) error {
+	if unsupportedCurrencies[strings.ToUpper(currency)] {
+		return fmt.Errorf("currency %s is not supported, amounts must have 2 decimals", currency)
+	}
+	return nil
+}
+
+// ExchangeRateProvider returns how many units of to are bought with one unit of from on a given date.
+type ExchangeRateProvider interface {
+	Rate(from, to string, on time.Time) (float64, error)
+}
+
+// ExchangeRates : Rates are the units of each currency bought with one unit of Base, this is the
+// format of both the file and HTTP providers.
+type ExchangeRates struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// Rate converts through the Base currency so any pair of known currencies can be converted.
+func (r *ExchangeRates) Rate(from, to string) (float64, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return 1, nil
+	}
+	fromRate, err := r.unitsPerBase(from)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := r.unitsPerBase(to)
+	if err != nil {
+		return 0, err
+	}
+	return toRate / fromRate, nil
+}
+
+func (r *ExchangeRates) unitsPerBase(currency string) (float64, error) {
+	if currency == strings.ToUpper(r.Base) {
+		return 1, nil
+	}
+	rate, ok := r.Rates[currency]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("no exchange rate for %s", currency)
+	}
+	return rate, nil
+}
+
+// NewExchangeRateProvider returns the provider configured by kind (file or http), location is the file
+// path or the URL to get the rates from.
+func NewExchangeRateProvider(kind, location string) (ExchangeRateProvider, error) {
+	switch strings.ToLower(kind) {
+	case "file":
+		provider, err := NewFileExchangeRateProvider(location)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	case "http":
+		return NewHTTPExchangeRateProvider(location), nil
+	}
+	return nil, fmt.Errorf("unknown exchange rate provider %s", kind)
+}
+
+// FileExchangeRateProvider is a stand-in provider reading a single snapshot of rates from a JSON
+// file , the date is ignored.
+type FileExchangeRateProvider struct {
+	rates *ExchangeRates
+}
+
+func NewFileExchangeRateProvider(path string) (*FileExchangeRateProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rates := &ExchangeRates{}
+	if err = json.Unmarshal(data, rates); err != nil {
+		return nil, err
+	}
+	return &FileExchangeRateProvider{rates: rates}, nil
+}
+
+func (p *FileExchangeRateProvider) Rate(from, to string, on time.Time) (float64, error) {
+	return p.rates.Rate(from, to)
+}
+
+// HTTPExchangeRateProvider gets the latest rates from an URL returning ExchangeRates, rates are
+// cached for a day.
+type HTTPExchangeRateProvider struct {
+	url       string
+	client    *http.Client
+	lock      sync.Mutex
+	rates     *ExchangeRates
+	fetchedOn time.Time
+}
+
+func NewHTTPExchangeRateProvider(url string) *HTTPExchangeRateProvider {
+	return &HTTPExchangeRateProvider{
+		url:    url,
+		client: &http.Client{Timeout: 2 * time.Second},
+	}
+}
+
+func (p *HTTPExchangeRateProvider) Rate(from, to string, on time.Time) (float64, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.rates == nil || time.Since(p.fetchedOn) > 24*time.Hour {
+		response, err := p.client.Get(p.url)
+		if err != nil {
+			return 0, err
+		}
+		defer response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			return 0, fmt.Errorf("exchange rates request failed with status %d", response.StatusCode)
+		}
+		rates := &ExchangeRates{}
+		if err = json.NewDecoder(response.Body).Decode(rates); err != nil {
+			return 0, err
+		}
+		p.rates = rates
+		p.fetchedOn = time.Now()
+	}
+	return p.rates.Rate(from, to)
+}
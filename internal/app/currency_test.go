@@ -0,0 +1,75 @@
+package app
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileExchangeRateProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	err := os.WriteFile(path, []byte(`{"base": "USD", "rates": {"MXN": 17.0, "EUR": 0.85}}`), 0600)
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	provider, err := NewFileExchangeRateProvider(path)
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	cases := []struct {
+		from, to string
+		expected float64
+	}{
+		{"USD", "USD", 1},
+		{"MXN", "USD", 1 / 17.0},
+		{"usd", "eur", 0.85},
+		{"EUR", "MXN", 17.0 / 0.85},
+	}
+	for _, c := range cases {
+		rate, err := provider.Rate(c.from, c.to, time.Now())
+		if err != nil {
+			t.Fatalf("Test failed with error %s", err)
+		}
+		if math.Abs(rate-c.expected) > 1e-9 {
+			t.Errorf("Expected %s/%s rate %f got %f", c.from, c.to, c.expected, rate)
+		}
+	}
+	if _, err := provider.Rate("JPY", "USD", time.Now()); err == nil {
+		t.Error("Expected error for an unknown currency")
+	}
+}
+
+type fixedRates map[string]float64
+
+func (f fixedRates) Rate(from, to string, on time.Time) (float64, error) {
+	return f[from+to], nil
+}
+
+func TestExpenseCategoryApplyExchangeRates(t *testing.T) {
+	category := &ExpenseCategory{
+		Category: "Venue",
+		Expenses: []*Expense{
+			{WhoPaid: "Jane", AmountPaid: 10000},
+			{WhoPaid: "Jane", AmountPaid: 170000, Currency: "mxn"},
+			{WhoPaid: "John", AmountPaid: 5000, Currency: "EUR", ExchangeRate: 1.2},
+		},
+	}
+	rates := fixedRates{"USDUSD": 1, "MXNUSD": 0.05, "EURUSD": 1.1}
+	if err := category.ApplyExchangeRates("usd", rates); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	// The EUR snapshot is kept
+	if category.Currency != "USD" || category.AmountPaid.String() != "245.00" {
+		t.Errorf("Expected 245 USD got %s %s", category.AmountPaid, category.Currency)
+	}
+	// Changing the base currency captures all the rates again
+	rates["USDEUR"], rates["MXNEUR"], rates["EUREUR"] = 0.5, 0.04, 1
+	if err := category.ApplyExchangeRates("EUR", rates); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if category.AmountPaid.String() != "168.00" {
+		t.Errorf("Expected 168 EUR got %s", category.AmountPaid)
+	}
+}
@@ -0,0 +1,88 @@
+package app
+
+import "time"
+
+// EventDashboard summarizes an event so the frontend does not have to list guests, tasks and expenses
+// to compute the counts itself. DaysUntilEvent is negative once the event is over, days are counted in
+// the time zone of the event.
+type EventDashboard struct {
+	EventId        string         `json:"eventId"`
+	Name           string         `json:"name"`
+	EventDay       time.Time      `json:"eventDay"`
+	DaysUntilEvent int            `json:"daysUntilEvent"`
+	Guests         *GuestCounts   `json:"guests"`
+	Tasks          *TaskCounts    `json:"tasks"`
+	Expenses       *ExpenseTotals `json:"expenses"`
+}
+
+// GuestCounts counts guests (invitations) and seats, confirmed guests are neither tentative nor not
+// attending.
+type GuestCounts struct {
+	Invited        int `json:"invited"`
+	Confirmed      int `json:"confirmed"`
+	Declined       int `json:"declined"`
+	Tentative      int `json:"tentative"`
+	Seats          int `json:"seats"`
+	ConfirmedSeats int `json:"confirmedSeats"`
+	TentativeSeats int `json:"tentativeSeats"`
+}
+
+// TaskCounts : Overdue are the PENDING tasks whose due date is already gone.
+type TaskCounts struct {
+	Total    int            `json:"total"`
+	ByStatus map[string]int `json:"byStatus"`
+	Overdue  int            `json:"overdue"`
+}
+
+// ExpenseTotals adds up the totals of every category, see ExpenseCategory.ComputeTotals.
+type ExpenseTotals struct {
+	Currency  string `json:"currency"`
+	Projected Money  `json:"projected"`
+	Paid      Money  `json:"paid"`
+	Total     Money  `json:"total"`
+	Remaining Money  `json:"remaining"`
+}
+
+func NewEventDashboard(event *Event, guests []*Guest, tasks []*Task, categories []*ExpenseCategory, now time.Time) *EventDashboard {
+	summary := event.ToSummary()
+	today := summary.Today(now)
+	dashboard := &EventDashboard{
+		EventId:        event.Id,
+		Name:           event.Name,
+		EventDay:       event.EventDay,
+		DaysUntilEvent: int(summary.Date().Sub(today).Hours() / 24),
+		Guests:         &GuestCounts{},
+		Tasks:          &TaskCounts{ByStatus: map[string]int{}},
+		Expenses:       &ExpenseTotals{Currency: event.Currency()},
+	}
+	for _, guest := range guests {
+		counts := dashboard.Guests
+		counts.Invited++
+		counts.Seats += guest.NumberOfSeats
+		switch {
+		case guest.NotAttending:
+			counts.Declined++
+		case guest.Tentative:
+			counts.Tentative++
+			counts.TentativeSeats += guest.NumberOfSeats
+		default:
+			counts.Confirmed++
+			counts.ConfirmedSeats += guest.AttendingSeats()
+		}
+	}
+	for _, task := range tasks {
+		dashboard.Tasks.Total++
+		dashboard.Tasks.ByStatus[task.Status]++
+		if due, ok := task.ResolveDueDate(event.EventDay); ok && task.Status == "PENDING" && CalendarDate(due, summary.Location()).Before(today) {
+			dashboard.Tasks.Overdue++
+		}
+	}
+	for _, category := range categories {
+		category.ComputeTotals()
+		dashboard.Expenses.Projected += category.AmountProjected
+		dashboard.Expenses.Paid += category.AmountPaid
+		dashboard.Expenses.Total += category.AmountTotal
+		dashboard.Expenses.Remaining += category.AmountRemaining
+	}
+	return dashboard
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewEventDashboard(t *testing.T) {
+	now := time.Date(2023, 5, 10, 23, 0, 0, 0, time.UTC)
+	event := &Event{Id: "event", EventDay: time.Date(2023, 5, 20, 1, 0, 0, 0, time.UTC)}
+	guests := []*Guest{
+		{NumberOfSeats: 2},
+		{NumberOfSeats: 3, Tentative: true},
+		{NumberOfSeats: 4, NotAttending: true},
+	}
+	daysBefore := 15
+	tasks := []*Task{
+		{Status: "PENDING", DueDate: now.AddDate(0, 0, -1)},
+		{Status: "PENDING", DaysBeforeEvent: &daysBefore},
+		{Status: "PENDING", DueDate: now.AddDate(0, 0, 1)},
+		{Status: "DONE", DueDate: now.AddDate(0, 0, -1)},
+	}
+	categories := []*ExpenseCategory{
+		{AmountProjected: 10000, AmountPaid: 2500},
+		{AmountProjected: 1000, AmountPaid: 1500},
+	}
+	dashboard := NewEventDashboard(event, guests, tasks, categories, now)
+	if dashboard.DaysUntilEvent != 10 {
+		t.Errorf("Expected 10 days until the event got %d", dashboard.DaysUntilEvent)
+	}
+	expectedGuests := GuestCounts{Invited: 3, Confirmed: 1, Declined: 1, Tentative: 1, Seats: 9, ConfirmedSeats: 2, TentativeSeats: 3}
+	if *dashboard.Guests != expectedGuests {
+		t.Errorf("Expected %+v got %+v", expectedGuests, dashboard.Guests)
+	}
+	if dashboard.Tasks.Total != 4 || dashboard.Tasks.ByStatus["PENDING"] != 3 || dashboard.Tasks.ByStatus["DONE"] != 1 {
+		t.Errorf("Unexpected task counts %+v", dashboard.Tasks)
+	}
+	if dashboard.Tasks.Overdue != 2 {
+		t.Errorf("Expected 2 overdue tasks got %d", dashboard.Tasks.Overdue)
+	}
+	expectedExpenses := ExpenseTotals{Currency: "USD", Projected: 11000, Paid: 4000, Total: 11500, Remaining: 7500}
+	if *dashboard.Expenses != expectedExpenses {
+		t.Errorf("Expected %+v got %+v", expectedExpenses, dashboard.Expenses)
+	}
+}
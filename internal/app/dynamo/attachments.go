@@ -0,0 +1,326 @@
+package dynamo
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+const _SORT_KEY_ATTACHMENT_PREFIX = "ATTACHMENT-"
+
+// How long presigned URLs are valid
+const _ATTACHMENT_URL_EXPIRATION = 15 * time.Minute
+
+// AttachmentService stores the attachment metadata under the event partition with sort key
+// ATTACHMENT-<entityType>-<entityId>-<attachmentId> so all the attachments of an entity are returned by
+// a single query, the content is stored in the BlobStorage under <eventId>/<attachmentId>.
+type AttachmentService struct {
+	db             *DBConfig
+	authorize      *AuthorizationService
+	storage        app.BlobStorage
+	limits         *app.AttachmentLimits
+	expenseService app.ExpenseService
+	vendorService  app.VendorService
+}
+
+func NewAttachmentService(db *DBConfig, authorize *AuthorizationService, storage app.BlobStorage, limits *app.AttachmentLimits, expense app.ExpenseService, vendor app.VendorService) *AttachmentService {
+	if db == nil {
+		log.Panicf("Null reference to db config in AttachmentService")
+	}
+	return &AttachmentService{
+		db:             db,
+		authorize:      authorize,
+		storage:        storage,
+		limits:         limits,
+		expenseService: expense,
+		vendorService:  vendor,
+	}
+}
+
+// Get returns the attachment with a presigned DownloadUrl once uploaded.
+func (c *AttachmentService) Get(eventManager, eventId, id string) (*app.Attachment, error) {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	attachment, err := c.get(eventId, id)
+	if err != nil || attachment == nil {
+		return nil, err
+	}
+	if attachment.Status == app.AttachmentUploaded {
+		attachment.DownloadUrl, err = c.storage.PresignDownload(attachment.Key, attachment.FileName, _ATTACHMENT_URL_EXPIRATION)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return attachment, nil
+}
+
+func (c *AttachmentService) List(eventManager, eventId, entityType, entityId string) ([]*app.Attachment, error) {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	log.Printf("Getting all attachments for %s %s in %s", entityType, entityId, eventId)
+	items, err := entityAttachments(c.db, eventId, strings.ToUpper(entityType), entityId)
+	if err != nil {
+		return nil, err
+	}
+	list := []*app.Attachment{}
+	for _, value := range items {
+		attachment := &app.Attachment{}
+		err = dynamodbattribute.UnmarshalMap(value, attachment)
+		if err != nil {
+			return nil, err
+		}
+		attachment.Id = *value[c.db.SORT_KEY].S
+		list = append(list, attachment)
+	}
+	return list, nil
+}
+
+// Create checks the declared content type and size against the limits, the attachment is PENDING until
+// Complete is called after the upload.
+func (c *AttachmentService) Create(eventManager, eventId string, u *app.Attachment) (*app.Attachment, error) {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	if err := writable(c.db, eventId); err != nil {
+		return nil, err
+	}
+	u.EntityType = strings.ToUpper(u.EntityType)
+	err := u.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if err = c.limits.Check(u.ContentType, u.Size); err != nil {
+		return nil, err
+	}
+	if err = c.checkEntity(eventManager, eventId, u); err != nil {
+		return nil, err
+	}
+	id, err := app.GenerateRandomId()
+	if err != nil {
+		return nil, err
+	}
+	u.Id = attachmentPrefix(u.EntityType, u.EntityId) + id
+	u.Key = eventId + "/" + id
+	u.Status = app.AttachmentPending
+	u.UploadedBy = strings.ToUpper(eventManager)
+	u.TimeCreatedOn = time.Now()
+	u.TimeUpdatedOn = time.Now()
+
+	log.Printf("Create attachment with Id /%s", u.Id)
+	if err = c.put(eventId, u); err != nil {
+		return nil, err
+	}
+	u.UploadUrl, err = c.storage.PresignUpload(u.Key, u.ContentType, u.Size, _ATTACHMENT_URL_EXPIRATION)
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Complete checks what was actually uploaded, content exceeding the limits is deleted.
+func (c *AttachmentService) Complete(eventManager, eventId, id string) (*app.Attachment, error) {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	if err := writable(c.db, eventId); err != nil {
+		return nil, err
+	}
+	attachment, err := c.get(eventId, id)
+	if err != nil {
+		return nil, err
+	}
+	if attachment == nil {
+		return nil, errors.New("attachment not found")
+	}
+	info, err := c.storage.Stat(attachment.Key)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.limits.Check(info.ContentType, info.Size); err != nil {
+		if deleteErr := c.storage.Delete(attachment.Key); deleteErr != nil {
+			log.Printf("WARN: Unable to delete rejected attachment %s - %s", attachment.Key, deleteErr)
+		}
+		return nil, err
+	}
+	attachment.Size = info.Size
+	attachment.Status = app.AttachmentUploaded
+	attachment.TimeUpdatedOn = time.Now()
+	if err = c.put(eventId, attachment); err != nil {
+		return nil, err
+	}
+	attachment.DownloadUrl, err = c.storage.PresignDownload(attachment.Key, attachment.FileName, _ATTACHMENT_URL_EXPIRATION)
+	if err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (c *AttachmentService) Delete(eventManager, eventId, id string) error {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return errors.New("unauthorized")
+	}
+	if err := writable(c.db, eventId); err != nil {
+		return err
+	}
+	attachment, err := c.get(eventId, id)
+	if err != nil || attachment == nil {
+		return err
+	}
+	if err = c.storage.Delete(attachment.Key); err != nil {
+		return err
+	}
+	input := &dynamodb.DeleteItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			c.db.PK_ID: {
+				S: aws.String(eventId),
+			},
+			c.db.SORT_KEY: {
+				S: aws.String(id),
+			},
+		},
+		TableName: &c.db.TableName,
+	}
+	_, err = c.db.DbService.DeleteItem(input)
+	if err != nil {
+		log.Printf("Got error calling delete attachment %s ", err)
+		return err
+	}
+	return nil
+}
+
+// checkEntity fails if the attached expense or vendor does not exist.
+func (c *AttachmentService) checkEntity(eventManager, eventId string, u *app.Attachment) error {
+	switch u.EntityType {
+	case app.AttachOnExpense:
+		expense, err := c.expenseService.GetItem(eventId, u.CategoryId, u.EntityId)
+		if err != nil {
+			return err
+		}
+		if expense == nil {
+			return errors.New("attached expense not found")
+		}
+	case app.AttachOnVendor:
+		vendor, err := c.vendorService.Get(eventManager, eventId, u.EntityId)
+		if err != nil {
+			return err
+		}
+		if vendor == nil {
+			return errors.New("attached vendor not found")
+		}
+	}
+	return nil
+}
+
+func (c *AttachmentService) get(eventId, id string) (*app.Attachment, error) {
+	input := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			c.db.PK_ID: {
+				S: aws.String(eventId),
+			},
+			c.db.SORT_KEY: {
+				S: aws.String(id),
+			},
+		},
+		TableName: &c.db.TableName,
+	}
+	result, err := c.db.DbService.GetItem(input)
+	if err != nil {
+		return nil, err
+	}
+	attachment := &app.Attachment{}
+	err = dynamodbattribute.UnmarshalMap(result.Item, attachment)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.Key == "" {
+		return nil, nil
+	}
+	attachment.Id = id
+	return attachment, nil
+}
+
+func (c *AttachmentService) put(eventId string, u *app.Attachment) error {
+	aAttachment, err := dynamodbattribute.MarshalMap(u)
+	if err != nil {
+		return err
+	}
+	aAttachment[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(eventId)}
+	aAttachment[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(u.Id)}
+	_, err = c.db.DbService.PutItem(&dynamodb.PutItemInput{
+		Item:      aAttachment,
+		TableName: &c.db.TableName,
+	})
+	return err
+}
+
+// entityAttachments returns the attachment items of an entity in the event eventId.
+func entityAttachments(db *DBConfig, eventId, entityType, entityId string) ([]map[string]*dynamodb.AttributeValue, error) {
+	return queryAll(db, &dynamodb.QueryInput{
+		TableName: aws.String(db.TableName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			db.PK_ID: {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(eventId),
+					},
+				},
+			},
+			db.SORT_KEY: {
+				ComparisonOperator: aws.String("BEGINS_WITH"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(attachmentPrefix(entityType, entityId)),
+					},
+				},
+			},
+		},
+	})
+}
+
+// attachmentDeletes returns the deletes of the metadata of the attachment items of the event eventId.
+func attachmentDeletes(db *DBConfig, eventId string, items []map[string]*dynamodb.AttributeValue) []*dynamodb.TransactWriteItem {
+	transactions := []*dynamodb.TransactWriteItem{}
+	for _, item := range items {
+		transactions = append(transactions, &dynamodb.TransactWriteItem{
+			Delete: &dynamodb.Delete{
+				Key: map[string]*dynamodb.AttributeValue{
+					db.PK_ID:    {S: aws.String(eventId)},
+					db.SORT_KEY: {S: aws.String(*item[db.SORT_KEY].S)},
+				},
+				TableName: &db.TableName,
+			},
+		})
+	}
+	return transactions
+}
+
+// deleteAttachmentBlobs deletes the content of the attachment items once their metadata is deleted, so
+// no attachment is left without content. Errors are only logged as the content is not reachable anymore.
+func deleteAttachmentBlobs(storage app.BlobStorage, items []map[string]*dynamodb.AttributeValue) {
+	for _, item := range items {
+		attachment := &app.Attachment{}
+		if err := dynamodbattribute.UnmarshalMap(item, attachment); err != nil {
+			log.Printf("WARN: Unable to read attachment - %s", err)
+			continue
+		}
+		if attachment.Key == "" {
+			continue
+		}
+		if err := storage.Delete(attachment.Key); err != nil {
+			log.Printf("WARN: Unable to delete the content of attachment %s - %s", attachment.Key, err)
+		}
+	}
+}
+
+func attachmentPrefix(entityType, entityId string) string {
+	return _SORT_KEY_ATTACHMENT_PREFIX + entityType + "-" + entityId + "-"
+}
@@ -0,0 +1,175 @@
+package dynamo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+const _SORT_KEY_AUDIT_PREFIX = "AUDIT-"
+
+// Attribute configured as TimeToLiveSpecification in the table, see template.yml
+const C_TTL = "ttl"
+
+const _DEFAULT_AUDIT_PAGE_SIZE = 25
+const _MAX_AUDIT_PAGE_SIZE = 100
+
+// Fixed width so the sort key order is the chronological order
+const _AUDIT_TIME_FORMAT = "2006-01-02T15:04:05.000000000Z"
+
+// AuditService stores the audit entries under the event partition with sort key AUDIT-<time>-<random>,
+// entries expire after retention through DynamoDB TTL.
+type AuditService struct {
+	db        *DBConfig
+	authorize *AuthorizationService
+	retention time.Duration
+}
+
+func NewAuditService(db *DBConfig, authorize *AuthorizationService, retention time.Duration) *AuditService {
+	if db == nil {
+		log.Panicf("Null reference to db config in AuditService")
+	}
+	return &AuditService{
+		db:        db,
+		authorize: authorize,
+		retention: retention,
+	}
+}
+
+func (c *AuditService) Record(entry *app.AuditEntry) error {
+	suffix, err := app.GenerateRandomId()
+	if err != nil {
+		return err
+	}
+	entry.Id = _SORT_KEY_AUDIT_PREFIX + entry.TimeCreatedOn.UTC().Format(_AUDIT_TIME_FORMAT) + "-" + suffix[:8]
+	aEntry, err := dynamodbattribute.MarshalMap(entry)
+	if err != nil {
+		return err
+	}
+	aEntry[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(entry.EventId)}
+	aEntry[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(entry.Id)}
+	if c.retention > 0 {
+		aEntry[C_TTL], err = dynamodbattribute.Marshal(entry.TimeCreatedOn.Add(c.retention).Unix())
+		if err != nil {
+			return err
+		}
+	}
+	input := &dynamodb.PutItemInput{
+		Item:      aEntry,
+		TableName: &c.db.TableName,
+	}
+	_, err = c.db.DbService.PutItem(input)
+	return err
+}
+
+// List returns the activity of an event newest first.
+func (c *AuditService) List(eventManager, eventId string, page *app.PageRequest) (*app.AuditPage, error) {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	limit := page.Limit
+	if limit <= 0 {
+		limit = _DEFAULT_AUDIT_PAGE_SIZE
+	}
+	if limit > _MAX_AUDIT_PAGE_SIZE {
+		limit = _MAX_AUDIT_PAGE_SIZE
+	}
+	var queryInput = &dynamodb.QueryInput{
+		TableName:        aws.String(c.db.TableName),
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int64(int64(limit)),
+		KeyConditions: map[string]*dynamodb.Condition{
+			c.db.PK_ID: {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(eventId),
+					},
+				},
+			},
+			c.db.SORT_KEY: {
+				ComparisonOperator: aws.String("BEGINS_WITH"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(_SORT_KEY_AUDIT_PREFIX),
+					},
+				},
+			},
+		},
+	}
+	if page.NextToken != "" {
+		startKey, err := c.decodeToken(eventId, page.NextToken)
+		if err != nil {
+			return nil, err
+		}
+		queryInput.ExclusiveStartKey = startKey
+	}
+
+	result, err := c.db.DbService.Query(queryInput)
+	if err != nil {
+		return nil, err
+	}
+	auditPage := &app.AuditPage{Items: []*app.AuditEntry{}}
+	for _, value := range result.Items {
+		entry := &app.AuditEntry{}
+		err = dynamodbattribute.UnmarshalMap(value, entry)
+		if err != nil {
+			return nil, err
+		}
+		entry.Id = *aws.String(*value[c.db.SORT_KEY].S)
+		entry.EventId = eventId
+		auditPage.Items = append(auditPage.Items, entry)
+	}
+	if sortKey, ok := result.LastEvaluatedKey[c.db.SORT_KEY]; ok {
+		token, err := json.Marshal(*sortKey.S)
+		if err != nil {
+			return nil, err
+		}
+		auditPage.NextToken = base64.URLEncoding.EncodeToString(token)
+	}
+	return auditPage, nil
+}
+
+// decodeToken returns the ExclusiveStartKey encoded in a NextToken, only the sort key is part of the
+// token so it can not be used to read a different partition.
+func (c *AuditService) decodeToken(eventId, token string) (map[string]*dynamodb.AttributeValue, error) {
+	data, err := base64.URLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.New("invalid nextToken")
+	}
+	var sortKey string
+	if err = json.Unmarshal(data, &sortKey); err != nil || !strings.HasPrefix(sortKey, _SORT_KEY_AUDIT_PREFIX) {
+		return nil, errors.New("invalid nextToken")
+	}
+	return map[string]*dynamodb.AttributeValue{
+		c.db.PK_ID:    {S: aws.String(eventId)},
+		c.db.SORT_KEY: {S: aws.String(sortKey)},
+	}, nil
+}
+
+// recordAudit records the mutation of entity from before to after, failures are logged but never fail
+// the mutation itself given it was already persisted.
+func recordAudit(audit app.AuditService, actor, eventId, entity, entityId string, before, after interface{}) {
+	if audit == nil {
+		return
+	}
+	entry, err := app.NewAuditEntry(strings.ToUpper(actor), eventId, entity, entityId, before, after)
+	if err != nil {
+		log.Printf("WARN: Unable to build audit entry for %s %s - %s", entity, entityId, err)
+		return
+	}
+	if entry.Action == app.AuditUpdate && len(entry.Changes) == 0 {
+		return
+	}
+	if err = audit.Record(entry); err != nil {
+		log.Printf("WARN: Unable to record audit entry for %s %s - %s", entity, entityId, err)
+	}
+}
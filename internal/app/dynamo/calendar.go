@@ -0,0 +1,251 @@
+package dynamo
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+// Every feed token is stored in its own partition so it is resolved with a single GetItem, the feed
+// itself is stored with sort key CALENDAR_FEED-<owner> under the event partition or the partition of
+// the owner, so each owner has its own token.
+const (
+	_PARTITION_CALENDAR_FEED_PREFIX = "CALENDAR_FEED#"
+	_SORT_KEY_CALENDAR_FEED_PREFIX  = "CALENDAR_FEED-"
+	_SORT_KEY_CALENDAR_FEED         = "CALENDAR_FEED"
+)
+
+type CalendarService struct {
+	db                  *DBConfig
+	authorize           *AuthorizationService
+	eventService        app.EventService
+	guestService        app.GuestService
+	taskService         app.TaskService
+	scheduleService     app.ScheduleService
+	notificationService app.Notifier
+}
+
+func NewCalendarService(db *DBConfig, authorize *AuthorizationService, event app.EventService, guest app.GuestService, task app.TaskService, schedule app.ScheduleService, notification app.Notifier) *CalendarService {
+	if db == nil {
+		log.Panicf("Null reference to db config in CalendarService")
+	}
+	return &CalendarService{
+		db:                  db,
+		authorize:           authorize,
+		eventService:        event,
+		guestService:        guest,
+		taskService:         task,
+		scheduleService:     schedule,
+		notificationService: notification,
+	}
+}
+
+func (c *CalendarService) GetFeed(eventManager, eventId string) (*app.CalendarFeed, error) {
+	eventManager = strings.ToUpper(eventManager)
+	if eventId != "" && !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	feed, err := c.get(c.partition(eventManager, eventId), _SORT_KEY_CALENDAR_FEED_PREFIX+eventManager)
+	if err != nil || feed != nil {
+		return feed, err
+	}
+	return c.create(eventManager, eventId, nil)
+}
+
+func (c *CalendarService) RotateFeed(eventManager, eventId string) (*app.CalendarFeed, error) {
+	eventManager = strings.ToUpper(eventManager)
+	if eventId != "" && !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	previous, err := c.get(c.partition(eventManager, eventId), _SORT_KEY_CALENDAR_FEED_PREFIX+eventManager)
+	if err != nil {
+		return nil, err
+	}
+	return c.create(eventManager, eventId, previous)
+}
+
+// Render returns nil when the owner of the feed is no longer an owner of the event, as if the token
+// did not exist.
+func (c *CalendarService) Render(token string) (*app.Calendar, error) {
+	feed, err := c.get(_PARTITION_CALENDAR_FEED_PREFIX+token, _SORT_KEY_CALENDAR_FEED)
+	if err != nil || feed == nil {
+		return nil, err
+	}
+	eventIds := []string{feed.EventId}
+	name := "Events"
+	if feed.EventId == "" {
+		events, err := c.eventService.List(feed.Owner)
+		if err != nil {
+			return nil, err
+		}
+		eventIds = []string{}
+		for _, event := range events {
+			eventIds = append(eventIds, event.Id)
+		}
+	} else if !c.authorize.Authorize(feed.Owner, feed.EventId) {
+		return nil, nil
+	}
+	calendar := app.NewCalendar(name, app.CalendarPublish, time.Now())
+	for _, eventId := range eventIds {
+		event, err := c.eventService.Get(eventId)
+		if err != nil {
+			return nil, err
+		}
+		if event == nil {
+			continue
+		}
+		if feed.EventId != "" {
+			calendar.Name = event.Name
+		}
+		schedule, err := c.scheduleService.List(feed.Owner, eventId)
+		if err != nil {
+			return nil, err
+		}
+		tasks, err := c.taskService.List(eventId)
+		if err != nil {
+			return nil, err
+		}
+		calendar.AddEventWithSchedule(event, schedule, tasks)
+	}
+	return calendar, nil
+}
+
+// SendInvitations keeps sending when an email fails, the failure is logged and the guest is not
+// counted.
+func (c *CalendarService) SendInvitations(eventManager, eventId string, guestIds []string) (int, error) {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return 0, errors.New("unauthorized")
+	}
+	if c.notificationService == nil {
+		return 0, errors.New("notifications are not enabled")
+	}
+	event, err := c.eventService.Get(eventId)
+	if err != nil {
+		return 0, err
+	}
+	if event == nil {
+		return 0, errors.New("event not found")
+	}
+	guests, err := c.guestService.List(eventManager, eventId)
+	if err != nil {
+		return 0, err
+	}
+	schedule, err := c.scheduleService.List(eventManager, eventId)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, guest := range app.InvitedGuests(guests, guestIds) {
+		invitation, invited := app.NewInvitation(eventManager, event, guest, schedule, time.Now())
+		body, err := app.TemplateInvitation(event, guest, invited)
+		if err != nil {
+			return sent, err
+		}
+		err = c.notificationService.Notify(&app.Notification{
+			Type:      app.NotificationInvitation,
+			EventId:   eventId,
+			Recipient: guest.Email,
+			Subject:   "Invitation to " + event.Name,
+			Body:      body.String(),
+			Attachments: []*app.EmailAttachment{{
+				FileName:    "invite.ics",
+				ContentType: "text/calendar; charset=UTF-8; method=" + app.CalendarRequest,
+				Content:     []byte(invitation.String()),
+			}},
+		})
+		if err != nil {
+			log.Printf("WARN: Failed to send invitation to %s with error %s", guest.Email, err)
+			continue
+		}
+		sent++
+	}
+	log.Printf("Sent %d invitations for %s", sent, eventId)
+	return sent, nil
+}
+
+// create stores a new token for the feed replacing the previous one, if any, in a single transaction.
+func (c *CalendarService) create(eventManager, eventId string, previous *app.CalendarFeed) (*app.CalendarFeed, error) {
+	token, err := app.GenerateRandomId()
+	if err != nil {
+		return nil, err
+	}
+	feed := &app.CalendarFeed{
+		Token:         token,
+		Owner:         eventManager,
+		EventId:       eventId,
+		TimeCreatedOn: time.Now(),
+	}
+	aFeed, err := dynamodbattribute.MarshalMap(feed)
+	if err != nil {
+		return nil, err
+	}
+	aToken, err := dynamodbattribute.MarshalMap(feed)
+	if err != nil {
+		return nil, err
+	}
+	transactions := []*dynamodb.TransactWriteItem{
+		{
+			Put: &dynamodb.Put{
+				Item:      c.withKey(aFeed, c.partition(eventManager, eventId), _SORT_KEY_CALENDAR_FEED_PREFIX+eventManager),
+				TableName: &c.db.TableName,
+			},
+		},
+		{
+			Put: &dynamodb.Put{
+				Item:      c.withKey(aToken, _PARTITION_CALENDAR_FEED_PREFIX+token, _SORT_KEY_CALENDAR_FEED),
+				TableName: &c.db.TableName,
+			},
+		},
+	}
+	if previous != nil {
+		transactions = append(transactions, &dynamodb.TransactWriteItem{
+			Delete: &dynamodb.Delete{
+				Key:       c.withKey(map[string]*dynamodb.AttributeValue{}, _PARTITION_CALENDAR_FEED_PREFIX+previous.Token, _SORT_KEY_CALENDAR_FEED),
+				TableName: &c.db.TableName,
+			},
+		})
+	}
+	if err = transactWrite(c.db, transactions); err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
+
+func (c *CalendarService) get(partition, sortKey string) (*app.CalendarFeed, error) {
+	input := &dynamodb.GetItemInput{
+		Key:       c.withKey(map[string]*dynamodb.AttributeValue{}, partition, sortKey),
+		TableName: &c.db.TableName,
+	}
+	result, err := c.db.DbService.GetItem(input)
+	if err != nil {
+		return nil, err
+	}
+	feed := &app.CalendarFeed{}
+	err = dynamodbattribute.UnmarshalMap(result.Item, feed)
+	if err != nil {
+		return nil, err
+	}
+	if feed.Token == "" {
+		return nil, nil
+	}
+	return feed, nil
+}
+
+func (c *CalendarService) partition(eventManager, eventId string) string {
+	if eventId == "" {
+		return userPartition(eventManager)
+	}
+	return eventId
+}
+
+func (c *CalendarService) withKey(item map[string]*dynamodb.AttributeValue, partition, sortKey string) map[string]*dynamodb.AttributeValue {
+	item[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(partition)}
+	item[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(sortKey)}
+	return item
+}
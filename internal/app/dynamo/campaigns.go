@@ -0,0 +1,359 @@
+package dynamo
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/craguilar/event-management-service/internal/app"
+	"golang.org/x/exp/slices"
+)
+
+const _SORT_KEY_CAMPAIGN_PREFIX = "CAMPAIGN-"
+
+// CampaignService stores the campaigns under the event partition, the recipients are kept in the item
+// itself as they are bounded by the guests of the event.
+type CampaignService struct {
+	db                  *DBConfig
+	authorize           *AuthorizationService
+	audit               app.AuditService
+	eventService        app.EventService
+	guestService        app.GuestService
+	scheduleService     app.ScheduleService
+	notificationService app.Notifier
+}
+
+func NewCampaignService(db *DBConfig, authorize *AuthorizationService, audit app.AuditService, event app.EventService, guest app.GuestService, schedule app.ScheduleService, notification app.Notifier) *CampaignService {
+	if db == nil {
+		log.Panicf("Null reference to db config in CampaignService")
+	}
+	return &CampaignService{
+		db:                  db,
+		authorize:           authorize,
+		audit:               audit,
+		eventService:        event,
+		guestService:        guest,
+		scheduleService:     schedule,
+		notificationService: notification,
+	}
+}
+
+func (c *CampaignService) Get(eventManager, eventId, id string) (*app.Campaign, error) {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	return c.get(eventId, id)
+}
+
+// List returns the campaigns without their recipients, only the totals.
+func (c *CampaignService) List(eventManager, eventId string) ([]*app.Campaign, error) {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	items, err := queryAll(c.db, &dynamodb.QueryInput{
+		TableName: aws.String(c.db.TableName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			c.db.PK_ID: {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(eventId),
+					},
+				},
+			},
+			c.db.SORT_KEY: {
+				ComparisonOperator: aws.String("BEGINS_WITH"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(_SORT_KEY_CAMPAIGN_PREFIX),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	list := []*app.Campaign{}
+	for _, value := range items {
+		campaign := &app.Campaign{}
+		if err = dynamodbattribute.UnmarshalMap(value, campaign); err != nil {
+			return nil, err
+		}
+		campaign.Id = *value[c.db.SORT_KEY].S
+		campaign.ComputeTotals()
+		campaign.Recipients = nil
+		list = append(list, campaign)
+	}
+	return list, nil
+}
+
+func (c *CampaignService) CreateOrUpdate(eventManager, eventId string, u *app.Campaign) (*app.Campaign, error) {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	if err := writable(c.db, eventId); err != nil {
+		return nil, err
+	}
+	err := u.Validate()
+	if err != nil {
+		return nil, err
+	}
+	var value *app.Campaign
+	if u.Id == "" {
+		id, err := app.GenerateRandomId()
+		if err != nil {
+			return nil, err
+		}
+		u.Id = _SORT_KEY_CAMPAIGN_PREFIX + id
+		u.TimeCreatedOn = time.Now()
+	} else {
+		value, err = c.get(eventId, u.Id)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			return nil, errors.New("campaign not found")
+		}
+		if value.Status != app.CampaignDraft {
+			return nil, app.ErrCampaignSent
+		}
+		u.TimeCreatedOn = value.TimeCreatedOn
+	}
+	u.Status = app.CampaignDraft
+	u.Recipients = []*app.CampaignRecipient{}
+	u.TimeSentOn = time.Time{}
+	u.TimeUpdatedOn = time.Now()
+	if err = c.put(eventId, u, nil); err != nil {
+		return nil, err
+	}
+	recordAudit(c.audit, eventManager, eventId, app.AuditCampaign, u.Id, value, u)
+	return u, nil
+}
+
+func (c *CampaignService) Delete(eventManager, eventId, id string) error {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return errors.New("unauthorized")
+	}
+	if err := writable(c.db, eventId); err != nil {
+		return err
+	}
+	value, err := c.get(eventId, id)
+	if err != nil || value == nil {
+		return err
+	}
+	input := &dynamodb.DeleteItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			c.db.PK_ID: {
+				S: aws.String(eventId),
+			},
+			c.db.SORT_KEY: {
+				S: aws.String(id),
+			},
+		},
+		TableName: &c.db.TableName,
+	}
+	if _, err = c.db.DbService.DeleteItem(input); err != nil {
+		return err
+	}
+	recordAudit(c.audit, eventManager, eventId, app.AuditCampaign, id, value, nil)
+	return nil
+}
+
+// Send resolves the recipients out of the current guest list, a campaign is sent only once.
+func (c *CampaignService) Send(eventManager, eventId, id string) (*app.Campaign, error) {
+	campaign, err := c.sendable(eventManager, eventId, id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status != app.CampaignDraft {
+		return nil, app.ErrCampaignSent
+	}
+	guests, err := c.guestService.List(eventManager, eventId)
+	if err != nil {
+		return nil, err
+	}
+	campaign.Resolve(guests)
+	if err = c.claim(eventId, campaign); err != nil {
+		return nil, err
+	}
+	return c.deliver(eventManager, eventId, campaign, guests)
+}
+
+// Resend only emails the recipients that failed, the guests changed since are not added. Campaigns
+// whose send was interrupted are sent to the recipients left.
+func (c *CampaignService) Resend(eventManager, eventId, id string) (*app.Campaign, error) {
+	campaign, err := c.sendable(eventManager, eventId, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = campaign.Resendable(time.Now()); err != nil {
+		return nil, err
+	}
+	guests, err := c.guestService.List(eventManager, eventId)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.claim(eventId, campaign); err != nil {
+		return nil, err
+	}
+	return c.deliver(eventManager, eventId, campaign, guests)
+}
+
+// claim stores the campaign SENDING only if nobody changed it since it was read, concurrent sends of
+// the same campaign fail with ErrCampaignSending.
+func (c *CampaignService) claim(eventId string, campaign *app.Campaign) error {
+	updatedOn := campaign.TimeUpdatedOn
+	campaign.Claim(time.Now())
+	err := c.put(eventId, campaign, &updatedOn)
+	var conditional *dynamodb.ConditionalCheckFailedException
+	if errors.As(err, &conditional) {
+		return app.ErrCampaignSending
+	}
+	return err
+}
+
+func (c *CampaignService) sendable(eventManager, eventId, id string) (*app.Campaign, error) {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	if err := writable(c.db, eventId); err != nil {
+		return nil, err
+	}
+	if c.notificationService == nil {
+		return nil, errors.New("notifications are not enabled")
+	}
+	campaign, err := c.get(eventId, id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign == nil {
+		return nil, errors.New("campaign not found")
+	}
+	return campaign, nil
+}
+
+// deliver emails the pending and failed recipients of a claimed campaign and stores the result of every
+// one of them. Each email has the key of its attempt, so the retry of an interrupted send gets the same
+// keys as the emails already in the outbox and they are not sent twice.
+func (c *CampaignService) deliver(eventManager, eventId string, campaign *app.Campaign, guests []*app.Guest) (*app.Campaign, error) {
+	event, err := c.eventService.Get(eventId)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, errors.New("event not found")
+	}
+	schedule, err := c.scheduleService.List(eventManager, eventId)
+	if err != nil {
+		return nil, err
+	}
+	subject := campaign.DefaultSubject(event.Name)
+	campaign.Deliver(func(recipient *app.CampaignRecipient) error {
+		i := slices.IndexFunc(guests, func(guest *app.Guest) bool { return guest.Id == recipient.GuestId })
+		if i < 0 {
+			return app.ErrGuestNotFound
+		}
+		invitation, invited := app.NewInvitation(eventManager, event, guests[i], schedule, time.Now())
+		body, err := app.TemplateCampaign(campaign, event, guests[i], invited)
+		if err != nil {
+			return err
+		}
+		attachments := []*app.EmailAttachment{}
+		if campaign.Template == app.CampaignInvitation {
+			attachments = append(attachments, &app.EmailAttachment{
+				FileName:    "invite.ics",
+				ContentType: "text/calendar; charset=UTF-8; method=" + app.CalendarRequest,
+				Content:     []byte(invitation.String()),
+			})
+		}
+		err = c.notificationService.Notify(&app.Notification{
+			Key:         app.NotificationKey(campaign.Id, recipient.GuestId, strconv.Itoa(recipient.Attempts)),
+			Type:        app.NotificationCampaign,
+			EventId:     eventId,
+			Recipient:   recipient.Email,
+			Subject:     subject,
+			Body:        body.String(),
+			Attachments: attachments,
+		})
+		if err != nil && !errors.Is(err, app.ErrNotificationSuppressed) {
+			log.Printf("WARN: Failed to send campaign %s to %s with error %s", campaign.Id, recipient.Email, err)
+		}
+		return err
+	})
+	claimedOn := campaign.TimeUpdatedOn
+	campaign.TimeUpdatedOn = time.Now()
+	err = c.put(eventId, campaign, &claimedOn)
+	var conditional *dynamodb.ConditionalCheckFailedException
+	if errors.As(err, &conditional) {
+		// The claim expired and another Resend took the campaign over
+		return nil, app.ErrCampaignSending
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Sent campaign %s of %s to %d recipients, %d failed, %d suppressed", campaign.Id, eventId, campaign.Sent, campaign.Failed, campaign.Suppressed)
+	return campaign, nil
+}
+
+// get returns nil for ids of other entities, so a client supplied id never reads or overrides them.
+func (c *CampaignService) get(eventId, id string) (*app.Campaign, error) {
+	if !strings.HasPrefix(id, _SORT_KEY_CAMPAIGN_PREFIX) {
+		return nil, nil
+	}
+	input := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			c.db.PK_ID: {
+				S: aws.String(eventId),
+			},
+			c.db.SORT_KEY: {
+				S: aws.String(id),
+			},
+		},
+		TableName: &c.db.TableName,
+	}
+	result, err := c.db.DbService.GetItem(input)
+	if err != nil {
+		return nil, err
+	}
+	campaign := &app.Campaign{}
+	if err = dynamodbattribute.UnmarshalMap(result.Item, campaign); err != nil {
+		return nil, err
+	}
+	if campaign.Name == "" {
+		return nil, nil
+	}
+	campaign.Id = id
+	campaign.ComputeTotals()
+	return campaign, nil
+}
+
+// put stores the campaign, only if its timeUpdatedOn is still updatedOn when set.
+func (c *CampaignService) put(eventId string, u *app.Campaign, updatedOn *time.Time) error {
+	aCampaign, err := dynamodbattribute.MarshalMap(u)
+	if err != nil {
+		return err
+	}
+	aCampaign[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(eventId)}
+	aCampaign[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(u.Id)}
+	input := &dynamodb.PutItemInput{
+		Item:      aCampaign,
+		TableName: &c.db.TableName,
+	}
+	if updatedOn != nil {
+		aUpdatedOn, err := dynamodbattribute.Marshal(updatedOn)
+		if err != nil {
+			return err
+		}
+		input.ConditionExpression = aws.String("#updatedOn = :updatedOn")
+		input.ExpressionAttributeNames = map[string]*string{"#updatedOn": aws.String("timeUpdatedOn")}
+		input.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{":updatedOn": aUpdatedOn}
+	}
+	_, err = c.db.DbService.PutItem(input)
+	return err
+}
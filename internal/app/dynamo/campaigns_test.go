@@ -0,0 +1,92 @@
+package dynamo
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/craguilar/event-management-service/internal/app"
+	"github.com/craguilar/event-management-service/internal/app/mock"
+)
+
+// campaignFixture returns the campaign service of event 1 with a guest, GetItem answers with campaign
+func campaignFixture(t *testing.T, campaign *app.Campaign, putItem fakeResponse) (*CampaignService, *app.MemoryNotifier, *fakeDynamo) {
+	events := mock.NewEventService()
+	if _, err := events.CreateOrUpdate("ana@example.com", &app.Event{Id: "1", Name: "Wedding", MainLocation: "Puebla", EventDay: time.Now().AddDate(0, 2, 0)}); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	guests := mock.NewGuestService(events)
+	if _, err := guests.CreateOrUpdate("ana@example.com", "1", &app.Guest{Id: "GUEST-1", FirstName: "Luis", LastName: "Perez", Email: "luis@example.com", NumberOfSeats: 1}); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	db, fake := newFakeDb(t, map[string]fakeResponse{
+		"GetItem": getItems(t, map[string]interface{}{
+			_SORT_KEY_OWNER_PREFIX + "ANA@EXAMPLE.COM": &app.EventOwner{OwnerEmail: "ANA@EXAMPLE.COM", EventSummary: &app.EventSummary{Id: "1", Name: "Wedding"}},
+			_SORT_KEY_EVENT_PREFIX + "1":               &app.Event{Id: "1", Name: "Wedding", Status: app.EventPlanning},
+			campaign.Id:                                campaign,
+		}),
+		"PutItem": putItem,
+	})
+	notifier := app.NewMemoryNotifier()
+	campaigns := NewCampaignService(db, NewAuthorizationService(db), nil, events, guests, mock.NewScheduleService(guests), notifier)
+	return campaigns, notifier, fake
+}
+
+func TestCampaignSendClaimedOnce(t *testing.T) {
+	campaign := &app.Campaign{Id: _SORT_KEY_CAMPAIGN_PREFIX + "1", Name: "Save the date", Template: app.CampaignSaveTheDate, Status: app.CampaignDraft, TimeUpdatedOn: time.Now()}
+	campaigns, notifier, fake := campaignFixture(t, campaign, respond(http.StatusBadRequest, dynamoError("ConditionalCheckFailedException")))
+	_, err := campaigns.Send("ana@example.com", "1", campaign.Id)
+	if !errors.Is(err, app.ErrCampaignSending) {
+		t.Errorf("Expected a campaign claimed by another send to fail got %v", err)
+	}
+	if len(notifier.Notifications()) != 0 || fake.called("PutItem") != 1 {
+		t.Errorf("Expected no email once the claim failed got %d", len(notifier.Notifications()))
+	}
+}
+
+func TestCampaignSendKeysEveryAttempt(t *testing.T) {
+	campaign := &app.Campaign{Id: _SORT_KEY_CAMPAIGN_PREFIX + "1", Name: "Save the date", Template: app.CampaignSaveTheDate, Status: app.CampaignDraft, TimeUpdatedOn: time.Now()}
+	campaigns, notifier, fake := campaignFixture(t, campaign, respond(http.StatusOK, map[string]interface{}{}))
+	sent, err := campaigns.Send("ana@example.com", "1", campaign.Id)
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if sent.Status != app.CampaignSent || sent.Sent != 1 {
+		t.Errorf("Expected the campaign sent to 1 recipient got %s %d", sent.Status, sent.Sent)
+	}
+	notifications := notifier.Notifications()
+	if len(notifications) != 1 || notifications[0].Key != app.NotificationKey(campaign.Id, "GUEST-1", "1") {
+		t.Fatalf("Expected the email keyed by its attempt got %v", notifications)
+	}
+	// Claim and result, both conditional
+	if fake.called("PutItem") != 2 {
+		t.Errorf("Expected the claim and the result stored got %d", fake.called("PutItem"))
+	}
+	for i, call := range fake.calls {
+		if call == "PutItem" && fake.inputs[i]["ConditionExpression"] != "#updatedOn = :updatedOn" {
+			t.Errorf("Expected conditional puts got %v", fake.inputs[i]["ConditionExpression"])
+		}
+	}
+}
+
+func TestCampaignResendInterrupted(t *testing.T) {
+	// Claimed by a send that timed out after the email of the first attempt
+	claimedOn := time.Now().Add(-app.CampaignClaimTimeout - time.Minute)
+	campaign := &app.Campaign{
+		Id:            _SORT_KEY_CAMPAIGN_PREFIX + "1",
+		Name:          "Save the date",
+		Template:      app.CampaignSaveTheDate,
+		Status:        app.CampaignSending,
+		Recipients:    []*app.CampaignRecipient{{GuestId: "GUEST-1", Email: "luis@example.com", Status: app.CampaignPending}},
+		TimeUpdatedOn: claimedOn,
+	}
+	campaigns, notifier, _ := campaignFixture(t, campaign, respond(http.StatusOK, map[string]interface{}{}))
+	if _, err := campaigns.Resend("ana@example.com", "1", campaign.Id); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	notifications := notifier.Notifications()
+	if len(notifications) != 1 || notifications[0].Key != app.NotificationKey(campaign.Id, "GUEST-1", "1") {
+		t.Errorf("Expected the key of the interrupted attempt got %v", notifications)
+	}
+}
@@ -0,0 +1,282 @@
+package dynamo
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/craguilar/event-management-service/internal/app"
+	"golang.org/x/exp/slices"
+)
+
+const _SORT_KEY_COMMENT_PREFIX = "COMMENT-"
+
+// Sort key prefix of the entities a comment can be attached to
+var commentEntityPrefixes = map[string]string{
+	app.CommentOnTask:            _SORT_KEY_TASK_PREFIX,
+	app.CommentOnGuest:           _SORT_KEY_GUEST_PREFIX,
+	app.CommentOnExpenseCategory: _SORT_KEY_EXPENSE_CATEGORY_PREFIX,
+}
+
+// CommentService stores comments under the event partition with sort key COMMENT-<entityId>-<commentId>
+// so all the comments of an entity are returned by a single query.
+type CommentService struct {
+	db                  *DBConfig
+	authorize           *AuthorizationService
+	eventService        *EventService
+	notificationService app.Notifier
+}
+
+func NewCommentService(db *DBConfig, authorize *AuthorizationService, event *EventService, notification app.Notifier) *CommentService {
+	if db == nil {
+		log.Panicf("Null reference to db config in CommentService")
+	}
+	return &CommentService{
+		db:                  db,
+		authorize:           authorize,
+		eventService:        event,
+		notificationService: notification,
+	}
+}
+
+func (c *CommentService) Get(eventId, id string) (*app.Comment, error) {
+	input := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			c.db.PK_ID: {
+				S: aws.String(eventId),
+			},
+			c.db.SORT_KEY: {
+				S: aws.String(id),
+			},
+		},
+		TableName: &c.db.TableName,
+	}
+
+	result, err := c.db.DbService.GetItem(input)
+	if err != nil {
+		return nil, err
+	}
+	comment := &app.Comment{}
+	err = dynamodbattribute.UnmarshalMap(result.Item, comment)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Body == "" {
+		return nil, nil
+	}
+	comment.Id = id
+	return comment, nil
+}
+
+func (c *CommentService) List(eventId, entityId string) ([]*app.Comment, error) {
+	log.Printf("Getting all comments for %s in %s", entityId, eventId)
+	var queryInput = &dynamodb.QueryInput{
+		TableName: aws.String(c.db.TableName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			c.db.PK_ID: {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(eventId),
+					},
+				},
+			},
+			c.db.SORT_KEY: {
+				ComparisonOperator: aws.String("BEGINS_WITH"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(_SORT_KEY_COMMENT_PREFIX + entityId + "-"),
+					},
+				},
+			},
+		},
+	}
+
+	items, err := queryAll(c.db, queryInput)
+	if err != nil {
+		return nil, err
+	}
+	list := []*app.Comment{}
+	for _, value := range items {
+		comment := &app.Comment{}
+		err = dynamodbattribute.UnmarshalMap(value, comment)
+		if err != nil {
+			return nil, err
+		}
+		comment.Id = *aws.String(*value[c.db.SORT_KEY].S)
+		list = append(list, comment)
+	}
+	// Oldest first , as in any conversation
+	slices.SortFunc(list, func(a, b *app.Comment) bool {
+		return a.TimeCreatedOn.Before(b.TimeCreatedOn)
+	})
+	return list, nil
+}
+
+// CreateOrUpdate creates a new comment if u.Id is empty , otherwise updates the Body of an existing
+// comment which is ONLY allowed to the comment author.
+func (c *CommentService) CreateOrUpdate(author, eventId string, u *app.Comment) (*app.Comment, error) {
+	author = strings.ToUpper(author)
+	err := u.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if !c.authorize.Authorize(author, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	if err = writable(c.db, eventId); err != nil {
+		return nil, err
+	}
+	var previousMentions []string
+	if u.Id == "" {
+		u.EntityId, err = c.entitySortKey(eventId, u.EntityType, u.EntityId)
+		if err != nil {
+			return nil, err
+		}
+		commentId, err := app.GenerateRandomId()
+		if err != nil {
+			return nil, err
+		}
+		u.Id = _SORT_KEY_COMMENT_PREFIX + u.EntityId + "-" + commentId
+		u.Author = author
+		u.TimeCreatedOn = time.Now()
+	} else {
+		value, err := c.Get(eventId, u.Id)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			return nil, errors.New("comment not found")
+		}
+		if value.Author != author {
+			return nil, errors.New("unauthorized")
+		}
+		// Only the body can be edited
+		value.Body = u.Body
+		previousMentions = value.Mentions
+		u = value
+	}
+	u.TimeUpdatedOn = time.Now()
+	// Only shared emails can be mentioned
+	owners, err := c.eventService.ListOwners(eventId)
+	if err != nil {
+		return nil, err
+	}
+	u.Mentions = []string{}
+	for _, mention := range app.ParseMentions(u.Body) {
+		if slices.Contains(owners.SharedEmails, mention) {
+			u.Mentions = append(u.Mentions, mention)
+		}
+	}
+
+	log.Printf("CreateOrUpdate comment with Id /%s", u.Id)
+	aComment, err := dynamodbattribute.MarshalMap(u)
+	if err != nil {
+		return nil, err
+	}
+	aComment[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(eventId)}
+	aComment[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(u.Id)}
+	input := &dynamodb.PutItemInput{
+		Item:      aComment,
+		TableName: &c.db.TableName,
+	}
+	_, err = c.db.DbService.PutItem(input)
+	if err != nil {
+		return nil, err
+	}
+	c.notifyMentions(eventId, u, previousMentions)
+	return u, nil
+}
+
+func (c *CommentService) Delete(author, eventId, id string) error {
+	if err := writable(c.db, eventId); err != nil {
+		return err
+	}
+	author = strings.ToUpper(author)
+	value, err := c.Get(eventId, id)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		return nil
+	}
+	if value.Author != author {
+		return errors.New("unauthorized")
+	}
+	input := &dynamodb.DeleteItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			c.db.PK_ID: {
+				S: aws.String(eventId),
+			},
+			c.db.SORT_KEY: {
+				S: aws.String(id),
+			},
+		},
+		TableName: &c.db.TableName,
+	}
+
+	_, err = c.db.DbService.DeleteItem(input)
+	if err != nil {
+		log.Printf("Got error calling delete comment %s ", err)
+		return err
+	}
+	return nil
+}
+
+// entitySortKey returns the sort key of the commented entity, failing if it does not exist. Ids are
+// accepted with or without the entity prefix.
+func (c *CommentService) entitySortKey(eventId, entityType, entityId string) (string, error) {
+	prefix := commentEntityPrefixes[entityType]
+	if !strings.HasPrefix(entityId, prefix) {
+		entityId = prefix + entityId
+	}
+	input := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			c.db.PK_ID: {
+				S: aws.String(eventId),
+			},
+			c.db.SORT_KEY: {
+				S: aws.String(entityId),
+			},
+		},
+		TableName: &c.db.TableName,
+	}
+	result, err := c.db.DbService.GetItem(input)
+	if err != nil {
+		return "", err
+	}
+	if len(result.Item) == 0 {
+		return "", errors.New("commented entity not found")
+	}
+	return entityId, nil
+}
+
+// notifyMentions sends an email to every new mention except the author, failures are only logged.
+func (c *CommentService) notifyMentions(eventId string, comment *app.Comment, previousMentions []string) {
+	if c.notificationService == nil || len(comment.Mentions) == 0 {
+		return
+	}
+	event, err := c.eventService.Get(eventId)
+	if err != nil || event == nil {
+		log.Printf("WARN: Unable to notify mentions for comment %s", comment.Id)
+		return
+	}
+	template, err := app.TemplateCommentMentionNotification(event.Name, comment)
+	if err != nil {
+		log.Printf("WARN: Unable to template mention for comment %s with error %s", comment.Id, err)
+		return
+	}
+	for _, mention := range comment.Mentions {
+		if mention == comment.Author || slices.Contains(previousMentions, mention) {
+			continue
+		}
+		err = c.notificationService.Notify(&app.Notification{Type: app.NotificationMention, EventId: eventId, Recipient: strings.ToLower(mention), Subject: "You were mentioned in " + event.Name, Body: template.String()})
+		if err != nil {
+			log.Printf("WARN: Failed to send mention notification for %s with error %s", mention, err)
+		}
+	}
+}
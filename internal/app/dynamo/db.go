@@ -1,7 +1,9 @@
 package dynamo
 
 import (
+	"fmt"
 	"log"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
@@ -13,21 +15,29 @@ const C_PK_ID = "id"
 const C_SORT_KEY = "entityType"
 const C_GSI_OWNER = "ownerIdx"
 
+// Sparse index of the outbox messages pending, only their items have the pendingOn range key
+const C_GSI_PENDING = "pendingIdx"
+
+// DynamoDB does not accept more than 100 items in a single TransactWriteItems call
+const _MAX_TRANSACT_ITEMS = 100
+
 type DBConfig struct {
-	DbService *dynamodb.DynamoDB
-	TableName string
-	PK_ID     string
-	SORT_KEY  string
-	GSI_OWNER string
+	DbService   *dynamodb.DynamoDB
+	TableName   string
+	PK_ID       string
+	SORT_KEY    string
+	GSI_OWNER   string
+	GSI_PENDING string
 }
 
 func InitDb(db *dynamodb.DynamoDB, tableName string) *DBConfig {
 	return &DBConfig{
-		DbService: db,
-		TableName: tableName,
-		PK_ID:     C_PK_ID,
-		SORT_KEY:  C_SORT_KEY,
-		GSI_OWNER: C_GSI_OWNER,
+		DbService:   db,
+		TableName:   tableName,
+		PK_ID:       C_PK_ID,
+		SORT_KEY:    C_SORT_KEY,
+		GSI_OWNER:   C_GSI_OWNER,
+		GSI_PENDING: C_GSI_PENDING,
 	}
 }
 
@@ -44,3 +54,69 @@ func InitLocalDb(overrideUrl, tableName string) *DBConfig {
 	}
 	return InitDb(dynamodb.New(awsSession), tableName)
 }
+
+// queryAll runs a Query following LastEvaluatedKey until every page has been read.
+func queryAll(db *DBConfig, input *dynamodb.QueryInput) ([]map[string]*dynamodb.AttributeValue, error) {
+	items := []map[string]*dynamodb.AttributeValue{}
+	for {
+		result, err := db.DbService.Query(input)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, result.Items...)
+		if len(result.LastEvaluatedKey) == 0 {
+			return items, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// queryPartition returns ALL items stored under the given partition key.
+func queryPartition(db *DBConfig, id string) ([]map[string]*dynamodb.AttributeValue, error) {
+	return queryAll(db, &dynamodb.QueryInput{
+		TableName: aws.String(db.TableName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			db.PK_ID: {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(id),
+					},
+				},
+			},
+		},
+	})
+}
+
+// transactWrite splits the items in chunks of _MAX_TRANSACT_ITEMS, each chunk is atomic BUT the
+// whole write is not, callers MUST be idempotent so a failed call can be retried.
+func transactWrite(db *DBConfig, items []*dynamodb.TransactWriteItem) error {
+	for start := 0; start < len(items); start += _MAX_TRANSACT_ITEMS {
+		end := start + _MAX_TRANSACT_ITEMS
+		if end > len(items) {
+			end = len(items)
+		}
+		input := &dynamodb.TransactWriteItemsInput{TransactItems: items[start:end]}
+		if _, err := db.DbService.TransactWriteItems(input); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setExpression returns an UpdateExpression SET clause assigning every attribute in item, attribute
+// names and values are always aliased so reserved words are not an issue.
+func setExpression(item map[string]*dynamodb.AttributeValue) (string, map[string]*string, map[string]*dynamodb.AttributeValue) {
+	assignments := []string{}
+	names := map[string]*string{}
+	values := map[string]*dynamodb.AttributeValue{}
+	i := 0
+	for key, value := range item {
+		name, placeholder := fmt.Sprintf("#a%d", i), fmt.Sprintf(":a%d", i)
+		names[name] = aws.String(key)
+		values[placeholder] = value
+		assignments = append(assignments, name+" = "+placeholder)
+		i++
+	}
+	return "SET " + strings.Join(assignments, ", "), names, values
+}
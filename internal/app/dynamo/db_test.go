@@ -0,0 +1,134 @@
+package dynamo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/private/protocol/json/jsonutil"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// fakeResponse answers a DynamoDB operation with its status code and JSON body
+type fakeResponse func(input map[string]interface{}) (int, interface{})
+
+// fakeDynamo serves the DynamoDB JSON API out of responses keyed by operation, e.g. GetItem, and
+// records the operations called. Operations without response fail the test.
+type fakeDynamo struct {
+	t         *testing.T
+	responses map[string]fakeResponse
+	calls     []string
+	inputs    []map[string]interface{}
+	lock      sync.Mutex
+}
+
+func newFakeDb(t *testing.T, responses map[string]fakeResponse) (*DBConfig, *fakeDynamo) {
+	fake := &fakeDynamo{t: t, responses: responses}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	db := InitLocalDb(server.URL, "events")
+	// Errors are part of the tests, do not retry them
+	db.DbService.Config.MaxRetries = new(int)
+	return db, fake
+}
+
+func (f *fakeDynamo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	operation := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.")
+	input := map[string]interface{}{}
+	json.NewDecoder(r.Body).Decode(&input)
+	f.lock.Lock()
+	f.calls = append(f.calls, operation)
+	f.inputs = append(f.inputs, input)
+	respond, ok := f.responses[operation]
+	f.lock.Unlock()
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	if !ok {
+		f.t.Errorf("Unexpected DynamoDB operation %s", operation)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(dynamoError("ValidationException"))
+		return
+	}
+	status, body := respond(input)
+	w.WriteHeader(status)
+	if raw, ok := body.([]byte); ok {
+		w.Write(raw)
+		return
+	}
+	json.NewEncoder(w).Encode(body)
+}
+
+// called returns how many times operation was called
+func (f *fakeDynamo) called(operation string) int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	count := 0
+	for _, call := range f.calls {
+		if call == operation {
+			count++
+		}
+	}
+	return count
+}
+
+func dynamoError(code string) map[string]string {
+	return map[string]string{"__type": "com.amazonaws.dynamodb.v20120810#" + code, "message": code}
+}
+
+// respond always answers with status and body
+func respond(status int, body interface{}) fakeResponse {
+	return func(map[string]interface{}) (int, interface{}) {
+		return status, body
+	}
+}
+
+// output encodes an output of the SDK, e.g. *dynamodb.GetItemOutput, as DynamoDB does
+func output(t *testing.T, value interface{}) []byte {
+	raw, err := jsonutil.BuildJSON(value)
+	if err != nil {
+		t.Fatalf("Unable to encode %v with error %s", value, err)
+	}
+	return raw
+}
+
+// marshal returns the attributes of value as stored by the services
+func marshal(t *testing.T, value interface{}) map[string]*dynamodb.AttributeValue {
+	item, err := dynamodbattribute.MarshalMap(value)
+	if err != nil {
+		t.Fatalf("Unable to marshal %v with error %s", value, err)
+	}
+	return item
+}
+
+// attributes decodes an item recorded in the inputs of the fake
+func attributes(t *testing.T, recorded map[string]interface{}) map[string]*dynamodb.AttributeValue {
+	raw, err := json.Marshal(recorded)
+	if err != nil {
+		t.Fatalf("Unable to encode %v with error %s", recorded, err)
+	}
+	item := map[string]*dynamodb.AttributeValue{}
+	if err = json.Unmarshal(raw, &item); err != nil {
+		t.Fatalf("Unable to decode %s with error %s", raw, err)
+	}
+	return item
+}
+
+// item returns a GetItem response with value
+func item(t *testing.T, value interface{}) []byte {
+	return output(t, &dynamodb.GetItemOutput{Item: marshal(t, value)})
+}
+
+// getItems answers GetItem with the value stored under the requested sort key, no item if there is none
+func getItems(t *testing.T, values map[string]interface{}) fakeResponse {
+	return func(input map[string]interface{}) (int, interface{}) {
+		key := input["Key"].(map[string]interface{})[C_SORT_KEY].(map[string]interface{})["S"].(string)
+		value, ok := values[key]
+		if !ok {
+			return http.StatusOK, map[string]interface{}{}
+		}
+		return http.StatusOK, item(t, value)
+	}
+}
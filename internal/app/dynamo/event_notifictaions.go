@@ -1,9 +1,11 @@
 package dynamo
 
 import (
+	"errors"
 	"log"
 	"time"
 
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/craguilar/event-management-service/internal/app"
 )
 
@@ -12,10 +14,12 @@ type EventActions struct {
 	db                  *DBConfig
 	eventService        *EventService
 	taskService         *TaskService
-	notificationService *app.EmailNotificationService
+	expenseService      *ExpenseService
+	preferencesService  app.PreferencesService
+	notificationService app.Notifier
 }
 
-func NewEventActionsService(db *DBConfig, event *EventService, task *TaskService, notification *app.EmailNotificationService) *EventActions {
+func NewEventActionsService(db *DBConfig, event *EventService, task *TaskService, expense *ExpenseService, preferences app.PreferencesService, notification app.Notifier) *EventActions {
 	if db == nil {
 		log.Panicf("Null reference to db config in EventService")
 	}
@@ -23,52 +27,192 @@ func NewEventActionsService(db *DBConfig, event *EventService, task *TaskService
 		db:                  db,
 		eventService:        event,
 		taskService:         task,
+		expenseService:      expense,
+		preferencesService:  preferences,
 		notificationService: notification,
 	}
 }
 
-func (c *EventActions) SendPendingTasksNotifications() error {
+// How far ahead recurring task occurrences are listed in notifications
+const _UPCOMING_OCCURRENCES_WINDOW = 30 * 24 * time.Hour
+
+// Scheduled payments due within a week are included in notifications
+const _DUE_PAYMENTS_WINDOW = 7 * 24 * time.Hour
+
+func (c *EventActions) SendPendingTasksNotifications() (*app.NotificationReport, error) {
+	report := app.NewNotificationReport(time.Now())
 	events, err := c.eventService.ListBy(func(event *app.EventSummary) bool {
-		return event.EventDay.After(time.Now()) && event.NotificationEnabled
+		return event.IsActive(time.Now())
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	for _, event := range events {
-
-		// Get the tasks
-		tasks, err := c.taskService.List(event.Id)
+		eventReport, err := c.notifyPendingTasks(event, time.Now())
 		if err != nil {
-			return err
+			// Keep going, one event must not prevent notifying the rest
+			log.Printf("WARN: Failed to notify pending tasks of %s with error %s", event.Id, err)
+			eventReport = &app.EventNotificationReport{EventId: event.Id, EventName: event.Name, Reason: err.Error(), Errors: []string{}}
 		}
-		// Filter by pending tasks
-		pending := make([]*app.Task, 0)
-		for _, task := range tasks {
-			if task.Status == "PENDING" {
-				pending = append(pending, task)
-			}
+		report.Add(eventReport)
+	}
+	report.TimeFinishedOn = time.Now()
+	log.Printf("Pending tasks notifications of %d events sent %d, failed %d, suppressed %d and skipped %d events", len(report.Events), report.Sent, report.Failed, report.Suppressed, report.Skipped)
+	return report, nil
+}
+
+// SendDueNotifications notifies the events whose reminder is due at now, each event is marked as notified
+// before it is so the concurrent or next runs skip it. Retries of the notifications are left to the
+// outbox, an event whose notification could not be prepared waits for its next reminder. Errors of an
+// event are reported and do not stop the run.
+func (c *EventActions) SendDueNotifications(now time.Time) (*app.NotificationReport, error) {
+	report := app.NewNotificationReport(now)
+	events, err := c.eventService.scanEvents(func(event *app.Event) bool {
+		summary := event.ToSummary()
+		return event.NotificationEnabled && summary.IsActive(now) && event.ReminderSchedule().IsDue(summary, event.TimeNotifiedOn, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		summary := event.ToSummary().WithState(now)
+		err = c.eventService.markNotified(event, now)
+		var conditional *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &conditional) {
+			report.Skip(summary, "already notified")
+			continue
 		}
-		if len(pending) == 0 || pending == nil {
+		if err != nil {
+			log.Printf("WARN: Failed to mark %s as notified with error %s", event.Id, err)
+			report.Add(&app.EventNotificationReport{EventId: event.Id, EventName: event.Name, Reason: err.Error(), Errors: []string{}})
 			continue
 		}
-		template := app.TemplatePendingTasksNotifications(event.Name, pending)
-		// Get owners
-		owners, err := c.eventService.ListOwners(event.Id)
+		eventReport, err := c.notifyPendingTasks(summary, now)
 		if err != nil {
-			return err
+			log.Printf("WARN: Failed to notify pending tasks of %s with error %s", event.Id, err)
+			eventReport = &app.EventNotificationReport{EventId: event.Id, EventName: event.Name, Reason: err.Error(), Errors: []string{}}
 		}
-		log.Printf("Sending notification for %s to %d recipients with %d tasks", event.Name, len(owners.SharedEmails), len(pending))
-		for _, toEmail := range owners.SharedEmails {
-			err = c.notificationService.SendEmailNotification(toEmail, "Pending Tasks for "+event.Name, template.String())
-			if err != nil {
-				log.Printf("WARN: Failed to send notification for %s with error %s", toEmail, err)
+		report.Add(eventReport)
+	}
+	report.TimeFinishedOn = time.Now()
+	log.Printf("Due notifications of %d events sent %d, failed %d, suppressed %d and skipped %d events", len(report.Events), report.Sent, report.Failed, report.Suppressed, report.Skipped)
+	return report, nil
+}
+
+// notifyPendingTasks sends the pending tasks of event at now to every owner, the recipients that failed
+// are reported and only errors preparing the notification are returned.
+func (c *EventActions) notifyPendingTasks(event *app.EventSummary, now time.Time) (*app.EventNotificationReport, error) {
+	// Get the tasks
+	tasks, err := c.taskService.List(event.Id)
+	if err != nil {
+		return nil, err
+	}
+	// Recurring tasks get their next occurrence even if notifications are disabled
+	generated, err := c.generateNextOccurrences(event, tasks)
+	if err != nil {
+		return nil, err
+	}
+	tasks = append(tasks, generated...)
+	report := &app.EventNotificationReport{EventId: event.Id, EventName: event.Name, Errors: []string{}}
+	if !event.NotificationEnabled {
+		report.Skipped, report.Reason = true, "notifications disabled"
+		return report, nil
+	}
+	// Filter by pending tasks
+	pending := make([]*app.Task, 0)
+	for _, task := range tasks {
+		if task.Status == "PENDING" {
+			// Only for display , relative due dates are not persisted
+			if due, ok := task.ResolveDueDate(event.EventDay); ok {
+				task.DueDate = due
 			}
+			pending = append(pending, task)
 		}
-
 	}
-	return nil
+	payments, err := c.expenseService.UpcomingPayments(event.Id, now.Add(_DUE_PAYMENTS_WINDOW))
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) == 0 && len(payments) == 0 {
+		report.Skipped, report.Reason = true, "nothing pending"
+		return report, nil
+	}
+	upcoming := app.UpcomingOccurrences(pending, event.EventDay, now.Add(_UPCOMING_OCCURRENCES_WINDOW))
+	template, err := app.TemplatePendingTasksNotifications(event, pending, upcoming, payments)
+	if err != nil {
+		return nil, err
+	}
+	// Get owners
+	owners, err := c.eventService.ListOwners(event.Id)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Sending notification for %s to %d recipients with %d tasks and %d payments", event.Name, len(owners.SharedEmails), len(pending), len(payments))
+	for _, toEmail := range owners.SharedEmails {
+		preferences, err := c.preferencesService.Get(toEmail)
+		if err != nil {
+			log.Printf("WARN: Failed to get the preferences of %s with error %s", toEmail, err)
+			report.Failed++
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+		if !preferences.Allows(app.NotificationPendingTasks, event.Id) {
+			report.Suppressed++
+			continue
+		}
+		// Sent once per digest period of the recipient, retries of the scheduled run get the same key
+		err = c.notificationService.Notify(&app.Notification{
+			Key:       app.NotificationKey(app.NotificationPendingTasks, event.Id, toEmail, preferences.DigestPeriod(now)),
+			Type:      app.NotificationPendingTasks,
+			EventId:   event.Id,
+			Recipient: toEmail,
+			Subject:   "Pending Tasks for " + event.Name,
+			Body:      template.String(),
+		})
+		if errors.Is(err, app.ErrNotificationSuppressed) {
+			report.Suppressed++
+			continue
+		}
+		if err != nil {
+			log.Printf("WARN: Failed to send notification for %s with error %s", toEmail, err)
+			report.Failed++
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+		report.Sent++
+	}
+	return report, nil
 }
 
-func TemplatePendingTasksNotifications(s string, tasks []*app.Task) {
-	panic("unimplemented")
+// generateNextOccurrences creates the next occurrence of every DONE recurring task that does not have
+// one yet and returns the created tasks.
+func (c *EventActions) generateNextOccurrences(event *app.EventSummary, tasks []*app.Task) ([]*app.Task, error) {
+	generated := []*app.Task{}
+	for _, task := range tasks {
+		if task.Status != "DONE" || task.Recurrence == "" || task.NextOccurrenceId != "" {
+			continue
+		}
+		next, err := task.NextOccurrence(event.EventDay)
+		if err != nil {
+			log.Printf("WARN: Invalid recurrence for task %s - %s", task.Id, err)
+			continue
+		}
+		if next == nil {
+			continue
+		}
+		if next.Id, err = app.GenerateRandomId(); err != nil {
+			return nil, err
+		}
+		// The completed task is marked in the same transaction so the occurrence is generated only once
+		created, err := c.taskService.createOccurrence(event.Id, task, next)
+		if err != nil {
+			return nil, err
+		}
+		if !created {
+			continue
+		}
+		log.Printf("Generated occurrence %d of task %s for event %s", next.Occurrence, next.RecurrenceId, event.Id)
+		generated = append(generated, next)
+	}
+	return generated, nil
 }
@@ -0,0 +1,143 @@
+package dynamo
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/craguilar/event-management-service/internal/app"
+	"github.com/craguilar/event-management-service/internal/app/mock"
+)
+
+func TestSendDueNotificationsReportsMarkErrors(t *testing.T) {
+	now := time.Now().UTC()
+	nine := time.Date(now.Year(), now.Month(), now.Day(), 9, 1, 0, 0, time.UTC)
+	items := []map[string]*dynamodb.AttributeValue{}
+	for _, id := range []string{"1", "2"} {
+		event := marshal(t, &app.Event{
+			Name:                 "Wedding " + id,
+			MainLocation:         "Puebla",
+			EventDay:             now.AddDate(0, 2, 0),
+			Status:               app.EventPlanning,
+			NotificationEnabled:  true,
+			NotificationSchedule: &app.NotificationSchedule{Frequency: app.ReminderDaily, Hour: 9},
+		})
+		event[C_PK_ID] = &dynamodb.AttributeValue{S: aws.String(id)}
+		items = append(items, event)
+	}
+	db, fake := newFakeDb(t, map[string]fakeResponse{
+		"Scan":       respond(http.StatusOK, output(t, &dynamodb.ScanOutput{Items: items})),
+		"UpdateItem": respond(http.StatusBadRequest, dynamoError("ValidationException")),
+	})
+	notifier := app.NewMemoryNotifier()
+	events := NewEventService(db, NewAuthorizationService(db), nil, nil)
+	actions := NewEventActionsService(db, events, NewTaskService(db, nil), nil, nil, notifier)
+	report, err := actions.SendDueNotifications(nine)
+	if err != nil {
+		t.Fatalf("Expected the run to go on got %s", err)
+	}
+	// Every event is tried and reported
+	if fake.called("UpdateItem") != 2 || len(report.Events) != 2 {
+		t.Fatalf("Expected both events reported got %d", len(report.Events))
+	}
+	for _, event := range report.Events {
+		if event.Reason == "" || event.Skipped {
+			t.Errorf("Expected the error of %s reported got %v", event.EventId, event)
+		}
+	}
+	if len(notifier.Notifications()) != 0 {
+		t.Error("Expected no notifications for events not marked")
+	}
+}
+
+func TestGenerateNextOccurrencesOnce(t *testing.T) {
+	done := &app.Task{Id: _SORT_KEY_TASK_PREFIX + "1", Name: "Call the florist", Status: "DONE", DueDate: time.Now(), Recurrence: "FREQ=WEEKLY"}
+	for _, test := range []struct {
+		name     string
+		response fakeResponse
+		expected int
+	}{
+		{"created", respond(http.StatusOK, map[string]interface{}{}), 1},
+		{"created by another run", respond(http.StatusBadRequest, map[string]interface{}{
+			"__type":              "com.amazonaws.dynamodb.v20120810#TransactionCanceledException",
+			"message":             "Transaction cancelled",
+			"CancellationReasons": []map[string]string{{"Code": "None"}, {"Code": "ConditionalCheckFailed"}},
+		}), 0},
+	} {
+		db, fake := newFakeDb(t, map[string]fakeResponse{
+			"GetItem":            respond(http.StatusOK, item(t, map[string]string{_ATTRIBUTE_STATUS: app.EventPlanning})),
+			"TransactWriteItems": test.response,
+		})
+		actions := NewEventActionsService(db, NewEventService(db, NewAuthorizationService(db), nil, nil), NewTaskService(db, nil), nil, nil, app.NewMemoryNotifier())
+		task := *done
+		generated, err := actions.generateNextOccurrences(&app.EventSummary{Id: "1", EventDay: time.Now().AddDate(0, 2, 0)}, []*app.Task{&task})
+		if err != nil {
+			t.Fatalf("%s failed with error %s", test.name, err)
+		}
+		if len(generated) != test.expected {
+			t.Errorf("Expected %d occurrences %s got %d", test.expected, test.name, len(generated))
+		}
+		// The occurrence and the link from the DONE task are a single write
+		if fake.called("TransactWriteItems") != 1 || fake.called("PutItem") != 0 || fake.called("UpdateItem") != 0 {
+			t.Errorf("Expected a single transaction %s got %v", test.name, fake.calls)
+		}
+		if test.expected == 1 && task.NextOccurrenceId != generated[0].Id {
+			t.Errorf("Expected the DONE task linked to %s got %s", generated[0].Id, task.NextOccurrenceId)
+		}
+	}
+}
+
+// failingPreferences fails to get the preferences of user
+type failingPreferences struct {
+	app.PreferencesService
+	user string
+}
+
+func (p *failingPreferences) Get(user string) (*app.NotificationPreferences, error) {
+	if user == p.user {
+		return nil, errors.New("preferences unavailable")
+	}
+	return p.PreferencesService.Get(user)
+}
+
+func TestNotifyPendingTasksGoesPastPreferencesErrors(t *testing.T) {
+	task := marshal(t, &app.Task{Name: "Book DJ", Status: "PENDING", DueDate: time.Now()})
+	task[C_SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(_SORT_KEY_TASK_PREFIX + "1")}
+	owners := []map[string]*dynamodb.AttributeValue{}
+	for _, email := range []string{"ANA@EXAMPLE.COM", "LUIS@EXAMPLE.COM"} {
+		owners = append(owners, map[string]*dynamodb.AttributeValue{C_SORT_KEY: {S: aws.String(_SORT_KEY_OWNER_PREFIX + email)}})
+	}
+	db, _ := newFakeDb(t, map[string]fakeResponse{
+		"GetItem": getItems(t, map[string]interface{}{
+			_SORT_KEY_EVENT_PREFIX + "1": &app.Event{Id: "1", Name: "Wedding", Status: app.EventPlanning, EventDay: time.Now().AddDate(0, 2, 0)},
+		}),
+		"Query": func(input map[string]interface{}) (int, interface{}) {
+			condition := input["KeyConditions"].(map[string]interface{})[C_SORT_KEY].(map[string]interface{})
+			switch condition["AttributeValueList"].([]interface{})[0].(map[string]interface{})["S"] {
+			case _SORT_KEY_TASK_PREFIX:
+				return http.StatusOK, output(t, &dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{task}})
+			case _SORT_KEY_OWNER_PREFIX:
+				return http.StatusOK, output(t, &dynamodb.QueryOutput{Items: owners})
+			}
+			return http.StatusOK, output(t, &dynamodb.QueryOutput{})
+		},
+	})
+	notifier := app.NewMemoryNotifier()
+	events := NewEventService(db, NewAuthorizationService(db), nil, nil)
+	expenses := NewExpenseService(db, nil, events, nil, nil, nil, nil)
+	preferences := &failingPreferences{PreferencesService: mock.NewPreferencesService(nil), user: "ANA@EXAMPLE.COM"}
+	actions := NewEventActionsService(db, events, NewTaskService(db, nil), expenses, preferences, notifier)
+	report, err := actions.notifyPendingTasks(&app.EventSummary{Id: "1", Name: "Wedding", EventDay: time.Now().AddDate(0, 2, 0), NotificationEnabled: true}, time.Now())
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if report.Failed != 1 || len(report.Errors) != 1 || report.Sent != 1 {
+		t.Errorf("Expected the failed recipient reported and the rest notified got %+v", report)
+	}
+	if notifications := notifier.Notifications(); len(notifications) != 1 || notifications[0].Recipient != "LUIS@EXAMPLE.COM" {
+		t.Errorf("Expected the other owner notified got %v", notifications)
+	}
+}
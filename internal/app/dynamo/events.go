@@ -16,19 +16,27 @@ import (
 const _SORT_KEY_EVENT_PREFIX = "EVENT-"
 const _SORT_KEY_OWNER_PREFIX = "OWNER-"
 
+// Attribute of the event Status, items are stored under their json names
+const _ATTRIBUTE_STATUS = "status"
+
 // EventService represents a Dynamo DB implementation of internal.EventService.
 type EventService struct {
 	db        *DBConfig
 	authorize *AuthorizationService
+	audit     app.AuditService
+	// Content of the attachments, deleted with the event
+	storage app.BlobStorage
 }
 
-func NewEventService(db *DBConfig, authorize *AuthorizationService) *EventService {
+func NewEventService(db *DBConfig, authorize *AuthorizationService, audit app.AuditService, storage app.BlobStorage) *EventService {
 	if db == nil {
 		log.Panicf("Null reference to db config in EventService")
 	}
 	return &EventService{
 		db:        db,
 		authorize: authorize,
+		audit:     audit,
+		storage:   storage,
 	}
 }
 
@@ -61,7 +69,7 @@ func (c *EventService) Get(id string) (*app.Event, error) {
 		return nil, nil
 	}
 	log.Printf("Return %v", event)
-	return event, nil
+	return event.WithState(time.Now()), nil
 }
 
 func (c *EventService) List(userName string) ([]*app.EventSummary, error) {
@@ -92,40 +100,70 @@ func (c *EventService) List(userName string) ([]*app.EventSummary, error) {
 	}
 	list := []*app.EventSummary{}
 	for _, value := range *items {
-		list = append(list, &app.EventSummary{Id: value.EventSummary.Id,
+		list = append(list, (&app.EventSummary{Id: value.EventSummary.Id,
 			Name:                value.EventSummary.Name,
 			MainLocation:        value.EventSummary.MainLocation,
 			EventDay:            value.EventSummary.EventDay,
 			TimeCreatedOn:       value.EventSummary.TimeCreatedOn,
-			NotificationEnabled: value.EventSummary.NotificationEnabled})
+			NotificationEnabled: value.EventSummary.NotificationEnabled,
+			TimeZone:            value.EventSummary.TimeZone,
+			AllDay:              value.EventSummary.AllDay,
+			Status:              value.EventSummary.Status}).WithState(time.Now()))
 	}
 	return list, nil
 }
 
+// ListBy scans only the EVENT- items leaving out the archived events, filter gets every other event.
 func (c *EventService) ListBy(filter func(*app.EventSummary) bool) ([]*app.EventSummary, error) {
-
-	var scanInput = &dynamodb.ScanInput{
-		TableName: aws.String(c.db.TableName),
-	}
-
-	result, err := c.db.DbService.Scan(scanInput)
+	events, err := c.scanEvents(func(event *app.Event) bool {
+		return filter(event.ToSummary().WithState(time.Now()))
+	})
 	if err != nil {
 		return nil, err
 	}
 	list := []*app.EventSummary{}
-	for _, value := range result.Items {
-		event := &app.Event{}
-		err = dynamodbattribute.UnmarshalMap(value, event)
+	for _, event := range events {
+		list = append(list, event.ToSummary().WithState(time.Now()))
+	}
+	return list, nil
+}
+
+// scanEvents is ListBy returning the whole events.
+func (c *EventService) scanEvents(filter func(*app.Event) bool) ([]*app.Event, error) {
+	var scanInput = &dynamodb.ScanInput{
+		TableName:        aws.String(c.db.TableName),
+		FilterExpression: aws.String("begins_with(#sortKey, :event) AND (attribute_not_exists(#status) OR #status <> :archived)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#sortKey": aws.String(c.db.SORT_KEY),
+			"#status":  aws.String(_ATTRIBUTE_STATUS),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":event":    {S: aws.String(_SORT_KEY_EVENT_PREFIX)},
+			":archived": {S: aws.String(app.EventArchived)},
+		},
+	}
+	list := []*app.Event{}
+	for {
+		result, err := c.db.DbService.Scan(scanInput)
 		if err != nil {
 			return nil, err
 		}
-		event.Id = *aws.String(*value[c.db.PK_ID].S)
-		summary := event.ToSummary()
-		if filter(summary) {
-			list = append(list, summary)
+		for _, value := range result.Items {
+			event := &app.Event{}
+			err = dynamodbattribute.UnmarshalMap(value, event)
+			if err != nil {
+				return nil, err
+			}
+			event.Id = *aws.String(*value[c.db.PK_ID].S)
+			if filter(event) {
+				list = append(list, event)
+			}
+		}
+		if len(result.LastEvaluatedKey) == 0 {
+			return list, nil
 		}
+		scanInput.ExclusiveStartKey = result.LastEvaluatedKey
 	}
-	return list, nil
 }
 
 // Added AuthZ to prevent the situation where, someone hacks its way and sends the
@@ -155,8 +193,14 @@ func (c *EventService) CreateOrUpdate(eventManager string, u *app.Event) (*app.E
 	if err != nil {
 		return nil, err
 	}
+	if err = u.Transition(value, time.Now()); err != nil {
+		return nil, err
+	}
 	if value == nil {
 		u.TimeCreatedOn = time.Now()
+	} else {
+		// Only set by the scheduled run, see markNotified
+		u.TimeNotifiedOn = value.TimeNotifiedOn
 	}
 	// If it exists update the time stamp!
 	u.TimeUpdatedOn = time.Now()
@@ -168,37 +212,47 @@ func (c *EventService) CreateOrUpdate(eventManager string, u *app.Event) (*app.E
 	aEvent[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(u.Id)}
 	aEvent[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(_SORT_KEY_EVENT_PREFIX + u.Id)}
 
-	aOwner, err := dynamodbattribute.MarshalMap(eventOwner(eventManager, u))
-	if err != nil {
-		return nil, err
+	// Every owner keeps a copy of the summary, all of them are updated so the status is listed the same
+	owners := []string{eventManager}
+	if value != nil {
+		shared, err := c.ListOwners(u.Id)
+		if err != nil {
+			return nil, err
+		}
+		for _, email := range shared.SharedEmails {
+			if email != eventManager {
+				owners = append(owners, email)
+			}
+		}
 	}
-	aOwner[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(u.Id)}
-	aOwner[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(_SORT_KEY_OWNER_PREFIX + eventManager)}
-
-	transactions := &dynamodb.TransactWriteItemsInput{
-		TransactItems: []*dynamodb.TransactWriteItem{
-			{
-				Put: &dynamodb.Put{
-					Item:      aEvent,
-					TableName: &c.db.TableName,
-				},
-			},
-			{
-				Put: &dynamodb.Put{
-					Item:      aOwner,
-					TableName: &c.db.TableName,
-				},
+	transactions := []*dynamodb.TransactWriteItem{
+		{
+			Put: &dynamodb.Put{
+				Item:      aEvent,
+				TableName: &c.db.TableName,
 			},
 		},
 	}
-	output, err := c.db.DbService.TransactWriteItems(transactions)
-
+	for _, email := range owners {
+		aOwner, err := dynamodbattribute.MarshalMap(eventOwner(email, u))
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, &dynamodb.TransactWriteItem{
+			Put: &dynamodb.Put{
+				Item:      c.withKey(aOwner, u.Id, _SORT_KEY_OWNER_PREFIX+email),
+				TableName: &c.db.TableName,
+			},
+		})
+	}
+	err = transactWrite(c.db, transactions)
 	if err != nil {
 		return nil, err
 	}
+	recordAudit(c.audit, eventManager, u.Id, app.AuditEvent, u.Id, value, u)
 
-	log.Printf("Created event with name %s /%s - output %s", u.Name, u.Id, output)
-	return u, nil
+	log.Printf("Created event with name %s /%s", u.Name, u.Id)
+	return u.WithState(time.Now()), nil
 }
 
 func (c *EventService) Delete(eventManager, id string) error {
@@ -206,29 +260,23 @@ func (c *EventService) Delete(eventManager, id string) error {
 	if !c.authorize.Authorize(eventManager, id) {
 		return errors.New("unauthorized")
 	}
-	// Get ALL associated elements
-	var queryInput = &dynamodb.QueryInput{
-		TableName: aws.String(c.db.TableName),
-		KeyConditions: map[string]*dynamodb.Condition{
-			c.db.PK_ID: {
-				ComparisonOperator: aws.String("EQ"),
-				AttributeValueList: []*dynamodb.AttributeValue{
-					{
-						S: aws.String(id),
-					},
-				},
-			},
-		},
+	before, err := c.Get(id)
+	if err != nil {
+		return err
 	}
-
-	result, err := c.db.DbService.Query(queryInput)
+	// Get ALL associated elements
+	items, err := queryPartition(c.db, id)
 	if err != nil {
 		log.Printf("Error when querying by HASH key - %s", err)
 		return err
 	}
 
 	transactions := []*dynamodb.TransactWriteItem{}
-	for _, value := range result.Items {
+	attachments := []map[string]*dynamodb.AttributeValue{}
+	for _, value := range items {
+		if strings.HasPrefix(*value[c.db.SORT_KEY].S, _SORT_KEY_ATTACHMENT_PREFIX) {
+			attachments = append(attachments, value)
+		}
 		transactions = append(transactions, &dynamodb.TransactWriteItem{
 			Delete: &dynamodb.Delete{
 				Key: map[string]*dynamodb.AttributeValue{
@@ -242,16 +290,32 @@ func (c *EventService) Delete(eventManager, id string) error {
 				TableName: &c.db.TableName,
 			},
 		})
-
+		// The slug of the website is stored in a partition of its own
+		if *value[c.db.SORT_KEY].S == _SORT_KEY_WEBSITE {
+			website := &app.EventWebsite{}
+			if err = dynamodbattribute.UnmarshalMap(value, website); err != nil {
+				return err
+			}
+			transactions = append(transactions, &dynamodb.TransactWriteItem{
+				Delete: &dynamodb.Delete{
+					Key:       c.withKey(map[string]*dynamodb.AttributeValue{}, _PARTITION_WEBSITE_PREFIX+website.Slug, _SORT_KEY_WEBSITE),
+					TableName: &c.db.TableName,
+				},
+			})
+		}
 	}
 
-	// Batch delete
-	transactWriteInput := &dynamodb.TransactWriteItemsInput{TransactItems: transactions}
-	_, err = c.db.DbService.TransactWriteItems(transactWriteInput)
+	// Batch delete, audit entries are part of the partition so it may take more than one transaction
+	err = transactWrite(c.db, transactions)
 	if err != nil {
 		log.Printf("Got error calling Delete event - %s", err)
 		return err
 	}
+	deleteAttachmentBlobs(c.storage, attachments)
+	// The deletion entry is the only one left in the partition and expires through TTL
+	if before != nil {
+		recordAudit(c.audit, eventManager, id, app.AuditEvent, id, before, nil)
+	}
 	return nil
 }
 
@@ -311,6 +375,9 @@ func (c *EventService) CreateOwner(userName string, u *app.EventSharedEmails) (*
 	if err != nil {
 		return nil, err
 	}
+	if event != nil && event.Status == app.EventArchived {
+		return nil, app.ErrEventReadOnly
+	}
 	//
 	transactions := []*dynamodb.TransactWriteItem{}
 	for i := 0; i < len(u.SharedEmails); i++ {
@@ -342,9 +409,289 @@ func (c *EventService) CreateOwner(userName string, u *app.EventSharedEmails) (*
 		log.Printf("Got error calling Delete - %s", err)
 		return nil, err
 	}
+	for _, email := range u.SharedEmails {
+		recordAudit(c.audit, userName, u.EventId, app.AuditOwner, _SORT_KEY_OWNER_PREFIX+email, nil, eventOwner(email, event))
+	}
 	return u, nil
 }
 
+// Clone creates a brand new event out of clone.FromEvent, the new event gets the source event fields,
+// tasks reset to PENDING, expense categories with projections only and the guest list (seating). Owners
+// are copied only when requested, the eventManager is always an owner of the new event.
+func (c *EventService) Clone(eventManager string, clone *app.CloneEventRequest) (*app.Event, error) {
+	eventManager = strings.ToUpper(eventManager)
+	err := clone.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if !c.authorize.Authorize(eventManager, clone.FromEvent) {
+		return nil, errors.New("unauthorized")
+	}
+	source, err := c.Get(clone.FromEvent)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, errors.New("event not found")
+	}
+	items, err := queryPartition(c.db, clone.FromEvent)
+	if err != nil {
+		return nil, err
+	}
+	// New event out of the source one
+	event := *source
+	event.Id, err = app.GenerateRandomId()
+	if err != nil {
+		return nil, err
+	}
+	if clone.Name != "" {
+		event.Name = clone.Name
+	}
+	event.EventDay = clone.EventDay
+	event.Guests = nil
+	event.Status = app.EventPlanning
+	event.WithState(time.Now())
+	event.TimeNotifiedOn = time.Time{}
+	event.TimeCreatedOn = time.Now()
+	event.TimeUpdatedOn = time.Now()
+
+	log.Printf("Clone event %s into %s", clone.FromEvent, event.Id)
+
+	aEvent, err := dynamodbattribute.MarshalMap(&event)
+	if err != nil {
+		return nil, err
+	}
+	aOwner, err := dynamodbattribute.MarshalMap(eventOwner(eventManager, &event))
+	if err != nil {
+		return nil, err
+	}
+	puts := []map[string]*dynamodb.AttributeValue{}
+	for _, value := range items {
+		sortKey := *value[c.db.SORT_KEY].S
+		var item interface{}
+		switch {
+		case strings.HasPrefix(sortKey, _SORT_KEY_OWNER_PREFIX):
+			email := strings.TrimPrefix(sortKey, _SORT_KEY_OWNER_PREFIX)
+			if !clone.IncludeOwners || email == eventManager {
+				continue
+			}
+			item = eventOwner(email, &event)
+		case strings.HasPrefix(sortKey, _SORT_KEY_TASK_PREFIX):
+			task := &app.Task{}
+			if err = dynamodbattribute.UnmarshalMap(value, task); err != nil {
+				return nil, err
+			}
+			task.Status = "PENDING"
+			task.TimeCreatedOn = time.Now()
+			task.TimeUpdatedOn = time.Now()
+			item = task
+		case strings.HasPrefix(sortKey, _SORT_KEY_EXPENSE_CATEGORY_PREFIX):
+			category := &app.ExpenseCategory{}
+			if err = dynamodbattribute.UnmarshalMap(value, category); err != nil {
+				return nil, err
+			}
+			// Expenses are EXPENSE_ITEM- items which are not cloned
+			category.AmountPaid = 0
+			category.TimeCreatedOn = time.Now()
+			category.TimeUpdatedOn = time.Now()
+			item = category
+		case strings.HasPrefix(sortKey, _SORT_KEY_VENDOR_PREFIX):
+			// Keeps the same Id so categories and tasks remain linked
+			vendor := &app.Vendor{}
+			if err = dynamodbattribute.UnmarshalMap(value, vendor); err != nil {
+				return nil, err
+			}
+			vendor.TimeCreatedOn = time.Now()
+			vendor.TimeUpdatedOn = time.Now()
+			item = vendor
+		case strings.HasPrefix(sortKey, _SORT_KEY_SCHEDULE_PREFIX):
+			// Moves with the event day, guests keep their Id so invitations remain valid
+			schedule := &app.ScheduleItem{}
+			if err = dynamodbattribute.UnmarshalMap(value, schedule); err != nil {
+				return nil, err
+			}
+			shift := clone.EventDay.Sub(source.EventDay)
+			schedule.StartTime = schedule.StartTime.Add(shift)
+			if !schedule.EndTime.IsZero() {
+				schedule.EndTime = schedule.EndTime.Add(shift)
+			}
+			schedule.Responses = []*app.ScheduleRsvp{}
+			schedule.TimeCreatedOn = time.Now()
+			schedule.TimeUpdatedOn = time.Now()
+			item = schedule
+		case strings.HasPrefix(sortKey, _SORT_KEY_GUEST_PREFIX):
+			guest := &app.Guest{}
+			if err = dynamodbattribute.UnmarshalMap(value, guest); err != nil {
+				return nil, err
+			}
+			// Like the schedule responses, the answers of the guests belong to the source event
+			guest.ConfirmedSeats = 0
+			guest.NotAttending = false
+			guest.Tentative = false
+			guest.TimeRespondedOn = time.Time{}
+			guest.TimeCreatedOn = time.Now()
+			guest.TimeUpdatedOn = time.Now()
+			item = guest
+		default:
+			continue
+		}
+		aItem, err := dynamodbattribute.MarshalMap(item)
+		if err != nil {
+			return nil, err
+		}
+		puts = append(puts, c.withKey(aItem, event.Id, sortKey))
+	}
+	// Written last so the clone is not listed by its owner until every item is
+	puts = append(puts,
+		c.withKey(aEvent, event.Id, _SORT_KEY_EVENT_PREFIX+event.Id),
+		c.withKey(aOwner, event.Id, _SORT_KEY_OWNER_PREFIX+eventManager),
+	)
+
+	transactions := []*dynamodb.TransactWriteItem{}
+	for _, put := range puts {
+		transactions = append(transactions, &dynamodb.TransactWriteItem{
+			Put: &dynamodb.Put{
+				Item:      put,
+				TableName: &c.db.TableName,
+			},
+		})
+	}
+	// Large events are written in several transactions, the items of a failed clone are deleted
+	if err = transactWrite(c.db, transactions); err != nil {
+		log.Printf("Got error calling Clone event - %s", err)
+		c.deleteItems(event.Id, puts)
+		return nil, err
+	}
+	recordAudit(c.audit, eventManager, event.Id, app.AuditEvent, event.Id, nil, &event)
+	return &event, nil
+}
+
+// deleteItems deletes the items of the event id with the keys of items, errors are only logged.
+func (c *EventService) deleteItems(id string, items []map[string]*dynamodb.AttributeValue) {
+	transactions := []*dynamodb.TransactWriteItem{}
+	for _, item := range items {
+		transactions = append(transactions, &dynamodb.TransactWriteItem{
+			Delete: &dynamodb.Delete{
+				Key:       c.withKey(map[string]*dynamodb.AttributeValue{}, id, *item[c.db.SORT_KEY].S),
+				TableName: &c.db.TableName,
+			},
+		})
+	}
+	if err := transactWrite(c.db, transactions); err != nil {
+		log.Printf("WARN: Unable to delete the items of event %s - %s", id, err)
+	}
+}
+
+// Summary reads the whole partition of the event in a single query instead of listing guests, tasks
+// and expenses separately.
+func (c *EventService) Summary(eventManager, id string) (*app.EventDashboard, error) {
+	if !c.authorize.Authorize(eventManager, id) {
+		return nil, errors.New("unauthorized")
+	}
+	items, err := queryPartition(c.db, id)
+	if err != nil {
+		return nil, err
+	}
+	var event *app.Event
+	guests := []*app.Guest{}
+	tasks := []*app.Task{}
+	categories := []*app.ExpenseCategory{}
+	for _, value := range items {
+		sortKey := *value[c.db.SORT_KEY].S
+		switch {
+		case strings.HasPrefix(sortKey, _SORT_KEY_EVENT_PREFIX):
+			event = &app.Event{}
+			err = dynamodbattribute.UnmarshalMap(value, event)
+		case strings.HasPrefix(sortKey, _SORT_KEY_GUEST_PREFIX):
+			guest := &app.Guest{}
+			err = dynamodbattribute.UnmarshalMap(value, guest)
+			guests = append(guests, guest)
+		case strings.HasPrefix(sortKey, _SORT_KEY_TASK_PREFIX):
+			task := &app.Task{}
+			err = dynamodbattribute.UnmarshalMap(value, task)
+			tasks = append(tasks, task)
+		case strings.HasPrefix(sortKey, _SORT_KEY_EXPENSE_CATEGORY_PREFIX):
+			// AmountPaid of the category already adds up its EXPENSE_ITEM- items
+			category := &app.ExpenseCategory{}
+			err = dynamodbattribute.UnmarshalMap(value, category)
+			categories = append(categories, category)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if event == nil {
+		return nil, nil
+	}
+	return app.NewEventDashboard(event, guests, tasks, categories, time.Now()), nil
+}
+
+// markNotified sets the TimeNotifiedOn of event to now, failing with ConditionalCheckFailedException if
+// it changed since event was read so concurrent runs notify the event once.
+func (c *EventService) markNotified(event *app.Event, now time.Time) error {
+	aNow, err := dynamodbattribute.Marshal(now)
+	if err != nil {
+		return err
+	}
+	aPrevious, err := dynamodbattribute.Marshal(event.TimeNotifiedOn)
+	if err != nil {
+		return err
+	}
+	_, err = c.db.DbService.UpdateItem(&dynamodb.UpdateItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			c.db.PK_ID: {
+				S: aws.String(event.Id),
+			},
+			c.db.SORT_KEY: {
+				S: aws.String(_SORT_KEY_EVENT_PREFIX + event.Id),
+			},
+		},
+		TableName:           &c.db.TableName,
+		UpdateExpression:    aws.String("SET #notified = :now"),
+		ConditionExpression: aws.String("attribute_exists(#id) AND (attribute_not_exists(#notified) OR #notified = :previous)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#id":       aws.String(c.db.PK_ID),
+			"#notified": aws.String("timeNotifiedOn"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":now":      aNow,
+			":previous": aPrevious,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	event.TimeNotifiedOn = now
+	return nil
+}
+
+// withKey assigns the dynamo db keys to an already marshalled item
+func (c *EventService) withKey(item map[string]*dynamodb.AttributeValue, id, sortKey string) map[string]*dynamodb.AttributeValue {
+	item[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(id)}
+	item[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(sortKey)}
+	return item
+}
+
+// writable returns app.ErrEventReadOnly when the event is archived, reading only its Status
+func writable(db *DBConfig, eventId string) error {
+	result, err := db.DbService.GetItem(&dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			db.PK_ID:    {S: aws.String(eventId)},
+			db.SORT_KEY: {S: aws.String(_SORT_KEY_EVENT_PREFIX + eventId)},
+		},
+		ProjectionExpression:     aws.String("#status"),
+		ExpressionAttributeNames: map[string]*string{"#status": aws.String(_ATTRIBUTE_STATUS)},
+		TableName:                &db.TableName,
+	})
+	if err != nil {
+		return err
+	}
+	if status, ok := result.Item[_ATTRIBUTE_STATUS]; ok && status.S != nil && *status.S == app.EventArchived {
+		return app.ErrEventReadOnly
+	}
+	return nil
+}
+
 func eventOwner(userName string, event *app.Event) *app.EventOwner {
 
 	return &app.EventOwner{
@@ -0,0 +1,147 @@
+package dynamo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+func TestArchivedEventRejectsWrites(t *testing.T) {
+	db, fake := newFakeDb(t, map[string]fakeResponse{
+		"GetItem": respond(http.StatusOK, item(t, map[string]string{_ATTRIBUTE_STATUS: app.EventArchived})),
+	})
+	tasks := NewTaskService(db, nil)
+	_, err := tasks.CreateOrUpdate("ana@example.com", "1", &app.Task{Name: "Book DJ", Status: "PENDING", DueDate: time.Now()})
+	if !errors.Is(err, app.ErrEventReadOnly) {
+		t.Errorf("Expected archived events to be read only got %v", err)
+	}
+	if fake.called("PutItem") != 0 {
+		t.Error("Expected nothing written")
+	}
+	names := fake.inputs[0]["ExpressionAttributeNames"].(map[string]interface{})
+	if names["#status"] != _ATTRIBUTE_STATUS {
+		t.Errorf("Expected the status attribute got %v", names)
+	}
+}
+
+func TestListByLeavesOutArchivedEvents(t *testing.T) {
+	db, fake := newFakeDb(t, map[string]fakeResponse{
+		"Scan": respond(http.StatusOK, map[string]interface{}{"Items": []interface{}{}}),
+	})
+	events := NewEventService(db, nil, nil, nil)
+	if _, err := events.ListBy(func(*app.EventSummary) bool { return true }); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	names := fake.inputs[0]["ExpressionAttributeNames"].(map[string]interface{})
+	if names["#status"] != _ATTRIBUTE_STATUS {
+		t.Errorf("Expected the status attribute got %v", names)
+	}
+}
+
+func TestCloneDeletesPartialEvent(t *testing.T) {
+	tasks := []map[string]*dynamodb.AttributeValue{}
+	for i := 0; i < 150; i++ {
+		task := marshal(t, &app.Task{Id: fmt.Sprint(i), Name: "Task", Status: "DONE"})
+		task[C_PK_ID] = &dynamodb.AttributeValue{S: aws.String("1")}
+		task[C_SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(_SORT_KEY_TASK_PREFIX + fmt.Sprint(i))}
+		tasks = append(tasks, task)
+	}
+	writes := 0
+	db, fake := newFakeDb(t, map[string]fakeResponse{
+		"GetItem": getItems(t, map[string]interface{}{
+			_SORT_KEY_OWNER_PREFIX + "ANA@EXAMPLE.COM": &app.EventOwner{OwnerEmail: "ANA@EXAMPLE.COM", EventSummary: &app.EventSummary{Id: "1", Name: "Wedding"}},
+			_SORT_KEY_EVENT_PREFIX + "1":               &app.Event{Id: "1", Name: "Wedding", Status: app.EventPlanning, EventDay: time.Now()},
+		}),
+		"Query": respond(http.StatusOK, output(t, &dynamodb.QueryOutput{Items: tasks})),
+		// The second chunk fails
+		"TransactWriteItems": func(map[string]interface{}) (int, interface{}) {
+			writes++
+			if writes == 2 {
+				return http.StatusBadRequest, dynamoError("ValidationException")
+			}
+			return http.StatusOK, map[string]interface{}{}
+		},
+	})
+	events := NewEventService(db, NewAuthorizationService(db), nil, nil)
+	_, err := events.Clone("ana@example.com", &app.CloneEventRequest{FromEvent: "1", EventDay: time.Now().AddDate(1, 0, 0)})
+	if err == nil {
+		t.Fatal("Expected the clone to fail")
+	}
+	puts, deletes := map[string]bool{}, map[string]bool{}
+	for i, call := range fake.calls {
+		if call != "TransactWriteItems" {
+			continue
+		}
+		for _, write := range fake.inputs[i]["TransactItems"].([]interface{}) {
+			for operation, value := range write.(map[string]interface{}) {
+				key := value.(map[string]interface{})
+				if operation == "Put" {
+					key = key["Item"].(map[string]interface{})
+				} else {
+					key = key["Key"].(map[string]interface{})
+				}
+				sortKey := key[C_SORT_KEY].(map[string]interface{})["S"].(string)
+				if operation == "Put" {
+					puts[sortKey] = true
+				} else {
+					deletes[sortKey] = true
+				}
+			}
+		}
+	}
+	// The owner is written with the last chunk so the partial event is never listed
+	if puts[_SORT_KEY_OWNER_PREFIX+"ANA@EXAMPLE.COM"] != true || writes != 4 {
+		t.Fatalf("Expected both chunks tried and cleaned up got %d writes", writes)
+	}
+	if len(deletes) != len(puts) {
+		t.Errorf("Expected the %d items written deleted got %d", len(puts), len(deletes))
+	}
+}
+
+func TestCloneResetsGuestResponses(t *testing.T) {
+	guest := marshal(t, &app.Guest{Id: "G1", FirstName: "Luis", LastName: "Perez", NumberOfSeats: 2, ConfirmedSeats: 1, NotAttending: true, Tentative: true, TimeRespondedOn: time.Now()})
+	guest[C_PK_ID] = &dynamodb.AttributeValue{S: aws.String("1")}
+	guest[C_SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(_SORT_KEY_GUEST_PREFIX + "G1")}
+	db, fake := newFakeDb(t, map[string]fakeResponse{
+		"GetItem": getItems(t, map[string]interface{}{
+			_SORT_KEY_OWNER_PREFIX + "ANA@EXAMPLE.COM": &app.EventOwner{OwnerEmail: "ANA@EXAMPLE.COM", EventSummary: &app.EventSummary{Id: "1", Name: "Wedding"}},
+			_SORT_KEY_EVENT_PREFIX + "1":               &app.Event{Id: "1", Name: "Wedding", Status: app.EventPlanning, EventDay: time.Now()},
+		}),
+		"Query":              respond(http.StatusOK, output(t, &dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{guest}})),
+		"TransactWriteItems": respond(http.StatusOK, map[string]interface{}{}),
+	})
+	events := NewEventService(db, NewAuthorizationService(db), nil, nil)
+	if _, err := events.Clone("ana@example.com", &app.CloneEventRequest{FromEvent: "1", EventDay: time.Now().AddDate(1, 0, 0)}); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	for i, call := range fake.calls {
+		if call != "TransactWriteItems" {
+			continue
+		}
+		for _, write := range fake.inputs[i]["TransactItems"].([]interface{}) {
+			put := write.(map[string]interface{})["Put"].(map[string]interface{})["Item"].(map[string]interface{})
+			if put[C_SORT_KEY].(map[string]interface{})["S"] != _SORT_KEY_GUEST_PREFIX+"G1" {
+				continue
+			}
+			cloned := &app.Guest{}
+			if err := dynamodbattribute.UnmarshalMap(attributes(t, put), cloned); err != nil {
+				t.Fatalf("Test failed with error %s", err)
+			}
+			if cloned.ConfirmedSeats != 0 || cloned.NotAttending || cloned.Tentative || !cloned.TimeRespondedOn.IsZero() {
+				t.Errorf("Expected the cloned guest without response got %+v", cloned)
+			}
+			if cloned.NumberOfSeats != 2 {
+				t.Errorf("Expected the seats of the guest kept got %d", cloned.NumberOfSeats)
+			}
+			return
+		}
+	}
+	t.Error("Expected the guest cloned")
+}
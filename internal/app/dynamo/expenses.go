@@ -1,6 +1,7 @@
 package dynamo
 
 import (
+	"errors"
 	"log"
 	"strings"
 	"time"
@@ -9,94 +10,104 @@ import (
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/craguilar/event-management-service/internal/app"
+	"golang.org/x/exp/slices"
 )
 
 const _SORT_KEY_EXPENSE_CATEGORY_PREFIX = "EXPENSE_CATEGORY-"
 
+// Individual expenses are stored as EXPENSE_ITEM-<categoryId>-<expenseId> so the expenses of a category
+// are returned by a single query
+const _SORT_KEY_EXPENSE_ITEM_PREFIX = "EXPENSE_ITEM-"
+
+// Common prefix of both categories and items
+const _SORT_KEY_EXPENSES_PREFIX = "EXPENSE_"
+
+// Category attributes only modified through update expressions as expenses are recorded
+var expenseCategoryManagedAttributes = []string{"amountPaid", "timeCreatedOn"}
+
 type ExpenseService struct {
-	db *DBConfig
+	db                  *DBConfig
+	audit               app.AuditService
+	eventService        app.EventService
+	rates               app.ExchangeRateProvider
+	notificationService app.Notifier
+	// Percentages of the budget alerted to the owners, see app.BudgetAlerts
+	budgetThresholds []int
+	// Content of the receipts, deleted with their expenses
+	storage app.BlobStorage
 }
 
-func NewExpenseService(db *DBConfig) *ExpenseService {
+func NewExpenseService(db *DBConfig, audit app.AuditService, event app.EventService, rates app.ExchangeRateProvider, notification app.Notifier, budgetThresholds []int, storage app.BlobStorage) *ExpenseService {
 	return &ExpenseService{
-		db: db,
+		db:                  db,
+		audit:               audit,
+		eventService:        event,
+		rates:               rates,
+		notificationService: notification,
+		budgetThresholds:    budgetThresholds,
+		storage:             storage,
 	}
 }
 
 func (c *ExpenseService) Get(eventId, id string) (*app.ExpenseCategory, error) {
-	input := &dynamodb.GetItemInput{
-		Key: map[string]*dynamodb.AttributeValue{
-			c.db.PK_ID: {
-				S: aws.String(eventId),
-			},
-			c.db.SORT_KEY: {
-				S: aws.String(id),
-			},
-		},
-		TableName: &c.db.TableName,
-	}
-
-	result, err := c.db.DbService.GetItem(input)
-	if err != nil {
+	category, err := c.getCategory(eventId, id)
+	if err != nil || category == nil {
 		return nil, err
-
 	}
-
-	category := &app.ExpenseCategory{}
-	err = dynamodbattribute.UnmarshalMap(result.Item, category)
+	category.Expenses, err = c.ListItems(eventId, id)
 	if err != nil {
 		return nil, err
 	}
-	if category.Category == "" {
-		return nil, nil
-	}
 	log.Printf("Return %v", category)
 	return category, nil
 }
 
+// List returns all the categories with their expenses using a single query.
 func (c *ExpenseService) List(eventId string) ([]*app.ExpenseCategory, error) {
 	log.Printf("Getting all expenses for %s", eventId)
-	var queryInput = &dynamodb.QueryInput{
-		TableName: aws.String(c.db.TableName),
-		KeyConditions: map[string]*dynamodb.Condition{
-			c.db.PK_ID: {
-				ComparisonOperator: aws.String("EQ"),
-				AttributeValueList: []*dynamodb.AttributeValue{
-					{
-						S: aws.String(eventId),
-					},
-				},
-			},
-			c.db.SORT_KEY: {
-				ComparisonOperator: aws.String("BEGINS_WITH"),
-				AttributeValueList: []*dynamodb.AttributeValue{
-					{
-						S: aws.String(_SORT_KEY_EXPENSE_CATEGORY_PREFIX),
-					},
-				},
-			},
-		},
-	}
-
-	result, err := c.db.DbService.Query(queryInput)
+	items, err := c.query(eventId, _SORT_KEY_EXPENSES_PREFIX)
 	if err != nil {
 		return nil, err
 	}
-	// Given we use a single table model until here result.Items contains ALL the same duplicated Id :)
 	list := []*app.ExpenseCategory{}
-	for _, value := range result.Items {
-		expense := &app.ExpenseCategory{}
-		err = dynamodbattribute.UnmarshalMap(value, expense)
+	expenses := map[string][]*app.Expense{}
+	for _, value := range items {
+		sortKey := *value[c.db.SORT_KEY].S
+		if strings.HasPrefix(sortKey, _SORT_KEY_EXPENSE_ITEM_PREFIX) {
+			expense, err := c.unmarshalItem(value)
+			if err != nil {
+				return nil, err
+			}
+			expenses[expense.CategoryId] = append(expenses[expense.CategoryId], expense)
+			continue
+		}
+		if !strings.HasPrefix(sortKey, _SORT_KEY_EXPENSE_CATEGORY_PREFIX) {
+			continue
+		}
+		category := &app.ExpenseCategory{}
+		err = dynamodbattribute.UnmarshalMap(value, category)
 		if err != nil {
 			return nil, err
 		}
-		expense.Id = *aws.String(*value[c.db.SORT_KEY].S)
-		list = append(list, expense)
+		category.Id = sortKey
+		category.ComputeTotals()
+		list = append(list, category)
+	}
+	for _, category := range list {
+		category.Expenses = expenses[category.Id]
+		if category.Expenses == nil {
+			category.Expenses = []*app.Expense{}
+		}
 	}
 	return list, nil
 }
 
-func (c *ExpenseService) CreateOrUpdate(eventId string, u *app.ExpenseCategory) (*app.ExpenseCategory, error) {
+// CreateOrUpdate creates or updates the category fields, AmountPaid is never taken from the request as
+// it is maintained atomically as expenses are recorded, see CreateOrUpdateItem.
+func (c *ExpenseService) CreateOrUpdate(eventManager, eventId string, u *app.ExpenseCategory) (*app.ExpenseCategory, error) {
+	if err := writable(c.db, eventId); err != nil {
+		return nil, err
+	}
 	err := u.Validate()
 	if err != nil {
 		return nil, err
@@ -105,72 +116,661 @@ func (c *ExpenseService) CreateOrUpdate(eventId string, u *app.ExpenseCategory)
 	if u.Id == "" {
 		u.Id = app.GenerateId(strings.ToUpper(u.Category))
 	}
-	amountPaid := 0.0
-	for i := range u.Expenses {
-		amountPaid += u.Expenses[i].AmountPaid
-		if u.Expenses[i].Id == "" {
-			u.Expenses[i].Id, err = app.GenerateRandomId()
-			if err != nil {
+	u.Id = categorySortKey(u.Id)
+	// The sort key of the expenses splits the category id at the first dash
+	if strings.Contains(strings.TrimPrefix(u.Id, _SORT_KEY_EXPENSE_CATEGORY_PREFIX), "-") {
+		return nil, errors.New("expense category id can not contain dashes")
+	}
+	for _, payment := range u.Payments {
+		if payment.Id == "" {
+			if payment.Id, err = app.GenerateRandomId(); err != nil {
 				return nil, err
 			}
 		}
 	}
+	for _, share := range u.Split {
+		share.Email = strings.ToUpper(share.Email)
+		if err = c.checkOwner(eventId, share.Email); err != nil {
+			return nil, err
+		}
+	}
+	base, err := c.baseCurrency(eventId)
+	if err != nil {
+		return nil, err
+	}
 
-	log.Printf("CreateOrUpdate guest with Id /%s", u.Id)
+	log.Printf("CreateOrUpdate expense category with Id /%s", u.Id)
 
-	value, err := c.Get(eventId, u.Id)
+	value, err := c.getCategory(eventId, u.Id)
 	if err != nil {
 		return nil, err
 	}
-	if value == nil {
-		u.TimeCreatedOn = time.Now()
+	if value != nil && value.Currency != "" && value.Currency != base {
+		if err = c.rebase(eventId, value, base); err != nil {
+			return nil, err
+		}
 	}
-	// If it exists update the time stamp!
+	u.Currency = base
 	u.TimeUpdatedOn = time.Now()
-	u.AmountPaid = amountPaid
-	aExpense, err := dynamodbattribute.MarshalMap(u)
+	aCategory, err := dynamodbattribute.MarshalMap(u)
 	if err != nil {
 		return nil, err
 	}
-	// Assign dynamo db key
-	aExpense[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(eventId)}
-	if value == nil {
+	delete(aCategory, c.db.PK_ID)
+	delete(aCategory, c.db.SORT_KEY)
+	for _, attribute := range expenseCategoryManagedAttributes {
+		delete(aCategory, attribute)
+	}
+	expression, names, values := setExpression(aCategory)
+	expression += ", #paid = if_not_exists(#paid, :zero), #created = if_not_exists(#created, :now)"
+	names["#paid"] = aws.String("amountPaid")
+	names["#created"] = aws.String("timeCreatedOn")
+	values[":zero"] = &dynamodb.AttributeValue{N: aws.String("0")}
+	values[":now"], err = dynamodbattribute.Marshal(u.TimeUpdatedOn)
+	if err != nil {
+		return nil, err
+	}
+	input := &dynamodb.UpdateItemInput{
+		Key:                       c.key(eventId, u.Id),
+		UpdateExpression:          aws.String(expression),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ReturnValues:              aws.String("ALL_NEW"),
+		TableName:                 &c.db.TableName,
+	}
+	output, err := c.db.DbService.UpdateItem(input)
+	if err != nil {
+		return nil, err
+	}
+	updated := &app.ExpenseCategory{}
+	if err = dynamodbattribute.UnmarshalMap(output.Attributes, updated); err != nil {
+		return nil, err
+	}
+	updated.Id = u.Id
+	updated.ComputeTotals()
+	updated.Expenses = []*app.Expense{}
+	if value != nil {
+		updated.Expenses = value.Expenses
+	}
+	recordAudit(c.audit, eventManager, eventId, app.AuditExpenseCategory, u.Id, value, updated)
+	c.notifyBudgetAlerts(eventId, u.Id, value)
+	log.Printf("Creatde an expense with Id %s", u.Id)
+	return updated, nil
+}
+
+// Delete removes the category and all its expenses.
+func (c *ExpenseService) Delete(eventManager, eventId, id string) error {
+	if err := writable(c.db, eventId); err != nil {
+		return err
+	}
+	id = categorySortKey(id)
+	value, err := c.getCategory(eventId, id)
+	if err != nil {
+		return err
+	}
+	items, err := c.query(eventId, itemSortKey(id, ""))
+	if err != nil {
+		return err
+	}
+	transactions := []*dynamodb.TransactWriteItem{
+		{Delete: &dynamodb.Delete{Key: c.key(eventId, id), TableName: &c.db.TableName}},
+	}
+	attachments := []map[string]*dynamodb.AttributeValue{}
+	for _, item := range items {
+		transactions = append(transactions, &dynamodb.TransactWriteItem{
+			Delete: &dynamodb.Delete{Key: c.key(eventId, *item[c.db.SORT_KEY].S), TableName: &c.db.TableName},
+		})
+		expense, err := c.unmarshalItem(item)
+		if err != nil {
+			return err
+		}
+		receipts, err := entityAttachments(c.db, eventId, app.AttachOnExpense, expense.Id)
+		if err != nil {
+			return err
+		}
+		attachments = append(attachments, receipts...)
+	}
+	err = transactWrite(c.db, append(transactions, attachmentDeletes(c.db, eventId, attachments)...))
+	if err != nil {
+		log.Printf("Got error calling DeetItem:")
+		return err
+	}
+	deleteAttachmentBlobs(c.storage, attachments)
+	if value != nil {
+		recordAudit(c.audit, eventManager, eventId, app.AuditExpenseCategory, id, value, nil)
+	}
+	return nil
+}
+
+// Individual expenses
+
+func (c *ExpenseService) GetItem(eventId, categoryId, id string) (*app.Expense, error) {
+	input := &dynamodb.GetItemInput{
+		Key:       c.key(eventId, itemSortKey(categoryId, id)),
+		TableName: &c.db.TableName,
+	}
+	result, err := c.db.DbService.GetItem(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Item) == 0 {
+		return nil, nil
+	}
+	return c.unmarshalItem(result.Item)
+}
+
+func (c *ExpenseService) ListItems(eventId, categoryId string) ([]*app.Expense, error) {
+	items, err := c.query(eventId, itemSortKey(categoryId, ""))
+	if err != nil {
+		return nil, err
+	}
+	list := []*app.Expense{}
+	for _, value := range items {
+		expense, err := c.unmarshalItem(value)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, expense)
+	}
+	return list, nil
+}
+
+// CreateOrUpdateItem records an expense and in the same transaction adds the difference of its amount
+// in base currency to the category AmountPaid. The exchange rate is captured once, when the expense
+// is created or its currency changes.
+func (c *ExpenseService) CreateOrUpdateItem(eventManager, eventId, categoryId string, u *app.Expense) (*app.Expense, error) {
+	if err := writable(c.db, eventId); err != nil {
+		return nil, err
+	}
+	err := u.Validate()
+	if err != nil {
+		return nil, err
+	}
+	categoryId = categorySortKey(categoryId)
+	category, err := c.getCategory(eventId, categoryId)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, errors.New("expense category not found")
+	}
+	if u.PaidBy != "" {
+		u.PaidBy = strings.ToUpper(u.PaidBy)
+		if err = c.checkOwner(eventId, u.PaidBy); err != nil {
+			return nil, err
+		}
+	}
+	base, err := c.baseCurrency(eventId)
+	if err != nil {
+		return nil, err
+	}
+	if category.Currency != "" && category.Currency != base {
+		if err = c.rebase(eventId, category, base); err != nil {
+			return nil, err
+		}
+	}
+	var before *app.Expense
+	if u.Id == "" {
+		u.Id, err = app.GenerateRandomId()
+		if err != nil {
+			return nil, err
+		}
 		u.TimeCreatedOn = time.Now()
-		u.Expenses = []*app.Expense{}
-		aExpense[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(_SORT_KEY_EXPENSE_CATEGORY_PREFIX + u.Id)}
 	} else {
-		u.TimeUpdatedOn = time.Now()
-		aExpense[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(u.Id)}
+		before, err = c.GetItem(eventId, categoryId, u.Id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Rates are never taken from the request
+	u.ExchangeRate = 0
+	if before != nil {
+		u.TimeCreatedOn = before.TimeCreatedOn
+		if strings.EqualFold(before.Currency, u.Currency) || u.Currency == "" {
+			u.Currency = before.Currency
+			u.ExchangeRate = before.ExchangeRate
+		}
 	}
-	input := &dynamodb.PutItemInput{
+	if err = u.ApplyExchangeRate(base, c.rates, false); err != nil {
+		return nil, err
+	}
+	u.CategoryId = categoryId
+	u.TimeUpdatedOn = time.Now()
+	delta := u.AmountPaidBase
+	if before != nil {
+		delta -= before.AmountPaidBase
+	}
+
+	aExpense, err := dynamodbattribute.MarshalMap(u)
+	if err != nil {
+		return nil, err
+	}
+	sortKey := itemSortKey(categoryId, u.Id)
+	aExpense[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(eventId)}
+	aExpense[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(sortKey)}
+	put := &dynamodb.Put{
 		Item:      aExpense,
 		TableName: &c.db.TableName,
 	}
-	_, err = c.db.DbService.PutItem(input)
+	// Optimistic locking, a concurrent update of the same expense would compute a wrong delta
+	if before == nil {
+		put.ConditionExpression = aws.String("attribute_not_exists(#sortKey)")
+		put.ExpressionAttributeNames = map[string]*string{"#sortKey": aws.String(c.db.SORT_KEY)}
+	} else {
+		put.ConditionExpression = aws.String("#base = :base")
+		put.ExpressionAttributeNames = map[string]*string{"#base": aws.String("amountPaidBase")}
+		put.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{":base": {N: aws.String(before.AmountPaidBase.String())}}
+	}
+	transactions := []*dynamodb.TransactWriteItem{{Put: put}, c.addToCategory(eventId, categoryId, delta)}
+	_, err = c.db.DbService.TransactWriteItems(&dynamodb.TransactWriteItemsInput{TransactItems: transactions})
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Creatde an expense with Id %s", u.Id)
+	recordAudit(c.audit, eventManager, eventId, app.AuditExpense, sortKey, before, u)
+	c.notifyBudgetAlerts(eventId, categoryId, category)
 	return u, nil
 }
 
-func (c *ExpenseService) Delete(eventId, id string) error {
-	input := &dynamodb.DeleteItemInput{
-		Key: map[string]*dynamodb.AttributeValue{
+func (c *ExpenseService) DeleteItem(eventManager, eventId, categoryId, id string) error {
+	if err := writable(c.db, eventId); err != nil {
+		return err
+	}
+	categoryId = categorySortKey(categoryId)
+	before, err := c.GetItem(eventId, categoryId, id)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return nil
+	}
+	sortKey := itemSortKey(categoryId, id)
+	transactions := []*dynamodb.TransactWriteItem{
+		{
+			Delete: &dynamodb.Delete{
+				Key:                       c.key(eventId, sortKey),
+				ConditionExpression:       aws.String("#base = :base"),
+				ExpressionAttributeNames:  map[string]*string{"#base": aws.String("amountPaidBase")},
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{":base": {N: aws.String(before.AmountPaidBase.String())}},
+				TableName:                 &c.db.TableName,
+			},
+		},
+		c.addToCategory(eventId, categoryId, -before.AmountPaidBase),
+	}
+	attachments, err := entityAttachments(c.db, eventId, app.AttachOnExpense, id)
+	if err != nil {
+		return err
+	}
+	// The expense is deleted in the first transaction, the receipts beyond it if there are many
+	err = transactWrite(c.db, append(transactions, attachmentDeletes(c.db, eventId, attachments)...))
+	if err != nil {
+		log.Printf("Got error calling delete expense %s ", err)
+		return err
+	}
+	deleteAttachmentBlobs(c.storage, attachments)
+	recordAudit(c.audit, eventManager, eventId, app.AuditExpense, sortKey, before, nil)
+	return nil
+}
+
+// Budget returns the event budget against the totals of its expense categories.
+func (c *ExpenseService) Budget(eventId string) (*app.BudgetSummary, error) {
+	event, err := c.eventService.Get(eventId)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, nil
+	}
+	categories, err := c.listCategories(eventId)
+	if err != nil {
+		return nil, err
+	}
+	return app.NewBudgetSummary(event, categories), nil
+}
+
+func (c *ExpenseService) UpcomingPayments(eventId string, until time.Time) ([]*app.UpcomingPayment, error) {
+	event, err := c.eventService.Get(eventId)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, errors.New("event not found")
+	}
+	categories, err := c.listCategories(eventId)
+	if err != nil {
+		return nil, err
+	}
+	return app.UpcomingPayments(categories, event.ToSummary().Today(time.Now()), until), nil
+}
+
+func (c *ExpenseService) Settlement(eventId string) (*app.Settlement, error) {
+	event, err := c.eventService.Get(eventId)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, nil
+	}
+	owners, err := c.eventService.ListOwners(eventId)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := c.List(eventId)
+	if err != nil {
+		return nil, err
+	}
+	return app.NewSettlement(event, owners.SharedEmails, categories), nil
+}
+
+// checkOwner fails if email is not a shared email of the event.
+func (c *ExpenseService) checkOwner(eventId, email string) error {
+	owners, err := c.eventService.ListOwners(eventId)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(owners.SharedEmails, email) {
+		return errors.New(strings.ToLower(email) + " is not a shared email of the event")
+	}
+	return nil
+}
+
+// notifyBudgetAlerts emails the owners the budget thresholds crossed by a mutation of categoryId,
+// before is the category as it was before the mutation or nil if it was created. Failures are only
+// logged.
+func (c *ExpenseService) notifyBudgetAlerts(eventId, categoryId string, before *app.ExpenseCategory) {
+	if c.notificationService == nil || len(c.budgetThresholds) == 0 {
+		return
+	}
+	event, err := c.eventService.Get(eventId)
+	if err != nil || event == nil {
+		log.Printf("WARN: Unable to check budget of event %s", eventId)
+		return
+	}
+	categories, err := c.listCategories(eventId)
+	if err != nil {
+		log.Printf("WARN: Unable to check budget of event %s with error %s", eventId, err)
+		return
+	}
+	previous := []*app.ExpenseCategory{}
+	for _, category := range categories {
+		if category.Id != categoryId {
+			previous = append(previous, category)
+		} else if before != nil {
+			previous = append(previous, before)
+		}
+	}
+	alerts := app.BudgetAlerts(app.NewBudgetSummary(event, previous), app.NewBudgetSummary(event, categories), c.budgetThresholds)
+	if len(alerts) == 0 {
+		return
+	}
+	owners, err := c.eventService.ListOwners(eventId)
+	if err != nil || owners == nil {
+		log.Printf("WARN: Unable to get owners of event %s", eventId)
+		return
+	}
+	template, err := app.TemplateBudgetAlertNotification(event.Name, event.Currency(), alerts)
+	if err != nil {
+		log.Printf("WARN: Unable to template budget alert for event %s with error %s", eventId, err)
+		return
+	}
+	for _, owner := range owners.SharedEmails {
+		err = c.notificationService.Notify(&app.Notification{Type: app.NotificationBudgetAlert, EventId: eventId, Recipient: strings.ToLower(owner), Subject: "Budget alert for " + event.Name, Body: template.String()})
+		if err != nil {
+			log.Printf("WARN: Failed to send budget alert to %s with error %s", owner, err)
+		}
+	}
+}
+
+// listCategories returns the categories without their expenses.
+func (c *ExpenseService) listCategories(eventId string) ([]*app.ExpenseCategory, error) {
+	items, err := c.query(eventId, _SORT_KEY_EXPENSE_CATEGORY_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	list := []*app.ExpenseCategory{}
+	for _, value := range items {
+		category := &app.ExpenseCategory{}
+		if err = dynamodbattribute.UnmarshalMap(value, category); err != nil {
+			return nil, err
+		}
+		category.Id = *value[c.db.SORT_KEY].S
+		category.ComputeTotals()
+		list = append(list, category)
+	}
+	return list, nil
+}
+
+// addToCategory atomically adds delta to the category AmountPaid, failing if the category is gone.
+func (c *ExpenseService) addToCategory(eventId, categoryId string, delta app.Money) *dynamodb.TransactWriteItem {
+	now, _ := dynamodbattribute.Marshal(time.Now())
+	return &dynamodb.TransactWriteItem{
+		Update: &dynamodb.Update{
+			Key:                 c.key(eventId, categoryId),
+			UpdateExpression:    aws.String("ADD #paid :delta SET #updated = :now"),
+			ConditionExpression: aws.String("attribute_exists(#sortKey)"),
+			ExpressionAttributeNames: map[string]*string{
+				"#paid":    aws.String("amountPaid"),
+				"#updated": aws.String("timeUpdatedOn"),
+				"#sortKey": aws.String(c.db.SORT_KEY),
+			},
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":delta": {N: aws.String(delta.String())},
+				":now":   now,
+			},
+			TableName: &c.db.TableName,
+		},
+	}
+}
+
+// rebase captures again the exchange rate of every expense of the category after the event base
+// currency changed. It is NOT atomic across expenses but it is idempotent, a failed rebase is retried
+// by the next write on the category.
+func (c *ExpenseService) rebase(eventId string, category *app.ExpenseCategory, base string) error {
+	log.Printf("Rebase expense category %s from %s to %s", category.Id, category.Currency, base)
+	expenses, err := c.ListItems(eventId, category.Id)
+	if err != nil {
+		return err
+	}
+	category.Expenses = expenses
+	if err = category.ApplyExchangeRates(base, c.rates); err != nil {
+		return err
+	}
+	for _, expense := range expenses {
+		aExpense, err := dynamodbattribute.MarshalMap(expense)
+		if err != nil {
+			return err
+		}
+		aExpense[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(eventId)}
+		aExpense[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(itemSortKey(category.Id, expense.Id))}
+		_, err = c.db.DbService.PutItem(&dynamodb.PutItemInput{Item: aExpense, TableName: &c.db.TableName})
+		if err != nil {
+			return err
+		}
+	}
+	_, err = c.db.DbService.UpdateItem(&dynamodb.UpdateItemInput{
+		Key:                      c.key(eventId, category.Id),
+		UpdateExpression:         aws.String("SET #paid = :paid, #currency = :currency"),
+		ExpressionAttributeNames: map[string]*string{"#paid": aws.String("amountPaid"), "#currency": aws.String("currency")},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":paid":     {N: aws.String(category.AmountPaid.String())},
+			":currency": {S: aws.String(base)},
+		},
+		TableName: &c.db.TableName,
+	})
+	return err
+}
+
+func (c *ExpenseService) baseCurrency(eventId string) (string, error) {
+	event, err := c.eventService.Get(eventId)
+	if err != nil {
+		return "", err
+	}
+	if event == nil {
+		return "", errors.New("event not found")
+	}
+	return event.Currency(), nil
+}
+
+// getCategory returns the category without its expenses.
+func (c *ExpenseService) getCategory(eventId, id string) (*app.ExpenseCategory, error) {
+	id = categorySortKey(id)
+	input := &dynamodb.GetItemInput{
+		Key:       c.key(eventId, id),
+		TableName: &c.db.TableName,
+	}
+	result, err := c.db.DbService.GetItem(input)
+	if err != nil {
+		return nil, err
+	}
+	category := &app.ExpenseCategory{}
+	err = dynamodbattribute.UnmarshalMap(result.Item, category)
+	if err != nil {
+		return nil, err
+	}
+	if category.Category == "" {
+		return nil, nil
+	}
+	category.Id = id
+	category.ComputeTotals()
+	return category, nil
+}
+
+func (c *ExpenseService) query(eventId, prefix string) ([]map[string]*dynamodb.AttributeValue, error) {
+	return queryAll(c.db, &dynamodb.QueryInput{
+		TableName: aws.String(c.db.TableName),
+		KeyConditions: map[string]*dynamodb.Condition{
 			c.db.PK_ID: {
-				S: aws.String(eventId),
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(eventId),
+					},
+				},
 			},
 			c.db.SORT_KEY: {
-				S: aws.String(id),
+				ComparisonOperator: aws.String("BEGINS_WITH"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(prefix),
+					},
+				},
 			},
 		},
-		TableName: &c.db.TableName,
+	})
+}
+
+func (c *ExpenseService) unmarshalItem(value map[string]*dynamodb.AttributeValue) (*app.Expense, error) {
+	expense := &app.Expense{}
+	if err := dynamodbattribute.UnmarshalMap(value, expense); err != nil {
+		return nil, err
+	}
+	// <prefix><categoryKey>-<expenseId>, expense ids are UUIDs so the category key has no dashes
+	sortKey := strings.TrimPrefix(*value[c.db.SORT_KEY].S, _SORT_KEY_EXPENSE_ITEM_PREFIX)
+	separator := strings.Index(sortKey, "-")
+	if separator < 0 {
+		return nil, errors.New("invalid expense sort key " + sortKey)
+	}
+	expense.CategoryId = categorySortKey(sortKey[:separator])
+	expense.Id = sortKey[separator+1:]
+	return expense, nil
+}
+
+func (c *ExpenseService) key(eventId, sortKey string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		c.db.PK_ID: {
+			S: aws.String(eventId),
+		},
+		c.db.SORT_KEY: {
+			S: aws.String(sortKey),
+		},
+	}
+}
+
+// Migrate rewrites every expense category of the table, it is idempotent and returns the number of
+// migrated categories:
+//  1. Amounts stored as float64 before Money existed are rounded to cents on read.
+//  2. Expenses embedded in the category item are moved to individual items.
+func (c *ExpenseService) Migrate() (int, error) {
+	var scanInput = &dynamodb.ScanInput{
+		TableName:        aws.String(c.db.TableName),
+		FilterExpression: aws.String("begins_with(#sortKey, :prefix)"),
+		ExpressionAttributeNames: map[string]*string{
+			"#sortKey": aws.String(c.db.SORT_KEY),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":prefix": {S: aws.String(_SORT_KEY_EXPENSE_CATEGORY_PREFIX)},
+		},
 	}
+	migrated := 0
+	err := c.db.DbService.ScanPages(scanInput, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		for _, value := range page.Items {
+			if err := c.migrateCategory(value); err != nil {
+				log.Printf("WARN: Unable to migrate expense category %s - %s", *value[c.db.SORT_KEY].S, err)
+				continue
+			}
+			migrated++
+		}
+		return true
+	})
+	return migrated, err
+}
 
-	_, err := c.db.DbService.DeleteItem(input)
+func (c *ExpenseService) migrateCategory(value map[string]*dynamodb.AttributeValue) error {
+	eventId, sortKey := *value[c.db.PK_ID].S, *value[c.db.SORT_KEY].S
+	category := &app.ExpenseCategory{}
+	if err := dynamodbattribute.UnmarshalMap(value, category); err != nil {
+		return err
+	}
+	category.Id = sortKey
+	// Expenses is not a stored attribute anymore
+	legacy := &struct {
+		Expenses []*app.Expense `json:"expenses"`
+	}{}
+	if err := dynamodbattribute.UnmarshalMap(value, legacy); err != nil {
+		return err
+	}
+	transactions := []*dynamodb.TransactWriteItem{}
+	if len(legacy.Expenses) > 0 {
+		category.Expenses = legacy.Expenses
+		category.SumExpenses()
+		for _, expense := range legacy.Expenses {
+			var err error
+			if expense.Id == "" {
+				if expense.Id, err = app.GenerateRandomId(); err != nil {
+					return err
+				}
+			}
+			expense.CategoryId = sortKey
+			aExpense, err := dynamodbattribute.MarshalMap(expense)
+			if err != nil {
+				return err
+			}
+			aExpense[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(eventId)}
+			aExpense[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(itemSortKey(sortKey, expense.Id))}
+			transactions = append(transactions, &dynamodb.TransactWriteItem{
+				Put: &dynamodb.Put{Item: aExpense, TableName: &c.db.TableName},
+			})
+		}
+	}
+	aCategory, err := dynamodbattribute.MarshalMap(category)
 	if err != nil {
-		log.Printf("Got error calling DeetItem:")
 		return err
 	}
-	return nil
+	aCategory[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(eventId)}
+	aCategory[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(sortKey)}
+	// The category goes last, until it is written the migration can be run again
+	transactions = append(transactions, &dynamodb.TransactWriteItem{
+		Put: &dynamodb.Put{Item: aCategory, TableName: &c.db.TableName},
+	})
+	return transactWrite(c.db, transactions)
+}
+
+func categorySortKey(id string) string {
+	if strings.HasPrefix(id, _SORT_KEY_EXPENSE_CATEGORY_PREFIX) {
+		return id
+	}
+	return _SORT_KEY_EXPENSE_CATEGORY_PREFIX + id
+}
+
+func itemSortKey(categoryId, id string) string {
+	return _SORT_KEY_EXPENSE_ITEM_PREFIX + strings.TrimPrefix(categoryId, _SORT_KEY_EXPENSE_CATEGORY_PREFIX) + "-" + id
 }
@@ -0,0 +1,98 @@
+package dynamo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+// deletedBlobs records the keys deleted from the storage
+type deletedBlobs struct {
+	app.BlobStorage
+	keys []string
+}
+
+func (s *deletedBlobs) Delete(key string) error {
+	s.keys = append(s.keys, key)
+	return nil
+}
+
+// deleteItemFixture returns the expense service of an expense E1 with a receipt, TransactWriteItems answers
+// with transact
+func deleteItemFixture(t *testing.T, transact fakeResponse) (*ExpenseService, *deletedBlobs, *fakeDynamo) {
+	itemKey := itemSortKey("CATERING", "E1")
+	expense := marshal(t, &app.Expense{Id: "E1", WhoPaid: "Ana", AmountPaidBase: 100})
+	expense[C_SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(itemKey)}
+	attachment := marshal(t, &app.Attachment{Id: "A1", EntityType: app.AttachOnExpense, EntityId: "E1", Key: "1/A1"})
+	attachment[C_SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(attachmentPrefix(app.AttachOnExpense, "E1") + "A1")}
+	event := getItems(t, map[string]interface{}{
+		_SORT_KEY_EVENT_PREFIX + "1": &app.Event{Id: "1", Name: "Wedding", Status: app.EventPlanning, EventDay: time.Now().AddDate(0, 2, 0)},
+	})
+	db, fake := newFakeDb(t, map[string]fakeResponse{
+		"GetItem": func(input map[string]interface{}) (int, interface{}) {
+			if input["Key"].(map[string]interface{})[C_SORT_KEY].(map[string]interface{})["S"] == itemKey {
+				return http.StatusOK, output(t, &dynamodb.GetItemOutput{Item: expense})
+			}
+			return event(input)
+		},
+		"Query":              respond(http.StatusOK, output(t, &dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{attachment}})),
+		"TransactWriteItems": transact,
+	})
+	storage := &deletedBlobs{}
+	return NewExpenseService(db, nil, nil, nil, nil, nil, storage), storage, fake
+}
+
+func TestDeleteItemDeletesAttachments(t *testing.T) {
+	expenses, storage, fake := deleteItemFixture(t, respond(http.StatusOK, map[string]interface{}{}))
+	if err := expenses.DeleteItem("ana@example.com", "1", "CATERING", "E1"); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if len(storage.keys) != 1 || storage.keys[0] != "1/A1" {
+		t.Errorf("Expected the attachment content deleted got %v", storage.keys)
+	}
+	for i, call := range fake.calls {
+		if call != "TransactWriteItems" {
+			continue
+		}
+		// The expense, its category total and the attachment
+		if items := fake.inputs[i]["TransactItems"].([]interface{}); len(items) != 3 {
+			t.Errorf("Expected the attachment deleted with the expense got %d writes", len(items))
+		}
+	}
+	if fake.called("TransactWriteItems") != 1 {
+		t.Errorf("Expected a single transaction got %d", fake.called("TransactWriteItems"))
+	}
+}
+
+func TestDeleteItemKeepsAttachmentsOfFailedDelete(t *testing.T) {
+	// The expense changed since it was read
+	expenses, storage, _ := deleteItemFixture(t, respond(http.StatusBadRequest, map[string]interface{}{
+		"__type":              "com.amazonaws.dynamodb.v20120810#TransactionCanceledException",
+		"message":             "Transaction cancelled",
+		"CancellationReasons": []map[string]string{{"Code": "ConditionalCheckFailed"}, {"Code": "None"}, {"Code": "None"}},
+	}))
+	if err := expenses.DeleteItem("ana@example.com", "1", "CATERING", "E1"); err == nil {
+		t.Fatal("Expected the delete to fail")
+	}
+	if len(storage.keys) != 0 {
+		t.Errorf("Expected the content of the attachments kept got %v deleted", storage.keys)
+	}
+}
+
+func TestCategoryIdWithoutDashes(t *testing.T) {
+	db, fake := newFakeDb(t, map[string]fakeResponse{
+		"GetItem": respond(http.StatusOK, item(t, map[string]string{_ATTRIBUTE_STATUS: app.EventPlanning})),
+	})
+	expenses := NewExpenseService(db, nil, nil, nil, nil, nil, nil)
+	_, err := expenses.CreateOrUpdate("ana@example.com", "1", &app.ExpenseCategory{Id: "CATERING-2024", Category: "Catering"})
+	if err == nil {
+		t.Error("Expected a category id with dashes rejected")
+	}
+	if fake.called("PutItem") != 0 || fake.called("TransactWriteItems") != 0 {
+		t.Error("Expected nothing written")
+	}
+}
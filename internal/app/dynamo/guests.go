@@ -17,12 +17,14 @@ const _SORT_KEY_GUEST_PREFIX = "GUEST-"
 type GuestService struct {
 	db        *DBConfig
 	authorize *AuthorizationService
+	audit     app.AuditService
 }
 
-func NewGuestService(db *DBConfig, authorize *AuthorizationService) *GuestService {
+func NewGuestService(db *DBConfig, authorize *AuthorizationService, audit app.AuditService) *GuestService {
 	return &GuestService{
 		db:        db,
 		authorize: authorize,
+		audit:     audit,
 	}
 }
 
@@ -101,6 +103,9 @@ func (c *GuestService) List(eventManager, eventId string) ([]*app.Guest, error)
 }
 
 func (c *GuestService) CreateOrUpdate(eventManager, eventId string, u *app.Guest) (*app.Guest, error) {
+	if err := writable(c.db, eventId); err != nil {
+		return nil, err
+	}
 	err := u.Validate()
 	if err != nil {
 		return nil, err
@@ -141,6 +146,7 @@ func (c *GuestService) CreateOrUpdate(eventManager, eventId string, u *app.Guest
 	if err != nil {
 		return nil, err
 	}
+	recordAudit(c.audit, eventManager, eventId, app.AuditGuest, *aGuest[c.db.SORT_KEY].S, value, u)
 	log.Printf("Creatde guest with Id %s", u.Id)
 	return u, nil
 }
@@ -150,6 +156,9 @@ func (c *GuestService) CopyFrom(eventManager string, eventId string, copy *app.C
 	if !c.authorize.Authorize(eventManager, eventId) {
 		return errors.New("unauthorized")
 	}
+	if err := writable(c.db, eventId); err != nil {
+		return err
+	}
 	guests, err := c.List(eventManager, copy.FromEvent)
 	if err != nil {
 		return err
@@ -164,6 +173,13 @@ func (c *GuestService) CopyFrom(eventManager string, eventId string, copy *app.C
 }
 
 func (c *GuestService) Delete(eventManager, eventId, id string) error {
+	if err := writable(c.db, eventId); err != nil {
+		return err
+	}
+	value, err := c.Get(eventManager, eventId, id)
+	if err != nil {
+		return err
+	}
 	input := &dynamodb.DeleteItemInput{
 		Key: map[string]*dynamodb.AttributeValue{
 			c.db.PK_ID: {
@@ -176,10 +192,13 @@ func (c *GuestService) Delete(eventManager, eventId, id string) error {
 		TableName: &c.db.TableName,
 	}
 
-	_, err := c.db.DbService.DeleteItem(input)
+	_, err = c.db.DbService.DeleteItem(input)
 	if err != nil {
 		log.Printf("Got error calling DeetItem:")
 		return err
 	}
+	if value != nil {
+		recordAudit(c.audit, eventManager, eventId, app.AuditGuest, id, value, nil)
+	}
 	return nil
 }
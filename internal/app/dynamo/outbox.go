@@ -0,0 +1,331 @@
+package dynamo
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/craguilar/event-management-service/internal/app"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	_PARTITION_OUTBOX_PREFIX = "OUTBOX#"
+	_SORT_KEY_OUTBOX         = "OUTBOX"
+)
+
+// Sent messages are kept for a while to ignore duplicates of them
+const _OUTBOX_SENT_RETENTION = 30 * 24 * time.Hour
+
+// Range key of the pending index, the unix time of the next attempt only while the message is PENDING
+const _ATTRIBUTE_PENDING_ON = "pendingOn"
+
+// OutboxService stores every message in a partition of its own OUTBOX#<id> with sort key OUTBOX, so
+// the messages are listed through the GSI by sort key. Pending messages are indexed by their next
+// attempt in the sparse pending index as well, so dispatch only reads the ones due.
+type OutboxService struct {
+	db      *DBConfig
+	channel app.Notifier
+	admins  []string
+}
+
+func NewOutboxService(db *DBConfig, channel app.Notifier, admins []string) *OutboxService {
+	if db == nil {
+		log.Panicf("Null reference to db config in OutboxService")
+	}
+	return &OutboxService{
+		db:      db,
+		channel: channel,
+		admins:  admins,
+	}
+}
+
+// Notify ignores the notifications with the Key of a message stored already.
+func (c *OutboxService) Notify(notification *app.Notification) error {
+	now := time.Now()
+	message, err := app.NewOutboxMessage(notification, now)
+	if err != nil {
+		return err
+	}
+	due := message.IsDue(now)
+	if due {
+		message.Claim(now)
+	}
+	err = c.put(message, nil)
+	var conditional *dynamodb.ConditionalCheckFailedException
+	if errors.As(err, &conditional) {
+		log.Printf("Notification %s already in the outbox", message.Id)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !due {
+		log.Printf("Notification %s held until %s", message.Id, message.TimeNextAttemptOn)
+		return nil
+	}
+	return c.send(message)
+}
+
+func (c *OutboxService) Dispatch(now time.Time) (*app.DispatchReport, error) {
+	messages, err := c.queryDue(now)
+	if err != nil {
+		return nil, err
+	}
+	report := &app.DispatchReport{}
+	for _, message := range messages {
+		if !message.IsDue(now) {
+			continue
+		}
+		claimed, err := c.claim(message, now)
+		if err != nil {
+			return nil, err
+		}
+		if !claimed {
+			continue
+		}
+		c.send(message)
+		report.Count(message)
+	}
+	log.Printf("Dispatched outbox messages sent %d, failed %d and dead %d", report.Sent, report.Failed, report.Dead)
+	return report, nil
+}
+
+func (c *OutboxService) Get(admin, id string) (*app.OutboxMessage, error) {
+	if !c.isAdmin(admin) {
+		return nil, errors.New("unauthorized")
+	}
+	return c.get(id)
+}
+
+func (c *OutboxService) List(admin, status string) ([]*app.OutboxMessage, error) {
+	if !c.isAdmin(admin) {
+		return nil, errors.New("unauthorized")
+	}
+	messages, err := c.query(strings.ToUpper(status))
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(messages, func(a, b *app.OutboxMessage) bool {
+		return a.TimeCreatedOn.After(b.TimeCreatedOn)
+	})
+	return messages, nil
+}
+
+// Replay returns the message with the result of the attempt, failing again is not an error.
+func (c *OutboxService) Replay(admin, id string) (*app.OutboxMessage, error) {
+	if !c.isAdmin(admin) {
+		return nil, errors.New("unauthorized")
+	}
+	message, err := c.get(id)
+	if err != nil || message == nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err = message.Replay(now); err != nil {
+		return nil, err
+	}
+	claimed, err := c.claim(message, now)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, errors.New("message is being sent")
+	}
+	c.send(message)
+	return message, nil
+}
+
+// claim starts an attempt unless the message changed since it was read, returns false if someone else
+// claimed it.
+func (c *OutboxService) claim(message *app.OutboxMessage, now time.Time) (bool, error) {
+	updatedOn := message.TimeUpdatedOn
+	message.Claim(now)
+	err := c.put(message, &updatedOn)
+	var conditional *dynamodb.ConditionalCheckFailedException
+	if errors.As(err, &conditional) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// send notifies through the channel a claimed message and stores the result, a failure storing it is
+// only logged as the message is retried once its next attempt is due.
+func (c *OutboxService) send(message *app.OutboxMessage) error {
+	err := c.channel.Notify(message.Notification())
+	if err != nil {
+		log.Printf("WARN: Failed attempt %d of outbox message %s with error %s", message.Attempts, message.Id, err)
+	}
+	claimedOn := message.TimeUpdatedOn
+	message.Record(err, time.Now())
+	if putErr := c.put(message, &claimedOn); putErr != nil {
+		log.Printf("WARN: Unable to record attempt of outbox message %s with error %s", message.Id, putErr)
+	}
+	return err
+}
+
+func (c *OutboxService) isAdmin(admin string) bool {
+	return slices.Contains(c.admins, strings.ToUpper(admin))
+}
+
+func (c *OutboxService) get(id string) (*app.OutboxMessage, error) {
+	input := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			c.db.PK_ID: {
+				S: aws.String(_PARTITION_OUTBOX_PREFIX + id),
+			},
+			c.db.SORT_KEY: {
+				S: aws.String(_SORT_KEY_OUTBOX),
+			},
+		},
+		TableName: &c.db.TableName,
+	}
+	result, err := c.db.DbService.GetItem(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Item) == 0 {
+		return nil, nil
+	}
+	return c.unmarshal(result.Item)
+}
+
+// query returns the messages with status, all of them if empty.
+func (c *OutboxService) query(status string) ([]*app.OutboxMessage, error) {
+	input := &dynamodb.QueryInput{
+		TableName: aws.String(c.db.TableName),
+		IndexName: aws.String(c.db.GSI_OWNER),
+		KeyConditions: map[string]*dynamodb.Condition{
+			c.db.SORT_KEY: {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(_SORT_KEY_OUTBOX),
+					},
+				},
+			},
+		},
+	}
+	if status != "" {
+		input.FilterExpression = aws.String("#status = :status")
+		input.ExpressionAttributeNames = map[string]*string{"#status": aws.String("status")}
+		input.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{":status": {S: aws.String(status)}}
+	}
+	items, err := queryAll(c.db, input)
+	if err != nil {
+		return nil, err
+	}
+	messages := []*app.OutboxMessage{}
+	for _, item := range items {
+		message, err := c.unmarshal(item)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// queryDue returns the pending messages whose next attempt is at or before now.
+func (c *OutboxService) queryDue(now time.Time) ([]*app.OutboxMessage, error) {
+	items, err := queryAll(c.db, &dynamodb.QueryInput{
+		TableName: aws.String(c.db.TableName),
+		IndexName: aws.String(c.db.GSI_PENDING),
+		KeyConditions: map[string]*dynamodb.Condition{
+			c.db.SORT_KEY: {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(_SORT_KEY_OUTBOX),
+					},
+				},
+			},
+			_ATTRIBUTE_PENDING_ON: {
+				ComparisonOperator: aws.String("LE"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						N: aws.String(strconv.FormatInt(now.Unix(), 10)),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	messages := []*app.OutboxMessage{}
+	for _, item := range items {
+		message, err := c.unmarshal(item)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// Migrate indexes the messages pending stored before the pending index existed, it is idempotent and
+// returns the number of messages indexed.
+func (c *OutboxService) Migrate() (int, error) {
+	messages, err := c.query(app.OutboxPending)
+	if err != nil {
+		return 0, err
+	}
+	for _, message := range messages {
+		if err = c.put(message, &message.TimeUpdatedOn); err != nil {
+			return 0, err
+		}
+	}
+	return len(messages), nil
+}
+
+// put stores message if it does not exist yet when updatedOn is nil, otherwise only if it was not
+// updated since updatedOn.
+func (c *OutboxService) put(message *app.OutboxMessage, updatedOn *time.Time) error {
+	aMessage, err := dynamodbattribute.MarshalMap(message)
+	if err != nil {
+		return err
+	}
+	aMessage[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(_PARTITION_OUTBOX_PREFIX + message.Id)}
+	aMessage[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(_SORT_KEY_OUTBOX)}
+	if message.Status == app.OutboxPending {
+		aMessage[_ATTRIBUTE_PENDING_ON] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(message.TimeNextAttemptOn.Unix(), 10))}
+	}
+	if message.Status == app.OutboxSent {
+		aMessage[C_TTL], err = dynamodbattribute.Marshal(message.TimeSentOn.Add(_OUTBOX_SENT_RETENTION).Unix())
+		if err != nil {
+			return err
+		}
+	}
+	input := &dynamodb.PutItemInput{
+		Item:                     aMessage,
+		TableName:                &c.db.TableName,
+		ConditionExpression:      aws.String("attribute_not_exists(#id)"),
+		ExpressionAttributeNames: map[string]*string{"#id": aws.String(c.db.PK_ID)},
+	}
+	if updatedOn != nil {
+		aUpdatedOn, err := dynamodbattribute.Marshal(updatedOn)
+		if err != nil {
+			return err
+		}
+		input.ConditionExpression = aws.String("#updatedOn = :updatedOn")
+		input.ExpressionAttributeNames = map[string]*string{"#updatedOn": aws.String("timeUpdatedOn")}
+		input.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{":updatedOn": aUpdatedOn}
+	}
+	_, err = c.db.DbService.PutItem(input)
+	return err
+}
+
+func (c *OutboxService) unmarshal(item map[string]*dynamodb.AttributeValue) (*app.OutboxMessage, error) {
+	message := &app.OutboxMessage{}
+	if err := dynamodbattribute.UnmarshalMap(item, message); err != nil {
+		return nil, err
+	}
+	message.Id = strings.TrimPrefix(aws.StringValue(item[c.db.PK_ID].S), _PARTITION_OUTBOX_PREFIX)
+	return message, nil
+}
@@ -0,0 +1,51 @@
+package dynamo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+func TestDispatchReadsOnlyDueMessages(t *testing.T) {
+	now := time.Now()
+	message := &app.OutboxMessage{Id: "1", Recipient: "ana@example.com", Subject: "Hi", Body: "Hi", Status: app.OutboxPending, TimeNextAttemptOn: now.Add(-time.Minute), TimeUpdatedOn: now.Add(-time.Hour)}
+	stored := marshal(t, message)
+	stored[C_PK_ID] = &dynamodb.AttributeValue{S: aws.String(_PARTITION_OUTBOX_PREFIX + "1")}
+	db, fake := newFakeDb(t, map[string]fakeResponse{
+		"Query":   respond(http.StatusOK, output(t, &dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{stored}})),
+		"PutItem": respond(http.StatusOK, map[string]interface{}{}),
+	})
+	channel := app.NewMemoryNotifier()
+	report, err := NewOutboxService(db, channel, nil).Dispatch(now)
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if report.Sent != 1 || len(channel.Notifications()) != 1 {
+		t.Errorf("Expected the due message sent got %d", report.Sent)
+	}
+	puts := []map[string]interface{}{}
+	for i, call := range fake.calls {
+		switch call {
+		case "Query":
+			if fake.inputs[i]["IndexName"] != C_GSI_PENDING {
+				t.Errorf("Expected the pending index queried got %v", fake.inputs[i]["IndexName"])
+			}
+		case "PutItem":
+			puts = append(puts, fake.inputs[i]["Item"].(map[string]interface{}))
+		}
+	}
+	if len(puts) != 2 {
+		t.Fatalf("Expected the claim and the result stored got %d", len(puts))
+	}
+	// Pending while claimed, out of the index once sent
+	if _, ok := puts[0][_ATTRIBUTE_PENDING_ON]; !ok {
+		t.Error("Expected the claimed message in the pending index")
+	}
+	if _, ok := puts[1][_ATTRIBUTE_PENDING_ON]; ok {
+		t.Error("Expected the sent message out of the pending index")
+	}
+}
@@ -0,0 +1,109 @@
+package dynamo
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+const _SORT_KEY_PREFERENCES = "PREFERENCES"
+
+// PreferencesService stores the preferences of every email under its user partition USER#<email>.
+type PreferencesService struct {
+	db    *DBConfig
+	links *app.UnsubscribeLinks
+}
+
+func NewPreferencesService(db *DBConfig, links *app.UnsubscribeLinks) *PreferencesService {
+	if db == nil {
+		log.Panicf("Null reference to db config in PreferencesService")
+	}
+	return &PreferencesService{
+		db:    db,
+		links: links,
+	}
+}
+
+func (c *PreferencesService) Get(user string) (*app.NotificationPreferences, error) {
+	input := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			c.db.PK_ID: {
+				S: aws.String(userPartition(user)),
+			},
+			c.db.SORT_KEY: {
+				S: aws.String(_SORT_KEY_PREFERENCES),
+			},
+		},
+		TableName: &c.db.TableName,
+	}
+	result, err := c.db.DbService.GetItem(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Item) == 0 {
+		return app.DefaultPreferences(user), nil
+	}
+	preferences := &app.NotificationPreferences{}
+	if err = dynamodbattribute.UnmarshalMap(result.Item, preferences); err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+func (c *PreferencesService) CreateOrUpdate(user string, u *app.NotificationPreferences) (*app.NotificationPreferences, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	u.Email = strings.ToLower(user)
+	if u.Digest == "" {
+		u.Digest = app.DigestDaily
+	}
+	if u.DisabledTypes == nil {
+		u.DisabledTypes = []string{}
+	}
+	if u.MutedEventIds == nil {
+		u.MutedEventIds = []string{}
+	}
+	u.TimeUpdatedOn = time.Now()
+	if err := c.put(u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (c *PreferencesService) Unsubscribe(token string) error {
+	email, notificationType, err := c.links.Verify(token)
+	if err != nil {
+		return err
+	}
+	preferences, err := c.Get(email)
+	if err != nil {
+		return err
+	}
+	preferences.Unsubscribe(notificationType)
+	preferences.TimeUpdatedOn = time.Now()
+	if err = c.put(preferences); err != nil {
+		return err
+	}
+	log.Printf("Unsubscribed %s from %s", email, notificationType)
+	return nil
+}
+
+func (c *PreferencesService) put(u *app.NotificationPreferences) error {
+	aPreferences, err := dynamodbattribute.MarshalMap(u)
+	if err != nil {
+		return err
+	}
+	aPreferences[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(userPartition(u.Email))}
+	aPreferences[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(_SORT_KEY_PREFERENCES)}
+	_, err = c.db.DbService.PutItem(&dynamodb.PutItemInput{
+		Item:      aPreferences,
+		TableName: &c.db.TableName,
+	})
+	return err
+}
@@ -0,0 +1,61 @@
+package dynamo
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+const (
+	_PARTITION_RATE_LIMIT_PREFIX = "RATE_LIMIT#"
+	_SORT_KEY_RATE_LIMIT         = "RATE_LIMIT"
+)
+
+// RateLimiter counts the calls of every key and window in an item of its own with an atomic counter,
+// the items expire through TTL once the window is over.
+type RateLimiter struct {
+	db *DBConfig
+}
+
+func NewRateLimiter(db *DBConfig) *RateLimiter {
+	if db == nil {
+		log.Panicf("Null reference to db config in RateLimiter")
+	}
+	return &RateLimiter{db: db}
+}
+
+func (c *RateLimiter) Allow(key string, limit app.RateLimit) (bool, error) {
+	now := time.Now()
+	window := now.Truncate(limit.Window)
+	partition := _PARTITION_RATE_LIMIT_PREFIX + key + "#" + strconv.FormatInt(window.Unix(), 10)
+	input := &dynamodb.UpdateItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			c.db.PK_ID:    {S: aws.String(partition)},
+			c.db.SORT_KEY: {S: aws.String(_SORT_KEY_RATE_LIMIT)},
+		},
+		UpdateExpression: aws.String("ADD #count :one SET #ttl = :expires"),
+		ExpressionAttributeNames: map[string]*string{
+			"#count": aws.String("count"),
+			"#ttl":   aws.String(C_TTL),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":one":     {N: aws.String("1")},
+			":expires": {N: aws.String(strconv.FormatInt(window.Add(limit.Window).Unix(), 10))},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueUpdatedNew),
+		TableName:    &c.db.TableName,
+	}
+	result, err := c.db.DbService.UpdateItem(input)
+	if err != nil {
+		return false, err
+	}
+	count, err := strconv.Atoi(aws.StringValue(result.Attributes["count"].N))
+	if err != nil {
+		return false, err
+	}
+	return count <= limit.Limit, nil
+}
@@ -0,0 +1,279 @@
+package dynamo
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/craguilar/event-management-service/internal/app"
+	"golang.org/x/exp/slices"
+)
+
+const _SORT_KEY_SCHEDULE_PREFIX = "SCHEDULE-"
+
+// ScheduleService stores the schedule items under the event partition, the RSVPs are kept in the item
+// itself as they are bounded by the guests of the event.
+type ScheduleService struct {
+	db           *DBConfig
+	authorize    *AuthorizationService
+	audit        app.AuditService
+	guestService app.GuestService
+}
+
+func NewScheduleService(db *DBConfig, authorize *AuthorizationService, audit app.AuditService, guest app.GuestService) *ScheduleService {
+	if db == nil {
+		log.Panicf("Null reference to db config in ScheduleService")
+	}
+	return &ScheduleService{
+		db:           db,
+		authorize:    authorize,
+		audit:        audit,
+		guestService: guest,
+	}
+}
+
+func (c *ScheduleService) Get(eventManager, eventId, id string) (*app.ScheduleItem, error) {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	item, err := c.get(eventId, id)
+	if err != nil || item == nil {
+		return nil, err
+	}
+	guests, err := c.guestService.List(eventManager, eventId)
+	if err != nil {
+		return nil, err
+	}
+	item.ComputeHeadcount(guests)
+	return item, nil
+}
+
+func (c *ScheduleService) List(eventManager, eventId string) ([]*app.ScheduleItem, error) {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	log.Printf("Getting the schedule of %s", eventId)
+	items, err := queryAll(c.db, &dynamodb.QueryInput{
+		TableName: aws.String(c.db.TableName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			c.db.PK_ID: {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(eventId),
+					},
+				},
+			},
+			c.db.SORT_KEY: {
+				ComparisonOperator: aws.String("BEGINS_WITH"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(_SORT_KEY_SCHEDULE_PREFIX),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	guests, err := c.guestService.List(eventManager, eventId)
+	if err != nil {
+		return nil, err
+	}
+	list := []*app.ScheduleItem{}
+	for _, value := range items {
+		item := &app.ScheduleItem{}
+		err = dynamodbattribute.UnmarshalMap(value, item)
+		if err != nil {
+			return nil, err
+		}
+		item.Id = *value[c.db.SORT_KEY].S
+		item.ComputeHeadcount(guests)
+		list = append(list, item)
+	}
+	app.SortSchedule(list)
+	return list, nil
+}
+
+// CreateOrUpdate keeps the stored responses, answers of guests no longer invited are dropped.
+func (c *ScheduleService) CreateOrUpdate(eventManager, eventId string, u *app.ScheduleItem) (*app.ScheduleItem, error) {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	if err := writable(c.db, eventId); err != nil {
+		return nil, err
+	}
+	err := u.Validate()
+	if err != nil {
+		return nil, err
+	}
+	guests, err := c.guestService.List(eventManager, eventId)
+	if err != nil {
+		return nil, err
+	}
+	if err = u.CheckGuests(guests); err != nil {
+		return nil, err
+	}
+	var value *app.ScheduleItem
+	if u.Id == "" {
+		id, err := app.GenerateRandomId()
+		if err != nil {
+			return nil, err
+		}
+		u.Id = _SORT_KEY_SCHEDULE_PREFIX + id
+		u.Responses = []*app.ScheduleRsvp{}
+		u.TimeCreatedOn = time.Now()
+	} else {
+		value, err = c.get(eventId, u.Id)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			return nil, errors.New("schedule item not found")
+		}
+		u.TimeCreatedOn = value.TimeCreatedOn
+		u.Responses = []*app.ScheduleRsvp{}
+		for _, rsvp := range value.Responses {
+			i := slices.IndexFunc(guests, func(guest *app.Guest) bool { return guest.Id == rsvp.GuestId })
+			if i >= 0 && u.Invites(guests[i]) {
+				u.Responses = append(u.Responses, rsvp)
+			}
+		}
+	}
+	u.TimeUpdatedOn = time.Now()
+
+	log.Printf("CreateOrUpdate schedule item with Id /%s", u.Id)
+	if err = c.put(eventId, u, nil); err != nil {
+		return nil, err
+	}
+	recordAudit(c.audit, eventManager, eventId, app.AuditSchedule, u.Id, value, u)
+	u.ComputeHeadcount(guests)
+	return u, nil
+}
+
+func (c *ScheduleService) Delete(eventManager, eventId, id string) error {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return errors.New("unauthorized")
+	}
+	if err := writable(c.db, eventId); err != nil {
+		return err
+	}
+	value, err := c.get(eventId, id)
+	if err != nil || value == nil {
+		return err
+	}
+	input := &dynamodb.DeleteItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			c.db.PK_ID: {
+				S: aws.String(eventId),
+			},
+			c.db.SORT_KEY: {
+				S: aws.String(id),
+			},
+		},
+		TableName: &c.db.TableName,
+	}
+	_, err = c.db.DbService.DeleteItem(input)
+	if err != nil {
+		log.Printf("Got error calling delete schedule item %s ", err)
+		return err
+	}
+	recordAudit(c.audit, eventManager, eventId, app.AuditSchedule, id, value, nil)
+	return nil
+}
+
+// Respond fails if the item was updated concurrently, so an answer never overrides another one.
+func (c *ScheduleService) Respond(eventManager, eventId, id string, rsvp *app.ScheduleRsvp) (*app.ScheduleItem, error) {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	if err := writable(c.db, eventId); err != nil {
+		return nil, err
+	}
+	item, err := c.get(eventId, id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, errors.New("schedule item not found")
+	}
+	guests, err := c.guestService.List(eventManager, eventId)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(guests, func(guest *app.Guest) bool { return guest.Id == rsvp.GuestId })
+	if i < 0 {
+		return nil, errors.New("guest not found")
+	}
+	if err = item.SetResponse(guests[i], rsvp); err != nil {
+		return nil, err
+	}
+	previous := item.TimeUpdatedOn
+	item.TimeUpdatedOn = time.Now()
+	if err = c.put(eventId, item, &previous); err != nil {
+		return nil, err
+	}
+	item.ComputeHeadcount(guests)
+	return item, nil
+}
+
+// get returns nil for ids of other entities, so a client supplied id never reads or overrides them.
+func (c *ScheduleService) get(eventId, id string) (*app.ScheduleItem, error) {
+	if !strings.HasPrefix(id, _SORT_KEY_SCHEDULE_PREFIX) {
+		return nil, nil
+	}
+	input := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			c.db.PK_ID: {
+				S: aws.String(eventId),
+			},
+			c.db.SORT_KEY: {
+				S: aws.String(id),
+			},
+		},
+		TableName: &c.db.TableName,
+	}
+	result, err := c.db.DbService.GetItem(input)
+	if err != nil {
+		return nil, err
+	}
+	item := &app.ScheduleItem{}
+	err = dynamodbattribute.UnmarshalMap(result.Item, item)
+	if err != nil {
+		return nil, err
+	}
+	if item.Name == "" {
+		return nil, nil
+	}
+	item.Id = id
+	return item, nil
+}
+
+// put writes the item, if updatedOn is given only when it was not updated since.
+func (c *ScheduleService) put(eventId string, u *app.ScheduleItem, updatedOn *time.Time) error {
+	aItem, err := dynamodbattribute.MarshalMap(u)
+	if err != nil {
+		return err
+	}
+	aItem[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(eventId)}
+	aItem[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(u.Id)}
+	input := &dynamodb.PutItemInput{
+		Item:      aItem,
+		TableName: &c.db.TableName,
+	}
+	if updatedOn != nil {
+		aUpdatedOn, err := dynamodbattribute.Marshal(updatedOn)
+		if err != nil {
+			return err
+		}
+		input.ConditionExpression = aws.String("timeUpdatedOn = :updatedOn")
+		input.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{":updatedOn": aUpdatedOn}
+	}
+	_, err = c.db.DbService.PutItem(input)
+	return err
+}
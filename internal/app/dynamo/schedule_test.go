@@ -0,0 +1,50 @@
+package dynamo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/craguilar/event-management-service/internal/app"
+	"github.com/craguilar/event-management-service/internal/app/mock"
+)
+
+// ownedEvent answers GetItem with event 1 owned by ana@example.com
+func ownedEvent(t *testing.T) fakeResponse {
+	return getItems(t, map[string]interface{}{
+		_SORT_KEY_OWNER_PREFIX + "ANA@EXAMPLE.COM": &app.EventOwner{OwnerEmail: "ANA@EXAMPLE.COM", EventSummary: &app.EventSummary{Id: "1", Name: "Wedding"}},
+		_SORT_KEY_EVENT_PREFIX + "1":               &app.Event{Id: "1", Name: "Wedding", Status: app.EventPlanning},
+		_SORT_KEY_TASK_PREFIX + "1":                &app.Task{Id: "TASK-1", Name: "Book DJ", Status: "PENDING"},
+	})
+}
+
+func TestScheduleRejectsIdsOfOtherEntities(t *testing.T) {
+	db, fake := newFakeDb(t, map[string]fakeResponse{"GetItem": ownedEvent(t)})
+	schedule := NewScheduleService(db, NewAuthorizationService(db), nil, mock.NewGuestService(mock.NewEventService()))
+	for _, id := range []string{_SORT_KEY_EVENT_PREFIX + "1", _SORT_KEY_TASK_PREFIX + "1"} {
+		_, err := schedule.CreateOrUpdate("ana@example.com", "1", &app.ScheduleItem{Id: id, Name: "Ceremony", StartTime: time.Now()})
+		if err == nil {
+			t.Errorf("Expected %s to be rejected", id)
+		}
+		if item, err := schedule.Get("ana@example.com", "1", id); err != nil || item != nil {
+			t.Errorf("Expected %s not found got %v %v", id, item, err)
+		}
+		if err = schedule.Delete("ana@example.com", "1", id); err != nil {
+			t.Errorf("Test failed with error %s", err)
+		}
+	}
+	if fake.called("PutItem") != 0 || fake.called("DeleteItem") != 0 {
+		t.Error("Expected nothing written")
+	}
+}
+
+func TestCampaignRejectsIdsOfOtherEntities(t *testing.T) {
+	db, fake := newFakeDb(t, map[string]fakeResponse{"GetItem": ownedEvent(t)})
+	campaigns := NewCampaignService(db, NewAuthorizationService(db), nil, nil, nil, nil, nil)
+	_, err := campaigns.CreateOrUpdate("ana@example.com", "1", &app.Campaign{Id: _SORT_KEY_EVENT_PREFIX + "1", Name: "Save the date", Template: app.CampaignSaveTheDate})
+	if err == nil {
+		t.Error("Expected the event id to be rejected")
+	}
+	if fake.called("PutItem") != 0 {
+		t.Error("Expected nothing written")
+	}
+}
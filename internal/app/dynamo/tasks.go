@@ -1,6 +1,7 @@
 package dynamo
 
 import (
+	"errors"
 	"log"
 	"time"
 
@@ -13,12 +14,14 @@ import (
 const _SORT_KEY_TASK_PREFIX = "TASK-"
 
 type TaskService struct {
-	db *DBConfig
+	db    *DBConfig
+	audit app.AuditService
 }
 
-func NewTaskService(db *DBConfig) *TaskService {
+func NewTaskService(db *DBConfig, audit app.AuditService) *TaskService {
 	return &TaskService{
-		db: db,
+		db:    db,
+		audit: audit,
 	}
 }
 
@@ -95,7 +98,10 @@ func (c *TaskService) List(eventId string) ([]*app.Task, error) {
 	return list, nil
 }
 
-func (c *TaskService) CreateOrUpdate(eventId string, u *app.Task) (*app.Task, error) {
+func (c *TaskService) CreateOrUpdate(eventManager, eventId string, u *app.Task) (*app.Task, error) {
+	if err := writable(c.db, eventId); err != nil {
+		return nil, err
+	}
 	err := u.Validate()
 	if err != nil {
 		return nil, err
@@ -135,11 +141,86 @@ func (c *TaskService) CreateOrUpdate(eventId string, u *app.Task) (*app.Task, er
 	if err != nil {
 		return nil, err
 	}
+	recordAudit(c.audit, eventManager, eventId, app.AuditTask, *aTask[c.db.SORT_KEY].S, value, u)
 	log.Printf("Creatde guest with Id %s", u.Id)
 	return u, nil
 }
 
-func (c *TaskService) Delete(eventId, id string) error {
+// createOccurrence creates next, the next occurrence of the DONE task done, and links it from done in a
+// single transaction. It returns false when the occurrence of done was already created.
+func (c *TaskService) createOccurrence(eventId string, done, next *app.Task) (bool, error) {
+	if err := writable(c.db, eventId); err != nil {
+		return false, err
+	}
+	next.TimeCreatedOn = time.Now()
+	aNext, err := dynamodbattribute.MarshalMap(next)
+	if err != nil {
+		return false, err
+	}
+	sortKey := _SORT_KEY_TASK_PREFIX + next.Id
+	aNext[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(eventId)}
+	aNext[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(sortKey)}
+	now := time.Now()
+	aNow, err := dynamodbattribute.Marshal(now)
+	if err != nil {
+		return false, err
+	}
+	transactions := []*dynamodb.TransactWriteItem{
+		{
+			Put: &dynamodb.Put{
+				Item:                     aNext,
+				ConditionExpression:      aws.String("attribute_not_exists(#id)"),
+				ExpressionAttributeNames: map[string]*string{"#id": aws.String(c.db.PK_ID)},
+				TableName:                &c.db.TableName,
+			},
+		},
+		{
+			Update: &dynamodb.Update{
+				Key: map[string]*dynamodb.AttributeValue{
+					c.db.PK_ID:    {S: aws.String(eventId)},
+					c.db.SORT_KEY: {S: aws.String(done.Id)},
+				},
+				UpdateExpression:    aws.String("SET #next = :next, #updatedOn = :now"),
+				ConditionExpression: aws.String("attribute_exists(#id) AND (attribute_not_exists(#next) OR attribute_type(#next, :null))"),
+				ExpressionAttributeNames: map[string]*string{
+					"#id":        aws.String(c.db.PK_ID),
+					"#next":      aws.String("nextOccurrenceId"),
+					"#updatedOn": aws.String("timeUpdatedOn"),
+				},
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":next": {S: aws.String(next.Id)},
+					":now":  aNow,
+					":null": {S: aws.String("NULL")},
+				},
+				TableName: &c.db.TableName,
+			},
+		},
+	}
+	_, err = c.db.DbService.TransactWriteItems(&dynamodb.TransactWriteItemsInput{TransactItems: transactions})
+	// The second item of the transaction is the DONE task, linked by a concurrent run
+	var canceled *dynamodb.TransactionCanceledException
+	if errors.As(err, &canceled) && len(canceled.CancellationReasons) > 1 &&
+		aws.StringValue(canceled.CancellationReasons[1].Code) == "ConditionalCheckFailed" {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	before := *done
+	done.NextOccurrenceId, done.TimeUpdatedOn = next.Id, now
+	recordAudit(c.audit, app.SystemActor, eventId, app.AuditTask, sortKey, nil, next)
+	recordAudit(c.audit, app.SystemActor, eventId, app.AuditTask, done.Id, &before, done)
+	return true, nil
+}
+
+func (c *TaskService) Delete(eventManager, eventId, id string) error {
+	if err := writable(c.db, eventId); err != nil {
+		return err
+	}
+	value, err := c.Get(eventId, id)
+	if err != nil {
+		return err
+	}
 	input := &dynamodb.DeleteItemInput{
 		Key: map[string]*dynamodb.AttributeValue{
 			c.db.PK_ID: {
@@ -152,10 +233,13 @@ func (c *TaskService) Delete(eventId, id string) error {
 		TableName: &c.db.TableName,
 	}
 
-	_, err := c.db.DbService.DeleteItem(input)
+	_, err = c.db.DbService.DeleteItem(input)
 	if err != nil {
 		log.Printf("Got error calling delete task  %s ", err)
 		return err
 	}
+	if value != nil {
+		recordAudit(c.audit, eventManager, eventId, app.AuditTask, id, value, nil)
+	}
 	return nil
 }
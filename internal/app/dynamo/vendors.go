@@ -0,0 +1,260 @@
+package dynamo
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+// Vendors of an event are stored under the event partition, shared vendors under the partition of the
+// user with a different prefix so the Id alone tells where a vendor lives.
+const (
+	_SORT_KEY_VENDOR_PREFIX        = "VENDOR-"
+	_SORT_KEY_SHARED_VENDOR_PREFIX = "SHARED_VENDOR-"
+	_PARTITION_USER_PREFIX         = "USER#"
+)
+
+type VendorService struct {
+	db             *DBConfig
+	authorize      *AuthorizationService
+	audit          app.AuditService
+	expenseService app.ExpenseService
+	taskService    app.TaskService
+	storage        app.BlobStorage
+}
+
+func NewVendorService(db *DBConfig, authorize *AuthorizationService, audit app.AuditService, expense app.ExpenseService, task app.TaskService, storage app.BlobStorage) *VendorService {
+	if db == nil {
+		log.Panicf("Null reference to db config in VendorService")
+	}
+	return &VendorService{
+		db:             db,
+		authorize:      authorize,
+		audit:          audit,
+		expenseService: expense,
+		taskService:    task,
+		storage:        storage,
+	}
+}
+
+func (c *VendorService) Get(eventManager, eventId, id string) (*app.Vendor, error) {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	input := &dynamodb.GetItemInput{
+		Key: map[string]*dynamodb.AttributeValue{
+			c.db.PK_ID: {
+				S: aws.String(c.partition(eventManager, eventId, id)),
+			},
+			c.db.SORT_KEY: {
+				S: aws.String(id),
+			},
+		},
+		TableName: &c.db.TableName,
+	}
+	result, err := c.db.DbService.GetItem(input)
+	if err != nil {
+		return nil, err
+	}
+	vendor := &app.Vendor{}
+	err = dynamodbattribute.UnmarshalMap(result.Item, vendor)
+	if err != nil {
+		return nil, err
+	}
+	if vendor.Name == "" {
+		return nil, nil
+	}
+	vendor.Id = id
+	return vendor, nil
+}
+
+func (c *VendorService) List(eventManager, eventId string) ([]*app.Vendor, error) {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	log.Printf("Getting all vendors for %s", eventId)
+	list, err := c.query(eventId, _SORT_KEY_VENDOR_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := c.query(userPartition(eventManager), _SORT_KEY_SHARED_VENDOR_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+	return append(list, shared...), nil
+}
+
+func (c *VendorService) CreateOrUpdate(eventManager, eventId string, u *app.Vendor) (*app.Vendor, error) {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	if err := writable(c.db, eventId); err != nil {
+		return nil, err
+	}
+	err := u.Validate()
+	if err != nil {
+		return nil, err
+	}
+	var value *app.Vendor
+	if u.Id == "" {
+		id, err := app.GenerateRandomId()
+		if err != nil {
+			return nil, err
+		}
+		u.Id = _SORT_KEY_VENDOR_PREFIX + id
+		if u.Shared {
+			u.Id = _SORT_KEY_SHARED_VENDOR_PREFIX + id
+		}
+		u.TimeCreatedOn = time.Now()
+	} else {
+		value, err = c.Get(eventManager, eventId, u.Id)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			return nil, errors.New("vendor not found")
+		}
+		u.TimeCreatedOn = value.TimeCreatedOn
+	}
+	// A vendor can not be moved between an event and the user
+	u.Shared = strings.HasPrefix(u.Id, _SORT_KEY_SHARED_VENDOR_PREFIX)
+	u.TimeUpdatedOn = time.Now()
+
+	log.Printf("CreateOrUpdate vendor with Id /%s", u.Id)
+	aVendor, err := dynamodbattribute.MarshalMap(u)
+	if err != nil {
+		return nil, err
+	}
+	aVendor[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(c.partition(eventManager, eventId, u.Id))}
+	aVendor[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(u.Id)}
+	input := &dynamodb.PutItemInput{
+		Item:      aVendor,
+		TableName: &c.db.TableName,
+	}
+	_, err = c.db.DbService.PutItem(input)
+	if err != nil {
+		return nil, err
+	}
+	// Shared vendors do not belong to any event
+	if !u.Shared {
+		recordAudit(c.audit, eventManager, eventId, app.AuditVendor, u.Id, value, u)
+	}
+	return u, nil
+}
+
+// Delete removes the vendor, categories, expenses and tasks keep the link to it but are no longer
+// included in the vendor summary.
+func (c *VendorService) Delete(eventManager, eventId, id string) error {
+	if err := writable(c.db, eventId); err != nil {
+		return err
+	}
+	value, err := c.Get(eventManager, eventId, id)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		return nil
+	}
+	// The contracts are attached to the vendor in the event
+	attachments, err := entityAttachments(c.db, eventId, app.AttachOnVendor, id)
+	if err != nil {
+		return err
+	}
+	transactions := []*dynamodb.TransactWriteItem{
+		{
+			Delete: &dynamodb.Delete{
+				Key: map[string]*dynamodb.AttributeValue{
+					c.db.PK_ID: {
+						S: aws.String(c.partition(eventManager, eventId, id)),
+					},
+					c.db.SORT_KEY: {
+						S: aws.String(id),
+					},
+				},
+				TableName: &c.db.TableName,
+			},
+		},
+	}
+	err = transactWrite(c.db, append(transactions, attachmentDeletes(c.db, eventId, attachments)...))
+	if err != nil {
+		log.Printf("Got error calling delete vendor %s ", err)
+		return err
+	}
+	deleteAttachmentBlobs(c.storage, attachments)
+	if !value.Shared {
+		recordAudit(c.audit, eventManager, eventId, app.AuditVendor, id, value, nil)
+	}
+	return nil
+}
+
+func (c *VendorService) Summary(eventManager, eventId string) ([]*app.VendorSummary, error) {
+	vendors, err := c.List(eventManager, eventId)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := c.expenseService.List(eventId)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := c.taskService.List(eventId)
+	if err != nil {
+		return nil, err
+	}
+	return app.NewVendorSummaries(vendors, categories, tasks), nil
+}
+
+func (c *VendorService) query(partition, prefix string) ([]*app.Vendor, error) {
+	items, err := queryAll(c.db, &dynamodb.QueryInput{
+		TableName: aws.String(c.db.TableName),
+		KeyConditions: map[string]*dynamodb.Condition{
+			c.db.PK_ID: {
+				ComparisonOperator: aws.String("EQ"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(partition),
+					},
+				},
+			},
+			c.db.SORT_KEY: {
+				ComparisonOperator: aws.String("BEGINS_WITH"),
+				AttributeValueList: []*dynamodb.AttributeValue{
+					{
+						S: aws.String(prefix),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	list := []*app.Vendor{}
+	for _, value := range items {
+		vendor := &app.Vendor{}
+		err = dynamodbattribute.UnmarshalMap(value, vendor)
+		if err != nil {
+			return nil, err
+		}
+		vendor.Id = *value[c.db.SORT_KEY].S
+		list = append(list, vendor)
+	}
+	return list, nil
+}
+
+func (c *VendorService) partition(eventManager, eventId, id string) string {
+	if strings.HasPrefix(id, _SORT_KEY_SHARED_VENDOR_PREFIX) {
+		return userPartition(eventManager)
+	}
+	return eventId
+}
+
+// userPartition is the partition of the items owned by a user instead of an event
+func userPartition(userName string) string {
+	return _PARTITION_USER_PREFIX + strings.ToUpper(userName)
+}
@@ -0,0 +1,43 @@
+package dynamo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+func TestVendorDeleteDeletesContracts(t *testing.T) {
+	vendorId := _SORT_KEY_VENDOR_PREFIX + "1"
+	contract := marshal(t, &app.Attachment{Id: "A1", EntityType: app.AttachOnVendor, EntityId: vendorId, Key: "1/A1"})
+	contract[C_SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(attachmentPrefix(app.AttachOnVendor, vendorId) + "A1")}
+	db, fake := newFakeDb(t, map[string]fakeResponse{
+		"GetItem": getItems(t, map[string]interface{}{
+			_SORT_KEY_OWNER_PREFIX + "ANA@EXAMPLE.COM": &app.EventOwner{OwnerEmail: "ANA@EXAMPLE.COM", EventSummary: &app.EventSummary{Id: "1", Name: "Wedding"}},
+			_SORT_KEY_EVENT_PREFIX + "1":               &app.Event{Id: "1", Name: "Wedding", Status: app.EventPlanning, EventDay: time.Now().AddDate(0, 2, 0)},
+			vendorId:                                   &app.Vendor{Name: "Flores"},
+		}),
+		"Query":              respond(http.StatusOK, output(t, &dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{contract}})),
+		"TransactWriteItems": respond(http.StatusOK, map[string]interface{}{}),
+	})
+	storage := &deletedBlobs{}
+	vendors := NewVendorService(db, NewAuthorizationService(db), nil, nil, nil, storage)
+	if err := vendors.Delete("ana@example.com", "1", vendorId); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if len(storage.keys) != 1 || storage.keys[0] != "1/A1" {
+		t.Errorf("Expected the contract content deleted got %v", storage.keys)
+	}
+	// The vendor and its contract
+	for i, call := range fake.calls {
+		if call == "TransactWriteItems" && len(fake.inputs[i]["TransactItems"].([]interface{})) != 2 {
+			t.Errorf("Expected the contract deleted with the vendor got %v", fake.inputs[i]["TransactItems"])
+		}
+	}
+	if fake.called("TransactWriteItems") != 1 || fake.called("DeleteItem") != 0 {
+		t.Errorf("Expected a single transaction got %v", fake.calls)
+	}
+}
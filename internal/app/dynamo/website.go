@@ -0,0 +1,260 @@
+package dynamo
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+// The settings are stored under the event partition with sort key WEBSITE, the slug is reserved by an
+// item in its own partition WEBSITE#<slug> pointing to the event so it is unique across events.
+const (
+	_PARTITION_WEBSITE_PREFIX = "WEBSITE#"
+	_SORT_KEY_WEBSITE         = "WEBSITE"
+)
+
+type websiteSlug struct {
+	EventId string
+}
+
+type WebsiteService struct {
+	db              *DBConfig
+	authorize       *AuthorizationService
+	eventService    app.EventService
+	guestService    app.GuestService
+	scheduleService app.ScheduleService
+	limiter         app.RateLimiter
+}
+
+func NewWebsiteService(db *DBConfig, authorize *AuthorizationService, event app.EventService, guest app.GuestService, schedule app.ScheduleService, limiter app.RateLimiter) *WebsiteService {
+	if db == nil {
+		log.Panicf("Null reference to db config in WebsiteService")
+	}
+	return &WebsiteService{
+		db:              db,
+		authorize:       authorize,
+		eventService:    event,
+		guestService:    guest,
+		scheduleService: schedule,
+		limiter:         limiter,
+	}
+}
+
+func (c *WebsiteService) Get(eventManager, eventId string) (*app.EventWebsite, error) {
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	return c.get(eventId)
+}
+
+// CreateOrUpdate fails if the slug is already used by another event, the previous slug is released.
+func (c *WebsiteService) CreateOrUpdate(eventManager, eventId string, u *app.EventWebsite) (*app.EventWebsite, error) {
+	eventManager = strings.ToUpper(eventManager)
+	if !c.authorize.Authorize(eventManager, eventId) {
+		return nil, errors.New("unauthorized")
+	}
+	if err := writable(c.db, eventId); err != nil {
+		return nil, err
+	}
+	u.Slug = strings.ToLower(strings.TrimSpace(u.Slug))
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	previous, err := c.get(eventId)
+	if err != nil {
+		return nil, err
+	}
+	u.EventId = eventId
+	u.PublishedBy = eventManager
+	u.TimeCreatedOn = time.Now()
+	if previous != nil {
+		u.TimeCreatedOn = previous.TimeCreatedOn
+	}
+	u.TimeUpdatedOn = time.Now()
+
+	aWebsite, err := dynamodbattribute.MarshalMap(u)
+	if err != nil {
+		return nil, err
+	}
+	aSlug, err := dynamodbattribute.MarshalMap(&websiteSlug{EventId: eventId})
+	if err != nil {
+		return nil, err
+	}
+	transactions := []*dynamodb.TransactWriteItem{
+		{
+			Put: &dynamodb.Put{
+				Item:      c.withKey(aWebsite, eventId, _SORT_KEY_WEBSITE),
+				TableName: &c.db.TableName,
+			},
+		},
+		{
+			Put: &dynamodb.Put{
+				Item:                c.withKey(aSlug, _PARTITION_WEBSITE_PREFIX+u.Slug, _SORT_KEY_WEBSITE),
+				ConditionExpression: aws.String("attribute_not_exists(#id) OR EventId = :eventId"),
+				ExpressionAttributeNames: map[string]*string{
+					"#id": aws.String(c.db.PK_ID),
+				},
+				ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+					":eventId": {S: aws.String(eventId)},
+				},
+				TableName: &c.db.TableName,
+			},
+		},
+	}
+	if previous != nil && previous.Slug != u.Slug {
+		transactions = append(transactions, &dynamodb.TransactWriteItem{
+			Delete: &dynamodb.Delete{
+				Key:       c.withKey(map[string]*dynamodb.AttributeValue{}, _PARTITION_WEBSITE_PREFIX+previous.Slug, _SORT_KEY_WEBSITE),
+				TableName: &c.db.TableName,
+			},
+		})
+	}
+	_, err = c.db.DbService.TransactWriteItems(&dynamodb.TransactWriteItemsInput{TransactItems: transactions})
+	// The second item of the transaction is the slug
+	var canceled *dynamodb.TransactionCanceledException
+	if errors.As(err, &canceled) && len(canceled.CancellationReasons) > 1 &&
+		aws.StringValue(canceled.CancellationReasons[1].Code) == "ConditionalCheckFailed" {
+		return nil, errors.New("slug already in use")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// GetPublic returns nil as well when the website is not published or the event is archived.
+func (c *WebsiteService) GetPublic(slug string) (*app.PublicEvent, error) {
+	website, event, err := c.published(slug)
+	if err != nil || website == nil {
+		return nil, err
+	}
+	schedule := []*app.ScheduleItem{}
+	if website.ScheduleVisible {
+		schedule, err = c.scheduleService.List(website.PublishedBy, event.Id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return app.NewPublicEvent(website, event, schedule, time.Now()), nil
+}
+
+// Respond rate limits every client and page before looking the guest up, so the guest list can not be
+// guessed. The answer is recorded on the guest and on the schedule items the guest is invited to.
+func (c *WebsiteService) Respond(slug, source string, rsvp *app.PublicRsvp) (*app.PublicRsvpResult, error) {
+	if err := rsvp.Validate(); err != nil {
+		return nil, err
+	}
+	allowed, err := c.limiter.Allow("RSVP#"+slug+"#"+source, app.RsvpSourceRateLimit)
+	if err != nil {
+		return nil, err
+	}
+	if allowed {
+		allowed, err = c.limiter.Allow("RSVP#"+slug, app.RsvpPageRateLimit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !allowed {
+		return nil, app.ErrRateLimited
+	}
+	website, event, err := c.published(slug)
+	if err != nil {
+		return nil, err
+	}
+	if website == nil {
+		return nil, nil
+	}
+	if !website.IsRsvpOpen(event, time.Now()) {
+		return nil, app.ErrRsvpClosed
+	}
+	guests, err := c.guestService.List(website.PublishedBy, event.Id)
+	if err != nil {
+		return nil, err
+	}
+	guest := app.FindGuestByEmail(guests, rsvp.Email)
+	if guest == nil {
+		return nil, app.ErrGuestNotFound
+	}
+	schedule, err := c.scheduleService.List(website.PublishedBy, event.Id)
+	if err != nil {
+		return nil, err
+	}
+	invited, err := rsvp.Apply(guest, schedule)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = c.guestService.CreateOrUpdate(website.PublishedBy, event.Id, guest); err != nil {
+		return nil, err
+	}
+	for _, item := range invited {
+		answer := &app.ScheduleRsvp{GuestId: guest.Id, Attending: rsvp.Attending, Seats: rsvp.Seats}
+		if _, err = c.scheduleService.Respond(website.PublishedBy, event.Id, item.Id, answer); err != nil {
+			return nil, err
+		}
+	}
+	log.Printf("Recorded rsvp of %s for %s", guest.Id, event.Id)
+	return &app.PublicRsvpResult{FirstName: guest.FirstName, Attending: rsvp.Attending, Seats: rsvp.Seats}, nil
+}
+
+// published returns the website and event of slug, nil if it is not published, the event is archived
+// or the owner that published it is no longer an owner.
+func (c *WebsiteService) published(slug string) (*app.EventWebsite, *app.Event, error) {
+	input := &dynamodb.GetItemInput{
+		Key:       c.withKey(map[string]*dynamodb.AttributeValue{}, _PARTITION_WEBSITE_PREFIX+strings.ToLower(slug), _SORT_KEY_WEBSITE),
+		TableName: &c.db.TableName,
+	}
+	result, err := c.db.DbService.GetItem(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	pointer := &websiteSlug{}
+	if err = dynamodbattribute.UnmarshalMap(result.Item, pointer); err != nil {
+		return nil, nil, err
+	}
+	if pointer.EventId == "" {
+		return nil, nil, nil
+	}
+	website, err := c.get(pointer.EventId)
+	if err != nil || website == nil || !website.Published {
+		return nil, nil, err
+	}
+	if !c.authorize.Authorize(website.PublishedBy, website.EventId) {
+		return nil, nil, nil
+	}
+	event, err := c.eventService.Get(website.EventId)
+	if err != nil || event == nil || event.Status == app.EventArchived {
+		return nil, nil, err
+	}
+	return website, event, nil
+}
+
+func (c *WebsiteService) get(eventId string) (*app.EventWebsite, error) {
+	input := &dynamodb.GetItemInput{
+		Key:       c.withKey(map[string]*dynamodb.AttributeValue{}, eventId, _SORT_KEY_WEBSITE),
+		TableName: &c.db.TableName,
+	}
+	result, err := c.db.DbService.GetItem(input)
+	if err != nil {
+		return nil, err
+	}
+	website := &app.EventWebsite{}
+	if err = dynamodbattribute.UnmarshalMap(result.Item, website); err != nil {
+		return nil, err
+	}
+	if website.Slug == "" {
+		return nil, nil
+	}
+	return website, nil
+}
+
+func (c *WebsiteService) withKey(item map[string]*dynamodb.AttributeValue, partition, sortKey string) map[string]*dynamodb.AttributeValue {
+	item[c.db.PK_ID] = &dynamodb.AttributeValue{S: aws.String(partition)}
+	item[c.db.SORT_KEY] = &dynamodb.AttributeValue{S: aws.String(sortKey)}
+	return item
+}
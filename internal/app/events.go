@@ -25,12 +25,44 @@ type EventService interface {
 	ListOwners(id string) (*EventSharedEmails, error)
 	CreateOrUpdate(eventManager string, u *Event) (*Event, error)
 	CreateOwner(eventManager string, u *EventSharedEmails) (*EventSharedEmails, error)
+	Clone(eventManager string, clone *CloneEventRequest) (*Event, error)
 	Delete(eventManager, id string) error
+	// Summary returns the guest, task and expense counts of the event, nil if it does not exist
+	Summary(eventManager, id string) (*EventDashboard, error)
 }
 
 type EventActions interface {
-	// Filter based on the referenced function
-	SendPendingTasksNotifications() error
+	// SendPendingTasksNotifications notifies the owners of every active event, failures of an event or
+	// a recipient are reported and do not stop the run
+	SendPendingTasksNotifications() (*NotificationReport, error)
+	// SendDueNotifications notifies the owners of the active events whose reminder is due at now, see
+	// NotificationSchedule. It is called every few minutes by the scheduled run
+	SendDueNotifications(now time.Time) (*NotificationReport, error)
+}
+
+// NotificationReport : Sent, Failed and Suppressed count recipients, Skipped counts the events not
+// notified.
+type NotificationReport struct {
+	Events         []*EventNotificationReport `json:"events"`
+	Sent           int                        `json:"sent"`
+	Failed         int                        `json:"failed"`
+	Suppressed     int                        `json:"suppressed"`
+	Skipped        int                        `json:"skipped"`
+	TimeStartedOn  time.Time                  `json:"timeStartedOn"`
+	TimeFinishedOn time.Time                  `json:"timeFinishedOn"`
+}
+
+// EventNotificationReport : Reason explains why the event was skipped or failed, Errors has the ones
+// of every recipient that failed. Suppressed counts the recipients that disabled the notification.
+type EventNotificationReport struct {
+	EventId    string   `json:"eventId"`
+	EventName  string   `json:"eventName"`
+	Sent       int      `json:"sent"`
+	Failed     int      `json:"failed"`
+	Suppressed int      `json:"suppressed"`
+	Skipped    bool     `json:"isSkipped"`
+	Reason     string   `json:"reason"`
+	Errors     []string `json:"errors"`
 }
 
 type GuestService interface {
@@ -44,29 +76,51 @@ type GuestService interface {
 type TaskService interface {
 	Get(eventId, id string) (*Task, error)
 	List(eventId string) ([]*Task, error)
-	CreateOrUpdate(eventId string, u *Task) (*Task, error)
-	Delete(eventId, id string) error
+	CreateOrUpdate(eventManager, eventId string, u *Task) (*Task, error)
+	Delete(eventManager, eventId, id string) error
 }
 
 type ExpenseService interface {
 	Get(eventId, id string) (*ExpenseCategory, error)
 	List(eventId string) ([]*ExpenseCategory, error)
-	CreateOrUpdate(eventId string, u *ExpenseCategory) (*ExpenseCategory, error)
-	Delete(eventId, id string) error
+	CreateOrUpdate(eventManager, eventId string, u *ExpenseCategory) (*ExpenseCategory, error)
+	Delete(eventManager, eventId, id string) error
+	// Individual expenses (payments) of a category
+	GetItem(eventId, categoryId, id string) (*Expense, error)
+	ListItems(eventId, categoryId string) ([]*Expense, error)
+	CreateOrUpdateItem(eventManager, eventId, categoryId string, u *Expense) (*Expense, error)
+	DeleteItem(eventManager, eventId, categoryId, id string) error
+	Budget(eventId string) (*BudgetSummary, error)
+	// Unpaid scheduled payments due before until, including the overdue ones
+	UpcomingPayments(eventId string, until time.Time) ([]*UpcomingPayment, error)
+	// Settlement returns who owes whom across all the expenses of the event
+	Settlement(eventId string) (*Settlement, error)
 }
 
-// Event : Required Name , MainLocation, EventDay. An event has Guests ,Expenses and Tasks
+// Event : Required Name , MainLocation, EventDay. An event has Guests ,Expenses and Tasks. Budget is
+// in the base currency, see BudgetSummary. TimeZone is the IANA zone of the event (UTC by default), the
+// EventDay of AllDay events is a calendar date, see timezone.go. Status is the lifecycle state, see
+// lifecycle.go, State is the state at the time it is returned. NotificationSchedule sets when the pending
+// tasks are sent, TimeNotifiedOn is the last time they were and is only set by SendDueNotifications.
 type Event struct {
-	Id                  string    `json:"id"`
-	Name                string    `json:"name" validate:"required"`
-	MainLocation        string    `json:"mainLocation" validate:"required"`
-	EventDay            time.Time `json:"eventDay" validate:"required"`
-	Description         string    `json:"description"`
-	Guests              []*Guest  `json:"guests"`
-	NotificationEnabled bool      `json:"isNotificationEnabled"`
-	v                   *validator.Validate
-	TimeCreatedOn       time.Time `json:"timeCreatedOn"`
-	TimeUpdatedOn       time.Time `json:"timeUpdatedOn"`
+	Id                   string                `json:"id"`
+	Name                 string                `json:"name" validate:"required"`
+	MainLocation         string                `json:"mainLocation" validate:"required"`
+	EventDay             time.Time             `json:"eventDay" validate:"required"`
+	Description          string                `json:"description"`
+	Guests               []*Guest              `json:"guests"`
+	NotificationEnabled  bool                  `json:"isNotificationEnabled"`
+	NotificationSchedule *NotificationSchedule `json:"notificationSchedule"`
+	BaseCurrency         string                `json:"baseCurrency" validate:"omitempty,iso4217"`
+	Budget               Money                 `json:"budget" validate:"gte=0"`
+	TimeZone             string                `json:"timeZone" validate:"omitempty,timezone"`
+	AllDay               bool                  `json:"isAllDay"`
+	Status               string                `json:"status" validate:"omitempty,oneof=DRAFT PLANNING FINALIZED COMPLETED ARCHIVED CANCELLED"`
+	State                string                `json:"state" dynamodbav:"-"`
+	v                    *validator.Validate
+	TimeNotifiedOn       time.Time `json:"timeNotifiedOn"`
+	TimeCreatedOn        time.Time `json:"timeCreatedOn"`
+	TimeUpdatedOn        time.Time `json:"timeUpdatedOn"`
 }
 
 type EventSummary struct {
@@ -75,6 +129,10 @@ type EventSummary struct {
 	MainLocation        string    `json:"mainLocation" validate:"required"`
 	EventDay            time.Time `json:"eventDay" validate:"required"`
 	NotificationEnabled bool      `json:"isNotificationEnabled"`
+	TimeZone            string    `json:"timeZone"`
+	AllDay              bool      `json:"isAllDay"`
+	Status              string    `json:"status"`
+	State               string    `json:"state" dynamodbav:"-"`
 	TimeCreatedOn       time.Time `json:"timeCreatedOn"`
 }
 
@@ -88,68 +146,135 @@ type EventSharedEmails struct {
 	SharedEmails []string `json:"sharedEmails" validate:"required"`
 }
 
-// Guest : Required FirstName,LastName,Tentative,NumberOfSeats
+// Guest : Required FirstName,LastName,Tentative,NumberOfSeats. NumberOfSeats is the allocation set by
+// the owners, TimeRespondedOn and ConfirmedSeats are set when the guest answers through the event
+// website.
 type Guest struct {
-	Id             string `json:"id"`
-	FirstName      string `json:"firstName" validate:"required"`
-	LastName       string `json:"lastName" validate:"required"`
-	GuestOf        string `json:"guestOf"`
-	Email          string `json:"email"`
-	Phone          string `json:"phone"`
-	Tentative      bool   `json:"isTentative"`
-	Country        string `json:"country"`
-	State          string `json:"state"`
-	RequiresInvite bool   `json:"requiresInvite"`
-	NotAttending   bool   `json:"isNotAttending"`
-	NumberOfSeats  int    `json:"numberOfSeats" validate:"required"`
-	v              *validator.Validate
-	TimeCreatedOn  time.Time `json:"timeCreatedOn"`
-	TimeUpdatedOn  time.Time `json:"timeUpdatedOn"`
+	Id              string `json:"id"`
+	FirstName       string `json:"firstName" validate:"required"`
+	LastName        string `json:"lastName" validate:"required"`
+	GuestOf         string `json:"guestOf"`
+	Email           string `json:"email"`
+	Phone           string `json:"phone"`
+	Tentative       bool   `json:"isTentative"`
+	Country         string `json:"country"`
+	State           string `json:"state"`
+	RequiresInvite  bool   `json:"requiresInvite"`
+	NotAttending    bool   `json:"isNotAttending"`
+	NumberOfSeats   int    `json:"numberOfSeats" validate:"required"`
+	ConfirmedSeats  int    `json:"confirmedSeats" validate:"gte=0"`
+	v               *validator.Validate
+	TimeRespondedOn time.Time `json:"timeRespondedOn"`
+	TimeCreatedOn   time.Time `json:"timeCreatedOn"`
+	TimeUpdatedOn   time.Time `json:"timeUpdatedOn"`
+}
+
+// AttendingSeats returns the seats the guest confirmed, its NumberOfSeats if it did not answer.
+func (g *Guest) AttendingSeats() int {
+	if g.ConfirmedSeats > 0 && g.ConfirmedSeats < g.NumberOfSeats {
+		return g.ConfirmedSeats
+	}
+	return g.NumberOfSeats
 }
 
 type CopyGuestRequest struct {
 	FromEvent string `json:"fromEvent"`
 }
 
-type Task struct {
-	Id            string `json:"id"`
-	Name          string `json:"name" validate:"required"`
-	Status        string `json:"status" validate:"required"` // PENDING, DONE
+// CloneEventRequest : Required FromEvent, EventDay. Name is optional and defaults to the source event
+// name, IncludeOwners copies every shared email of the source event into the new one.
+type CloneEventRequest struct {
+	FromEvent     string    `json:"fromEvent" validate:"required"`
+	Name          string    `json:"name"`
+	EventDay      time.Time `json:"eventDay" validate:"required"`
+	IncludeOwners bool      `json:"includeOwners"`
 	v             *validator.Validate
-	TimeCreatedOn time.Time `json:"timeCreatedOn"`
-	TimeUpdatedOn time.Time `json:"timeUpdatedOn"`
 }
 
-// Expense representation , the Category MUST be unique per eventId
+// Task : Required Name, Status. A task is due either on DueDate or DaysBeforeEvent days before the
+// EventDay, Recurrence is an RRULE subset (see RecurrenceRule) anchored on the task due date.
+type Task struct {
+	Id              string    `json:"id"`
+	Name            string    `json:"name" validate:"required"`
+	Status          string    `json:"status" validate:"required"` // PENDING, DONE
+	DueDate         time.Time `json:"dueDate"`
+	DaysBeforeEvent *int      `json:"daysBeforeEvent"`
+	Recurrence      string    `json:"recurrence"`
+	// Id of the first task in the recurrence series and position of this task in it (starting at 1)
+	RecurrenceId string `json:"recurrenceId"`
+	Occurrence   int    `json:"occurrence"`
+	// Set once the next occurrence of a completed recurring task has been generated
+	NextOccurrenceId string `json:"nextOccurrenceId"`
+	VendorId         string `json:"vendorId"`
+	v                *validator.Validate
+	TimeCreatedOn    time.Time `json:"timeCreatedOn"`
+	TimeUpdatedOn    time.Time `json:"timeUpdatedOn"`
+}
+
+// Expense representation , the Category MUST be unique per eventId. Amounts of the category are in
+// Currency, the event base currency at the time the amounts were computed. AmountPaid is maintained by
+// the service as expenses are recorded, AmountTotal and AmountRemaining are computed server side, see
+// ComputeTotals. Expenses are stored as individual items and only returned when getting a category.
+// Limit is the optional budget of the category and Payments the deposits and installments agreed.
+// Split is how the owners share the costs of the category, equally when empty. PerGuest categories,
+// like catering, cost more with every guest.
 type ExpenseCategory struct {
-	Id              string     `json:"id"`
-	Category        string     `json:"category" validate:"required"`
-	AmountProjected float64    `json:"amountProjected"`
-	AmountPaid      float64    `json:"amountPaid"`
-	AmountTotal     float64    `json:"amountTotal"`
-	Expenses        []*Expense `json:"expenses"`
+	Id              string              `json:"id"`
+	Category        string              `json:"category" validate:"required"`
+	Currency        string              `json:"currency"`
+	AmountProjected Money               `json:"amountProjected" validate:"gte=0"`
+	AmountPaid      Money               `json:"amountPaid"`
+	Limit           Money               `json:"limit" validate:"gte=0"`
+	VendorId        string              `json:"vendorId"`
+	Payments        []*ScheduledPayment `json:"payments" validate:"max=50,dive"`
+	Split           []*CostShare        `json:"split" validate:"dive"`
+	PerGuest        bool                `json:"isPerGuest"`
+	AmountTotal     Money               `json:"amountTotal" dynamodbav:"-"`
+	AmountRemaining Money               `json:"amountRemaining" dynamodbav:"-"`
+	Expenses        []*Expense          `json:"expenses" dynamodbav:"-"`
 	v               *validator.Validate
 	TimeCreatedOn   time.Time `json:"timeCreatedOn"`
 	TimeUpdatedOn   time.Time `json:"timeUpdatedOn"`
 }
 
+// Expense : AmountPaid is in Currency (defaults to the event base currency), ExchangeRate is the
+// snapshot of the rate to the base currency captured when the expense was first recorded. VendorId is
+// the vendor paid, when empty it is the vendor of the category. PaidBy is the shared email that paid,
+// WhoPaid is free text kept for payers that are not owners.
 type Expense struct {
-	Id            string    `json:"id"`
-	WhoPaid       string    `json:"whoPaid" validate:"required"`
-	TimePaidOn    time.Time `json:"timePaidOn"`
-	AmountPaid    float64   `json:"amountPaid"`
-	v             *validator.Validate
-	TimeCreatedOn time.Time `json:"timeCreatedOn"`
-	TimeUpdatedOn time.Time `json:"timeUpdatedOn"`
+	Id             string    `json:"id"`
+	CategoryId     string    `json:"categoryId"`
+	VendorId       string    `json:"vendorId"`
+	WhoPaid        string    `json:"whoPaid" validate:"required_without=PaidBy"`
+	PaidBy         string    `json:"paidBy" validate:"omitempty,email"`
+	TimePaidOn     time.Time `json:"timePaidOn"`
+	AmountPaid     Money     `json:"amountPaid" validate:"gte=0"`
+	Currency       string    `json:"currency" validate:"omitempty,iso4217"`
+	ExchangeRate   float64   `json:"exchangeRate"`
+	AmountPaidBase Money     `json:"amountPaidBase"`
+	v              *validator.Validate
+	TimeCreatedOn  time.Time `json:"timeCreatedOn"`
+	TimeUpdatedOn  time.Time `json:"timeUpdatedOn"`
 }
 
 func (e *Event) Validate() error {
 	if e.v == nil {
 		e.v = validator.New()
 	}
+	if err := checkCurrency(e.BaseCurrency); err != nil {
+		return err
+	}
 	return e.v.Struct(e)
 }
 
+// Currency returns the event base currency
+func (e *Event) Currency() string {
+	if e.BaseCurrency == "" {
+		return DefaultCurrency
+	}
+	return strings.ToUpper(e.BaseCurrency)
+}
+
 func (e *Event) ToSummary() *EventSummary {
 	return &EventSummary{
 		Id:                  e.Id,
@@ -158,30 +283,96 @@ func (e *Event) ToSummary() *EventSummary {
 		EventDay:            e.EventDay,
 		TimeCreatedOn:       e.TimeCreatedOn,
 		NotificationEnabled: e.NotificationEnabled,
+		TimeZone:            e.TimeZone,
+		AllDay:              e.AllDay,
+		Status:              e.Status,
+		State:               e.State,
 	}
 }
 
+func (c *CloneEventRequest) Validate() error {
+	if c.v == nil {
+		c.v = validator.New()
+	}
+	return c.v.Struct(c)
+}
+
 func (e *ExpenseCategory) Validate() error {
 	if e.v == nil {
 		e.v = validator.New()
 	}
-	// Arbitrart number to avoid ExpenseCategory row growing large
-	if len(e.Expenses) > 80 {
-		return errors.New("trying to create more than 80 Expenses  . Not allowed")
+	return e.v.Struct(e)
+}
+
+// ApplyExchangeRates converts every expense in Expenses into base and computes the category totals
+// out of them, all the rates are captured again if the base currency changed.
+func (e *ExpenseCategory) ApplyExchangeRates(base string, rates ExchangeRateProvider) error {
+	base = strings.ToUpper(base)
+	rebase := e.Currency != "" && e.Currency != base
+	e.Currency = base
+	for _, expense := range e.Expenses {
+		if err := expense.ApplyExchangeRate(base, rates, rebase); err != nil {
+			return err
+		}
 	}
+	e.SumExpenses()
+	return nil
+}
 
-	for _, value := range e.Expenses {
-		if err := value.Validate(); err != nil {
+// SumExpenses computes AmountPaid out of Expenses using their captured exchange rate.
+func (e *ExpenseCategory) SumExpenses() {
+	e.AmountPaid = 0
+	for _, expense := range e.Expenses {
+		rate := expense.ExchangeRate
+		if rate == 0 {
+			rate = 1
+		}
+		expense.AmountPaidBase = expense.AmountPaid.Convert(rate)
+		e.AmountPaid += expense.AmountPaidBase
+	}
+	e.ComputeTotals()
+}
+
+// ComputeTotals computes the total , the projected amount or what was actually paid if it went over the
+// projection, and the remaining which is what is left to pay of the total.
+func (e *ExpenseCategory) ComputeTotals() {
+	e.AmountTotal = e.AmountProjected
+	if e.AmountPaid > e.AmountTotal {
+		e.AmountTotal = e.AmountPaid
+	}
+	e.AmountRemaining = e.AmountTotal - e.AmountPaid
+}
+
+// ApplyExchangeRate captures the rate from Currency to base, unless already captured, and computes
+// AmountPaidBase.
+func (e *Expense) ApplyExchangeRate(base string, rates ExchangeRateProvider, recapture bool) error {
+	base = strings.ToUpper(base)
+	if e.Currency == "" {
+		e.Currency = base
+	}
+	e.Currency = strings.ToUpper(e.Currency)
+	if e.ExchangeRate == 0 || recapture {
+		paidOn := e.TimePaidOn
+		if paidOn.IsZero() {
+			paidOn = time.Now()
+		}
+		rate, err := rates.Rate(e.Currency, base, paidOn)
+		if err != nil {
 			return err
 		}
+		e.ExchangeRate = rate
 	}
-	return e.v.Struct(e)
+	e.AmountPaidBase = e.AmountPaid.Convert(e.ExchangeRate)
+	return nil
 }
 
 func (e *Expense) Validate() error {
 	if e.v == nil {
 		e.v = validator.New()
 	}
+	if err := checkCurrency(e.Currency); err != nil {
+		return err
+	}
 	return e.v.Struct(e)
 }
 
@@ -189,9 +380,86 @@ func (t *Task) Validate() error {
 	if t.v == nil {
 		t.v = validator.New()
 	}
+	if t.DaysBeforeEvent != nil && *t.DaysBeforeEvent < 0 {
+		return errors.New("daysBeforeEvent can not be negative")
+	}
+	if t.Recurrence != "" {
+		if _, err := ParseRecurrence(t.Recurrence); err != nil {
+			return err
+		}
+		if t.DueDate.IsZero() && t.DaysBeforeEvent == nil {
+			return errors.New("a recurring task requires dueDate or daysBeforeEvent")
+		}
+	}
 	return t.v.Struct(t)
 }
 
+// ResolveDueDate returns the absolute due date of the task, tasks scheduled relative to the event
+// are due DaysBeforeEvent days before eventDay. Returns false if the task has no due date.
+func (t *Task) ResolveDueDate(eventDay time.Time) (time.Time, bool) {
+	if t.DaysBeforeEvent != nil && !eventDay.IsZero() {
+		return eventDay.AddDate(0, 0, -*t.DaysBeforeEvent), true
+	}
+	return t.DueDate, !t.DueDate.IsZero()
+}
+
+// NextOccurrence returns the PENDING task following this one in its recurrence series, nil if the
+// rule is exhausted or the next occurrence falls after eventDay.
+func (t *Task) NextOccurrence(eventDay time.Time) (*Task, error) {
+	if t.Recurrence == "" {
+		return nil, nil
+	}
+	rule, err := ParseRecurrence(t.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	due, ok := t.ResolveDueDate(eventDay)
+	if !ok {
+		return nil, nil
+	}
+	occurrence := t.Occurrence
+	if occurrence == 0 {
+		occurrence = 1
+	}
+	next := rule.Next(due)
+	if !rule.Allows(occurrence+1, next) || (!eventDay.IsZero() && next.After(eventDay)) {
+		return nil, nil
+	}
+	recurrenceId := t.RecurrenceId
+	if recurrenceId == "" {
+		recurrenceId = t.Id
+	}
+	return &Task{
+		Name:         t.Name,
+		Status:       "PENDING",
+		DueDate:      next,
+		Recurrence:   t.Recurrence,
+		RecurrenceId: recurrenceId,
+		Occurrence:   occurrence + 1,
+	}, nil
+}
+
+// UpcomingOccurrences returns the future occurrences, after the current one, of the PENDING recurring
+// tasks happening before until.
+func UpcomingOccurrences(tasks []*Task, eventDay, until time.Time) []*TaskOccurrence {
+	upcoming := []*TaskOccurrence{}
+	for _, task := range tasks {
+		if task.Status != "PENDING" {
+			continue
+		}
+		current := task
+		for {
+			next, err := current.NextOccurrence(eventDay)
+			if err != nil || next == nil || next.DueDate.After(until) {
+				break
+			}
+			upcoming = append(upcoming, &TaskOccurrence{Name: next.Name, DueDate: next.DueDate})
+			current = next
+		}
+	}
+	return upcoming
+}
+
 func (g *Guest) Validate() error {
 	if g.v == nil {
 		g.v = validator.New()
@@ -212,3 +480,23 @@ func GenerateRandomId() (string, error) {
 	}
 	return id.String(), nil
 }
+
+func NewNotificationReport(now time.Time) *NotificationReport {
+	return &NotificationReport{Events: []*EventNotificationReport{}, TimeStartedOn: now}
+}
+
+// Add appends the report of an event and adds it to the totals.
+func (r *NotificationReport) Add(event *EventNotificationReport) {
+	r.Events = append(r.Events, event)
+	r.Sent += event.Sent
+	r.Failed += event.Failed
+	r.Suppressed += event.Suppressed
+	if event.Skipped {
+		r.Skipped++
+	}
+}
+
+// Skip reports an event as not notified because of reason.
+func (r *NotificationReport) Skip(event *EventSummary, reason string) {
+	r.Add(&EventNotificationReport{EventId: event.Id, EventName: event.Name, Skipped: true, Reason: reason, Errors: []string{}})
+}
@@ -1,6 +1,9 @@
 package app
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestValidation(t *testing.T) {
 	e := &Event{
@@ -11,3 +14,16 @@ func TestValidation(t *testing.T) {
 		t.Error("Object is expected to be validated %", err)
 	}
 }
+
+func TestValidationCurrencies(t *testing.T) {
+	for currency, valid := range map[string]bool{"": true, "MXN": true, "EUR": true, "JPY": false, "KWD": false, "BHD": false} {
+		event := &Event{Name: "Wedding", MainLocation: "Puebla", EventDay: time.Now(), BaseCurrency: currency}
+		if err := event.Validate(); (err == nil) != valid {
+			t.Errorf("Expected base currency %q valid %t got %v", currency, valid, err)
+		}
+		expense := &Expense{WhoPaid: "Ana", Currency: currency}
+		if err := expense.Validate(); (err == nil) != valid {
+			t.Errorf("Expected expense currency %q valid %t got %v", currency, valid, err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// Lifecycle of an event, events without Status are PLANNING. Events not CANCELLED nor ARCHIVED are
+// COMPLETED once they happened without storing it, see CurrentState. ARCHIVED events are read-only and
+// left out of the default listing and of the scheduled jobs.
+const (
+	EventDraft     = "DRAFT"
+	EventPlanning  = "PLANNING"
+	EventFinalized = "FINALIZED"
+	EventCompleted = "COMPLETED"
+	EventArchived  = "ARCHIVED"
+	EventCancelled = "CANCELLED"
+)
+
+var (
+	ErrEventReadOnly     = errors.New("event is archived and read-only")
+	ErrInvalidTransition = errors.New("invalid transition")
+)
+
+// eventTransitions are the states every state can move to, an event is unarchived as COMPLETED
+var eventTransitions = map[string][]string{
+	EventDraft:     {EventPlanning, EventCancelled},
+	EventPlanning:  {EventDraft, EventFinalized, EventCompleted, EventCancelled},
+	EventFinalized: {EventPlanning, EventCompleted, EventCancelled},
+	EventCompleted: {EventPlanning, EventArchived},
+	EventCancelled: {EventPlanning, EventArchived},
+	EventArchived:  {EventCompleted},
+}
+
+// CurrentState returns the lifecycle state of the event at now.
+func (e *EventSummary) CurrentState(now time.Time) string {
+	switch e.Status {
+	case "", EventDraft, EventPlanning, EventFinalized:
+		if !e.IsUpcoming(now) {
+			return EventCompleted
+		}
+		if e.Status == "" {
+			return EventPlanning
+		}
+	}
+	return e.Status
+}
+
+// IsActive returns true if the event still has to happen, scheduled jobs only consider active events.
+func (e *EventSummary) IsActive(now time.Time) bool {
+	state := e.CurrentState(now)
+	return state == EventDraft || state == EventPlanning || state == EventFinalized
+}
+
+func (e *EventSummary) IsArchived() bool {
+	return e.Status == EventArchived
+}
+
+// WithState sets the State of the event at now.
+func (e *Event) WithState(now time.Time) *Event {
+	e.State = e.ToSummary().CurrentState(now)
+	return e
+}
+
+// WithState sets the State of the event at now.
+func (e *EventSummary) WithState(now time.Time) *EventSummary {
+	e.State = e.CurrentState(now)
+	return e
+}
+
+// Transition validates the change of u from previous at now, previous is nil for new events. A missing
+// Status keeps the one of previous and new events are PLANNING by default. Archived events can only
+// be unarchived.
+func (u *Event) Transition(previous *Event, now time.Time) error {
+	if previous == nil {
+		if u.Status == "" {
+			u.Status = EventPlanning
+		}
+		if u.Status != EventDraft && u.Status != EventPlanning {
+			return fmt.Errorf("%w, new events must be %s or %s", ErrInvalidTransition, EventDraft, EventPlanning)
+		}
+		return nil
+	}
+	if u.Status == "" {
+		u.Status = previous.Status
+	}
+	if previous.Status == EventArchived && u.Status == EventArchived {
+		return ErrEventReadOnly
+	}
+	from := previous.ToSummary().CurrentState(now)
+	if u.Status == previous.Status || u.Status == from {
+		return nil
+	}
+	if !slices.Contains(eventTransitions[from], u.Status) {
+		return fmt.Errorf("%w from %s to %s", ErrInvalidTransition, from, u.Status)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEventCompletesOnceItHappened(t *testing.T) {
+	now := time.Date(2023, 6, 10, 12, 0, 0, 0, time.UTC)
+	event := &EventSummary{EventDay: time.Date(2023, 6, 11, 0, 0, 0, 0, time.UTC), AllDay: true}
+	if state := event.CurrentState(now); state != EventPlanning {
+		t.Errorf("Expected events without status to be %s got %s", EventPlanning, state)
+	}
+	if !event.IsActive(now) {
+		t.Errorf("Expected the event to be active")
+	}
+	event.Status = EventFinalized
+	if state := event.CurrentState(now.AddDate(0, 0, 2)); state != EventCompleted {
+		t.Errorf("Expected %s got %s", EventCompleted, state)
+	}
+	// Cancelled and archived events are never completed
+	event.Status = EventCancelled
+	if state := event.CurrentState(now.AddDate(0, 0, 2)); state != EventCancelled {
+		t.Errorf("Expected %s got %s", EventCancelled, state)
+	}
+	if event.IsActive(now) {
+		t.Errorf("Expected cancelled events to be inactive")
+	}
+}
+
+func TestEventTransition(t *testing.T) {
+	now := time.Date(2023, 6, 10, 12, 0, 0, 0, time.UTC)
+	past := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	created := &Event{}
+	if err := created.Transition(nil, now); err != nil || created.Status != EventPlanning {
+		t.Errorf("Expected new events to be %s got %s %v", EventPlanning, created.Status, err)
+	}
+	if err := (&Event{Status: EventArchived}).Transition(nil, now); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected new events not to be archived got %v", err)
+	}
+	// The status is kept when missing, so the event still completes on its own
+	previous := &Event{Status: EventPlanning, EventDay: past}
+	updated := &Event{EventDay: past}
+	if err := updated.Transition(previous, now); err != nil || updated.Status != EventPlanning {
+		t.Errorf("Expected the status to be kept got %s %v", updated.Status, err)
+	}
+	// Past events are completed and can be archived
+	if err := (&Event{Status: EventArchived}).Transition(previous, now); err != nil {
+		t.Errorf("Expected completed events to be archived got %v", err)
+	}
+	previous.EventDay = now.AddDate(0, 1, 0)
+	if err := (&Event{Status: EventArchived}).Transition(previous, now); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected upcoming events not to be archived got %v", err)
+	}
+}
+
+func TestArchivedEventIsReadOnly(t *testing.T) {
+	now := time.Date(2023, 6, 10, 12, 0, 0, 0, time.UTC)
+	previous := &Event{Status: EventArchived, EventDay: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)}
+	if err := (&Event{Name: "Renamed"}).Transition(previous, now); !errors.Is(err, ErrEventReadOnly) {
+		t.Errorf("Expected %v got %v", ErrEventReadOnly, err)
+	}
+	if err := (&Event{Status: EventPlanning}).Transition(previous, now); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("Expected archived events to be only unarchived got %v", err)
+	}
+	if err := (&Event{Status: EventCompleted}).Transition(previous, now); err != nil {
+		t.Errorf("Expected archived events to be unarchived got %v", err)
+	}
+}
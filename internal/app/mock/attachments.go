@@ -0,0 +1,127 @@
+package mock
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+// AttachmentService keeps the metadata in memory and the content in the given storage, attached
+// entities are not checked.
+type AttachmentService struct {
+	db      map[string]map[string]*app.Attachment
+	storage app.BlobStorage
+	limits  *app.AttachmentLimits
+	lock    sync.RWMutex
+}
+
+func NewAttachmentService(storage app.BlobStorage, limits *app.AttachmentLimits) *AttachmentService {
+	return &AttachmentService{
+		db:      make(map[string]map[string]*app.Attachment),
+		storage: storage,
+		limits:  limits,
+	}
+}
+
+func (c *AttachmentService) Get(eventManager, eventId, id string) (*app.Attachment, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	value, exists := c.db[eventId][id]
+	if !exists {
+		return nil, nil
+	}
+	if value.Status == app.AttachmentUploaded {
+		url, err := c.storage.PresignDownload(value.Key, value.FileName, time.Minute)
+		if err != nil {
+			return nil, err
+		}
+		value.DownloadUrl = url
+	}
+	return value, nil
+}
+
+func (c *AttachmentService) List(eventManager, eventId, entityType, entityId string) ([]*app.Attachment, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	list := []*app.Attachment{}
+	for _, value := range c.db[eventId] {
+		if value.EntityType == strings.ToUpper(entityType) && value.EntityId == entityId {
+			list = append(list, value)
+		}
+	}
+	return list, nil
+}
+
+func (c *AttachmentService) Create(eventManager, eventId string, u *app.Attachment) (*app.Attachment, error) {
+	u.EntityType = strings.ToUpper(u.EntityType)
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	if err := c.limits.Check(u.ContentType, u.Size); err != nil {
+		return nil, err
+	}
+	id, err := app.GenerateRandomId()
+	if err != nil {
+		return nil, err
+	}
+	u.Id = id
+	u.Key = eventId + "/" + id
+	u.Status = app.AttachmentPending
+	u.UploadedBy = strings.ToUpper(eventManager)
+	u.TimeCreatedOn = time.Now()
+	u.TimeUpdatedOn = time.Now()
+	u.UploadUrl, err = c.storage.PresignUpload(u.Key, u.ContentType, u.Size, time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.db[eventId] == nil {
+		c.db[eventId] = make(map[string]*app.Attachment)
+	}
+	c.db[eventId][u.Id] = u
+	return u, nil
+}
+
+func (c *AttachmentService) Complete(eventManager, eventId, id string) (*app.Attachment, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	value, exists := c.db[eventId][id]
+	if !exists {
+		return nil, errors.New("attachment not found")
+	}
+	info, err := c.storage.Stat(value.Key)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.limits.Check(info.ContentType, info.Size); err != nil {
+		c.storage.Delete(value.Key)
+		return nil, err
+	}
+	value.Size = info.Size
+	value.Status = app.AttachmentUploaded
+	value.TimeUpdatedOn = time.Now()
+	return value, nil
+}
+
+func (c *AttachmentService) Delete(eventManager, eventId, id string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	value, exists := c.db[eventId][id]
+	if !exists {
+		return nil
+	}
+	if err := c.storage.Delete(value.Key); err != nil {
+		return err
+	}
+	delete(c.db[eventId], id)
+	return nil
+}
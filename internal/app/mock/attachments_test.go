@@ -0,0 +1,84 @@
+package mock
+
+import (
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+// upload writes content where a client would PUT it, the upload URL of the file storage
+func upload(t *testing.T, uploadUrl string, content []byte) {
+	location, err := url.Parse(uploadUrl)
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if err = os.WriteFile(location.Path, content, 0600); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+}
+
+func TestAttachmentUpload(t *testing.T) {
+	storage, err := app.NewFileBlobStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	service := NewAttachmentService(storage, app.DefaultAttachmentLimits)
+	receipt := []byte("%PDF-1.4 receipt")
+	attachment, err := service.Create("dummy", "event", &app.Attachment{EntityType: "vendor", EntityId: "VENDOR-1", FileName: "contract.pdf", ContentType: "application/pdf", Size: int64(len(receipt))})
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if attachment.Status != app.AttachmentPending || !strings.HasPrefix(attachment.UploadUrl, "file://") {
+		t.Fatalf("Unexpected attachment %+v", attachment)
+	}
+	upload(t, attachment.UploadUrl, receipt)
+
+	attachment, err = service.Complete("dummy", "event", attachment.Id)
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if attachment.Status != app.AttachmentUploaded {
+		t.Errorf("Expected %s got %s", app.AttachmentUploaded, attachment.Status)
+	}
+	attachments, _ := service.List("dummy", "event", app.AttachOnVendor, "VENDOR-1")
+	if len(attachments) != 1 {
+		t.Fatalf("Expected 1 attachment got %d", len(attachments))
+	}
+	if err = service.Delete("dummy", "event", attachment.Id); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if _, err = storage.Stat(attachment.Key); err != app.ErrBlobNotFound {
+		t.Errorf("Expected the content to be deleted got %v", err)
+	}
+}
+
+func TestAttachmentLimits(t *testing.T) {
+	storage, err := app.NewFileBlobStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	service := NewAttachmentService(storage, &app.AttachmentLimits{MaxSize: 10, ContentTypes: []string{"application/pdf"}})
+	_, err = service.Create("dummy", "event", &app.Attachment{EntityType: "VENDOR", EntityId: "VENDOR-1", FileName: "big.pdf", ContentType: "application/pdf", Size: 11})
+	if err == nil {
+		t.Error("Expected error for an attachment over the size limit")
+	}
+	_, err = service.Create("dummy", "event", &app.Attachment{EntityType: "EXPENSE", EntityId: "1", FileName: "receipt.pdf", ContentType: "application/pdf", Size: 5})
+	if err == nil {
+		t.Error("Expected error for an expense attachment without category")
+	}
+	// Declared as a PDF but the uploaded content is not
+	attachment, err := service.Create("dummy", "event", &app.Attachment{EntityType: "VENDOR", EntityId: "VENDOR-1", FileName: "fake.pdf", ContentType: "application/pdf", Size: 5})
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	upload(t, attachment.UploadUrl, []byte("hello"))
+	if _, err = service.Complete("dummy", "event", attachment.Id); err == nil {
+		t.Error("Expected error for content not matching the allowed types")
+	}
+	if _, err = storage.Stat(attachment.Key); err != app.ErrBlobNotFound {
+		t.Errorf("Expected the rejected content to be deleted got %v", err)
+	}
+}
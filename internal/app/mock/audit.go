@@ -0,0 +1,49 @@
+package mock
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+type AuditService struct {
+	db   map[string][]*app.AuditEntry
+	lock sync.RWMutex
+}
+
+func NewAuditService() *AuditService {
+	return &AuditService{
+		db: make(map[string][]*app.AuditEntry),
+	}
+}
+
+func (c *AuditService) Record(entry *app.AuditEntry) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry.Id = strconv.Itoa(len(c.db[entry.EventId]))
+	c.db[entry.EventId] = append(c.db[entry.EventId], entry)
+	return nil
+}
+
+// List returns the activity newest first, NextToken is the offset of the next page.
+func (c *AuditService) List(eventManager, eventId string, page *app.PageRequest) (*app.AuditPage, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	entries := c.db[eventId]
+	offset, _ := strconv.Atoi(page.NextToken)
+	limit := page.Limit
+	if limit <= 0 {
+		limit = 25
+	}
+	auditPage := &app.AuditPage{Items: []*app.AuditEntry{}}
+	for i := len(entries) - 1 - offset; i >= 0 && len(auditPage.Items) < limit; i-- {
+		auditPage.Items = append(auditPage.Items, entries[i])
+	}
+	if offset+len(auditPage.Items) < len(entries) {
+		auditPage.NextToken = strconv.Itoa(offset + len(auditPage.Items))
+	}
+	return auditPage, nil
+}
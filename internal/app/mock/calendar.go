@@ -0,0 +1,113 @@
+package mock
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+// CalendarService keeps the feed tokens in memory, invitations are rendered but never sent.
+type CalendarService struct {
+	feeds           map[string]*app.CalendarFeed
+	eventService    app.EventService
+	guestService    app.GuestService
+	taskService     app.TaskService
+	scheduleService app.ScheduleService
+	lock            sync.RWMutex
+}
+
+func NewCalendarService(event app.EventService, guest app.GuestService, task app.TaskService, schedule app.ScheduleService) *CalendarService {
+	return &CalendarService{
+		feeds:           make(map[string]*app.CalendarFeed),
+		eventService:    event,
+		guestService:    guest,
+		taskService:     task,
+		scheduleService: schedule,
+	}
+}
+
+func (c *CalendarService) GetFeed(eventManager, eventId string) (*app.CalendarFeed, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	eventManager = strings.ToUpper(eventManager)
+	for _, feed := range c.feeds {
+		if feed.Owner == eventManager && feed.EventId == eventId {
+			return feed, nil
+		}
+	}
+	return c.create(eventManager, eventId)
+}
+
+func (c *CalendarService) RotateFeed(eventManager, eventId string) (*app.CalendarFeed, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	eventManager = strings.ToUpper(eventManager)
+	for token, feed := range c.feeds {
+		if feed.Owner == eventManager && feed.EventId == eventId {
+			delete(c.feeds, token)
+		}
+	}
+	return c.create(eventManager, eventId)
+}
+
+func (c *CalendarService) Render(token string) (*app.Calendar, error) {
+	c.lock.RLock()
+	feed, exists := c.feeds[token]
+	c.lock.RUnlock()
+	if !exists {
+		return nil, nil
+	}
+	eventIds := []string{feed.EventId}
+	if feed.EventId == "" {
+		events, err := c.eventService.List(feed.Owner)
+		if err != nil {
+			return nil, err
+		}
+		eventIds = []string{}
+		for _, event := range events {
+			eventIds = append(eventIds, event.Id)
+		}
+	}
+	calendar := app.NewCalendar("Events", app.CalendarPublish, time.Now())
+	for _, eventId := range eventIds {
+		event, err := c.eventService.Get(eventId)
+		if err != nil {
+			return nil, err
+		}
+		if event == nil {
+			continue
+		}
+		schedule, err := c.scheduleService.List(feed.Owner, eventId)
+		if err != nil {
+			return nil, err
+		}
+		tasks, err := c.taskService.List(eventId)
+		if err != nil {
+			return nil, err
+		}
+		calendar.AddEventWithSchedule(event, schedule, tasks)
+	}
+	return calendar, nil
+}
+
+func (c *CalendarService) SendInvitations(eventManager, eventId string, guestIds []string) (int, error) {
+	guests, err := c.guestService.List(eventManager, eventId)
+	if err != nil {
+		return 0, err
+	}
+	return len(app.InvitedGuests(guests, guestIds)), nil
+}
+
+func (c *CalendarService) create(eventManager, eventId string) (*app.CalendarFeed, error) {
+	token, err := app.GenerateRandomId()
+	if err != nil {
+		return nil, err
+	}
+	feed := &app.CalendarFeed{Token: token, Owner: eventManager, EventId: eventId, TimeCreatedOn: time.Now()}
+	c.feeds[token] = feed
+	return feed, nil
+}
@@ -0,0 +1,124 @@
+package mock
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+// CampaignService keeps the campaigns in memory, no email is sent and every recipient is marked as sent.
+type CampaignService struct {
+	db           map[string]map[string]*app.Campaign
+	guestService app.GuestService
+	lock         sync.RWMutex
+}
+
+func NewCampaignService(guest app.GuestService) *CampaignService {
+	return &CampaignService{
+		db:           make(map[string]map[string]*app.Campaign),
+		guestService: guest,
+	}
+}
+
+func (c *CampaignService) Get(eventManager, eventId, id string) (*app.Campaign, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	value, exists := c.db[eventId][id]
+	if !exists {
+		return nil, nil
+	}
+	return value, nil
+}
+
+func (c *CampaignService) List(eventManager, eventId string) ([]*app.Campaign, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	list := []*app.Campaign{}
+	for _, value := range c.db[eventId] {
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+func (c *CampaignService) CreateOrUpdate(eventManager, eventId string, u *app.Campaign) (*app.Campaign, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if u.Id == "" {
+		id, err := app.GenerateRandomId()
+		if err != nil {
+			return nil, err
+		}
+		u.Id = "CAMPAIGN-" + id
+		u.TimeCreatedOn = time.Now()
+	} else {
+		value, exists := c.db[eventId][u.Id]
+		if !exists {
+			return nil, errors.New("campaign not found")
+		}
+		if value.Status != app.CampaignDraft {
+			return nil, app.ErrCampaignSent
+		}
+		u.TimeCreatedOn = value.TimeCreatedOn
+	}
+	u.Status = app.CampaignDraft
+	u.Recipients = []*app.CampaignRecipient{}
+	u.TimeUpdatedOn = time.Now()
+	if c.db[eventId] == nil {
+		c.db[eventId] = make(map[string]*app.Campaign)
+	}
+	c.db[eventId][u.Id] = u
+	return u, nil
+}
+
+func (c *CampaignService) Delete(eventManager, eventId, id string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.db[eventId], id)
+	return nil
+}
+
+func (c *CampaignService) Send(eventManager, eventId, id string) (*app.Campaign, error) {
+	guests, err := c.guestService.List(eventManager, eventId)
+	if err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	value, exists := c.db[eventId][id]
+	if !exists {
+		return nil, errors.New("campaign not found")
+	}
+	if value.Status != app.CampaignDraft {
+		return nil, app.ErrCampaignSent
+	}
+	value.Resolve(guests)
+	value.Deliver(func(recipient *app.CampaignRecipient) error { return nil })
+	value.TimeUpdatedOn = time.Now()
+	return value, nil
+}
+
+func (c *CampaignService) Resend(eventManager, eventId, id string) (*app.Campaign, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	value, exists := c.db[eventId][id]
+	if !exists {
+		return nil, errors.New("campaign not found")
+	}
+	if err := value.Resendable(time.Now()); err != nil {
+		return nil, err
+	}
+	value.Deliver(func(recipient *app.CampaignRecipient) error { return nil })
+	value.TimeUpdatedOn = time.Now()
+	return value, nil
+}
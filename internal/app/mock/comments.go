@@ -0,0 +1,93 @@
+package mock
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+type CommentService struct {
+	db   map[string]map[string]*app.Comment
+	lock sync.RWMutex
+}
+
+func NewCommentService() *CommentService {
+	return &CommentService{
+		db: make(map[string]map[string]*app.Comment),
+	}
+}
+
+func (c *CommentService) Get(eventId, id string) (*app.Comment, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	value, exists := c.db[eventId][id]
+	if !exists {
+		return nil, nil
+	}
+	return value, nil
+}
+
+func (c *CommentService) List(eventId, entityId string) ([]*app.Comment, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	list := []*app.Comment{}
+	for _, value := range c.db[eventId] {
+		if value.EntityId == entityId {
+			list = append(list, value)
+		}
+	}
+	return list, nil
+}
+
+func (c *CommentService) CreateOrUpdate(author, eventId string, u *app.Comment) (*app.Comment, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	author = strings.ToUpper(author)
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	if c.db[eventId] == nil {
+		c.db[eventId] = make(map[string]*app.Comment)
+	}
+	value, exists := c.db[eventId][u.Id]
+	if exists && value.Author != author {
+		return nil, errors.New("unauthorized")
+	}
+	if exists {
+		u.Author = value.Author
+		u.TimeCreatedOn = value.TimeCreatedOn
+	} else {
+		id, err := app.GenerateRandomId()
+		if err != nil {
+			return nil, err
+		}
+		u.Id = id
+		u.Author = author
+		u.TimeCreatedOn = time.Now()
+	}
+	u.Mentions = app.ParseMentions(u.Body)
+	u.TimeUpdatedOn = time.Now()
+	c.db[eventId][u.Id] = u
+	return u, nil
+}
+
+func (c *CommentService) Delete(author, eventId, id string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	value, exists := c.db[eventId][id]
+	if !exists {
+		return errors.New("object comment does not exist")
+	}
+	if value.Author != strings.ToUpper(author) {
+		return errors.New("unauthorized")
+	}
+	delete(c.db[eventId], id)
+	return nil
+}
@@ -1,43 +1,140 @@
 package mock
 
 import (
+	"errors"
 	"log"
 	"time"
 
 	"github.com/craguilar/event-management-service/internal/app"
 )
 
-// EventService represents a Dynamo DB implementation of internal.EventService.
+// EventActions sends the pending tasks of the events in memory through the notifier configured,
+// there are no payments as expenses are not kept in memory.
 type EventActions struct {
-	eventService *EventService
-	taskService  *TaskService
+	eventService        *EventService
+	taskService         *TaskService
+	preferencesService  app.PreferencesService
+	notificationService app.Notifier
 }
 
-func NewEventActionsService(event *EventService, task *TaskService) *EventActions {
+func NewEventActionsService(event *EventService, task *TaskService, preferences app.PreferencesService, notification app.Notifier) *EventActions {
 
 	return &EventActions{
-		eventService: event,
-		taskService:  task,
+		eventService:        event,
+		taskService:         task,
+		preferencesService:  preferences,
+		notificationService: notification,
 	}
 }
 
-func (c *EventActions) SendPendingTasksNotifications() error {
+// How far ahead recurring task occurrences are listed in notifications
+const _UPCOMING_OCCURRENCES_WINDOW = 30 * 24 * time.Hour
+
+func (c *EventActions) SendPendingTasksNotifications() (*app.NotificationReport, error) {
+	now := time.Now()
+	report := app.NewNotificationReport(now)
 	events, err := c.eventService.ListBy(func(event *app.EventSummary) bool {
-		return event.EventDay.After(time.Now())
+		return event.IsActive(now)
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	for _, event := range events {
+		report.Add(c.notifyPendingTasks(event, now))
+	}
+	report.TimeFinishedOn = time.Now()
+	return report, nil
+}
+
+// SendDueNotifications marks the events due at now as notified and then notifies them.
+func (c *EventActions) SendDueNotifications(now time.Time) (*app.NotificationReport, error) {
+	c.eventService.lock.Lock()
+	due := []*app.EventSummary{}
+	for _, event := range c.eventService.db {
+		summary := event.ToSummary().WithState(now)
+		if !event.NotificationEnabled || !summary.IsActive(now) || !event.ReminderSchedule().IsDue(summary, event.TimeNotifiedOn, now) {
+			continue
+		}
+		event.TimeNotifiedOn = now
+		due = append(due, summary)
+	}
+	c.eventService.lock.Unlock()
 
-		tasks, err := c.taskService.List(event.Id)
+	report := app.NewNotificationReport(now)
+	for _, event := range due {
+		report.Add(c.notifyPendingTasks(event, now))
+	}
+	report.TimeFinishedOn = now
+	return report, nil
+}
+
+// notifyPendingTasks sends the pending tasks of event at now to every owner, errors are reported.
+func (c *EventActions) notifyPendingTasks(event *app.EventSummary, now time.Time) *app.EventNotificationReport {
+	report := &app.EventNotificationReport{EventId: event.Id, EventName: event.Name, Errors: []string{}}
+	if !event.NotificationEnabled {
+		report.Skipped, report.Reason = true, "notifications disabled"
+		return report
+	}
+	tasks, err := c.taskService.List(event.Id)
+	if err != nil {
+		report.Reason = err.Error()
+		return report
+	}
+	pending := make([]*app.Task, 0)
+	for _, task := range tasks {
+		if task.Status == "PENDING" {
+			if due, ok := task.ResolveDueDate(event.EventDay); ok {
+				task.DueDate = due
+			}
+			pending = append(pending, task)
+		}
+	}
+	if len(pending) == 0 {
+		report.Skipped, report.Reason = true, "nothing pending"
+		return report
+	}
+	upcoming := app.UpcomingOccurrences(pending, event.EventDay, now.Add(_UPCOMING_OCCURRENCES_WINDOW))
+	template, err := app.TemplatePendingTasksNotifications(event, pending, upcoming, nil)
+	if err != nil {
+		report.Reason = err.Error()
+		return report
+	}
+	owners, err := c.eventService.ListOwners(event.Id)
+	if err != nil || owners == nil {
+		report.Reason = "owners not found"
+		return report
+	}
+	for _, toEmail := range owners.SharedEmails {
+		preferences, err := c.preferencesService.Get(toEmail)
 		if err != nil {
-			return err
+			log.Printf("WARN: Failed to get the preferences of %s with error %s", toEmail, err)
+			report.Failed++
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+		if !preferences.Allows(app.NotificationPendingTasks, event.Id) {
+			report.Suppressed++
+			continue
 		}
-		for _, task := range tasks {
-			log.Printf("%v", task)
-			// Do something
+		err = c.notificationService.Notify(&app.Notification{
+			Key:       app.NotificationKey(app.NotificationPendingTasks, event.Id, toEmail, preferences.DigestPeriod(now)),
+			Type:      app.NotificationPendingTasks,
+			EventId:   event.Id,
+			Recipient: toEmail,
+			Subject:   "Pending Tasks for " + event.Name,
+			Body:      template.String(),
+		})
+		if errors.Is(err, app.ErrNotificationSuppressed) {
+			report.Suppressed++
+			continue
+		}
+		if err != nil {
+			log.Printf("WARN: Failed to send notification for %s with error %s", toEmail, err)
+			report.Failed++
+			report.Errors = append(report.Errors, err.Error())
+			continue
 		}
+		report.Sent++
 	}
-	return nil
+	return report
 }
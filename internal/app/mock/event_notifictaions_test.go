@@ -0,0 +1,53 @@
+package mock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+func TestSendDueNotificationsOncePerReminder(t *testing.T) {
+	events := NewEventService()
+	now := time.Now().UTC()
+	event, err := events.CreateOrUpdate("ana@example.com", &app.Event{
+		Name:                 "Wedding",
+		MainLocation:         "Puebla",
+		EventDay:             now.AddDate(0, 2, 0),
+		NotificationEnabled:  true,
+		NotificationSchedule: &app.NotificationSchedule{Frequency: app.ReminderDaily, Hour: 9},
+	})
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	tasks := &TaskService{}
+	if _, err = tasks.CreateOrUpdate("ana@example.com", event.Id, &app.Task{Name: "Book DJ", Status: "PENDING"}); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	notifier := app.NewMemoryNotifier()
+	actions := NewEventActionsService(events, tasks, NewPreferencesService(nil), notifier)
+	nine := time.Date(now.Year(), now.Month(), now.Day(), 9, 0, 0, 0, time.UTC)
+	sent := 0
+	for _, tick := range []struct {
+		now      time.Time
+		expected int
+	}{
+		{nine.Add(time.Minute), 1},
+		{nine.Add(16 * time.Minute), 0},
+		{nine.AddDate(0, 0, 1).Add(-time.Minute), 0},
+		{nine.AddDate(0, 0, 1).Add(time.Minute), 1},
+	} {
+		report, err := actions.SendDueNotifications(tick.now)
+		if err != nil {
+			t.Fatalf("Test failed with error %s", err)
+		}
+		if len(report.Events) != tick.expected {
+			t.Errorf("Expected %d events due at %s got %d", tick.expected, tick.now, len(report.Events))
+		}
+		sent += tick.expected
+		// Every reminder due is emailed to the owner through the notifier
+		if notifications := notifier.Notifications(); len(notifications) != sent || notifications[sent-1].Recipient != "ANA@EXAMPLE.COM" {
+			t.Errorf("Expected %d notifications to the owner got %v", sent, notifications)
+		}
+	}
+}
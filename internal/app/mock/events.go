@@ -3,20 +3,25 @@ package mock
 import (
 	"errors"
 	"log"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/craguilar/event-management-service/internal/app"
+	"golang.org/x/exp/slices"
 )
 
+// EventService keeps the events in memory, the owners of every event by their upper case email.
 type EventService struct {
-	db   map[string]*app.Event
-	lock sync.RWMutex
+	db     map[string]*app.Event
+	owners map[string][]string
+	lock   sync.RWMutex
 }
 
 func NewEventService() *EventService {
 	return &EventService{
-		db: make(map[string]*app.Event),
+		db:     make(map[string]*app.Event),
+		owners: make(map[string][]string),
 	}
 }
 
@@ -28,7 +33,7 @@ func (c *EventService) Get(id string) (*app.Event, error) {
 	if !exists {
 		return nil, nil
 	}
-	return value, nil
+	return value.WithState(time.Now()), nil
 }
 
 func (c *EventService) List(user string) ([]*app.EventSummary, error) {
@@ -37,7 +42,7 @@ func (c *EventService) List(user string) ([]*app.EventSummary, error) {
 
 	list := []*app.EventSummary{}
 	for _, value := range c.db {
-		list = append(list, &app.EventSummary{Id: value.Id, Name: value.Name, MainLocation: value.MainLocation, EventDay: value.EventDay, TimeCreatedOn: value.TimeCreatedOn})
+		list = append(list, value.ToSummary().WithState(time.Now()))
 	}
 	return list, nil
 }
@@ -48,7 +53,10 @@ func (c *EventService) ListBy(filter func(*app.EventSummary) bool) ([]*app.Event
 
 	list := []*app.EventSummary{}
 	for _, value := range c.db {
-		summary := &app.EventSummary{Id: value.Id, Name: value.Name, MainLocation: value.MainLocation, EventDay: value.EventDay, TimeCreatedOn: value.TimeCreatedOn}
+		summary := value.ToSummary().WithState(time.Now())
+		if summary.IsArchived() {
+			continue
+		}
 		if filter(summary) {
 			list = append(list, summary)
 		}
@@ -69,14 +77,20 @@ func (c *EventService) CreateOrUpdate(eventManager string, u *app.Event) (*app.E
 	if u.Id == "" {
 		u.Id = app.GenerateId(u.Name)
 	}
-	_, exists := c.db[u.Id]
+	previous, exists := c.db[u.Id]
+	if err = u.Transition(previous, time.Now()); err != nil {
+		return nil, err
+	}
+	u.WithState(time.Now())
 	if !exists {
 		u.TimeCreatedOn = time.Now()
 		u.TimeUpdatedOn = time.Now()
 		c.db[u.Id] = u
+		c.owners[u.Id] = []string{strings.ToUpper(eventManager)}
 		return u, nil
 	}
 	// If it exists update the time stamp and return, we should be more strict about validations but dah!
+	u.TimeNotifiedOn = previous.TimeNotifiedOn
 	u.TimeUpdatedOn = time.Now()
 	c.db[u.Id] = u
 	log.Printf("Created event %s", u.Id)
@@ -84,21 +98,85 @@ func (c *EventService) CreateOrUpdate(eventManager string, u *app.Event) (*app.E
 }
 
 func (c *EventService) ListOwners(id string) (*app.EventSharedEmails, error) {
-	return nil, errors.New("not implemented")
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if _, exists := c.db[id]; !exists {
+		return nil, nil
+	}
+	return &app.EventSharedEmails{EventId: id, SharedEmails: slices.Clone(c.owners[id])}, nil
 }
 
 func (c *EventService) CreateOwner(eventManager string, u *app.EventSharedEmails) (*app.EventSharedEmails, error) {
-	return nil, errors.New("not implemented")
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, exists := c.db[u.EventId]; !exists {
+		return nil, errors.New("event not found")
+	}
+	for _, email := range u.SharedEmails {
+		if email = strings.ToUpper(email); !slices.Contains(c.owners[u.EventId], email) {
+			c.owners[u.EventId] = append(c.owners[u.EventId], email)
+		}
+	}
+	return &app.EventSharedEmails{EventId: u.EventId, SharedEmails: slices.Clone(c.owners[u.EventId])}, nil
+}
+
+func (c *EventService) Clone(eventManager string, clone *app.CloneEventRequest) (*app.Event, error) {
+	if err := clone.Validate(); err != nil {
+		return nil, err
+	}
+	source, err := c.Get(clone.FromEvent)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, errors.New("object event does not exist")
+	}
+	event := &app.Event{
+		Name:                 source.Name,
+		MainLocation:         source.MainLocation,
+		EventDay:             clone.EventDay,
+		Description:          source.Description,
+		NotificationEnabled:  source.NotificationEnabled,
+		NotificationSchedule: source.NotificationSchedule,
+		TimeZone:             source.TimeZone,
+		AllDay:               source.AllDay,
+		Status:               app.EventPlanning,
+	}
+	if clone.Name != "" {
+		event.Name = clone.Name
+	}
+	// Avoid the name based Id, a clone keeping the name would override the source event
+	event.Id, err = app.GenerateRandomId()
+	if err != nil {
+		return nil, err
+	}
+	for _, guest := range source.Guests {
+		copied := *guest
+		event.Guests = append(event.Guests, &copied)
+	}
+	return c.CreateOrUpdate(eventManager, event)
+}
+
+// Summary only counts the guests embedded in the event, tasks and expenses live in other services.
+func (c *EventService) Summary(eventManager, id string) (*app.EventDashboard, error) {
+	event, err := c.Get(id)
+	if err != nil || event == nil {
+		return nil, err
+	}
+	return app.NewEventDashboard(event, event.Guests, []*app.Task{}, []*app.ExpenseCategory{}, time.Now()), nil
 }
 
 func (c *EventService) Delete(eventManager, id string) error {
-	c.lock.RLock()
-	defer c.lock.RUnlock()
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
 	_, exists := c.db[id]
 	if !exists {
 		return errors.New("object event does not exist")
 	}
 	delete(c.db, id)
+	delete(c.owners, id)
 	return nil
 }
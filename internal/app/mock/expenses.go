@@ -2,6 +2,7 @@ package mock
 
 import (
 	"errors"
+	"time"
 
 	"github.com/craguilar/event-management-service/internal/app"
 )
@@ -17,11 +18,39 @@ func (c *ExpenseService) List(eventId string) ([]*app.ExpenseCategory, error) {
 	return nil, errors.New("not implemented")
 
 }
-func (c *ExpenseService) CreateOrUpdate(eventId string, u *app.ExpenseCategory) (*app.ExpenseCategory, error) {
+func (c *ExpenseService) CreateOrUpdate(eventManager, eventId string, u *app.ExpenseCategory) (*app.ExpenseCategory, error) {
 
 	return nil, errors.New("not implemented")
 }
-func (c *ExpenseService) Delete(eventId, id string) error {
+func (c *ExpenseService) Delete(eventManager, eventId, id string) error {
 	return errors.New("not implemented")
 
 }
+
+func (c *ExpenseService) GetItem(eventId, categoryId, id string) (*app.Expense, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *ExpenseService) ListItems(eventId, categoryId string) ([]*app.Expense, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *ExpenseService) CreateOrUpdateItem(eventManager, eventId, categoryId string, u *app.Expense) (*app.Expense, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *ExpenseService) DeleteItem(eventManager, eventId, categoryId, id string) error {
+	return errors.New("not implemented")
+}
+
+func (c *ExpenseService) Budget(eventId string) (*app.BudgetSummary, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *ExpenseService) UpcomingPayments(eventId string, until time.Time) ([]*app.UpcomingPayment, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *ExpenseService) Settlement(eventId string) (*app.Settlement, error) {
+	return nil, errors.New("not implemented")
+}
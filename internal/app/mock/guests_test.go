@@ -121,3 +121,43 @@ func TestDelete(t *testing.T) {
 		t.Fatalf("A list guest of size 0 is expected")
 	}
 }
+
+func TestCloneEventCopiesGuests(t *testing.T) {
+	eventService := NewEventService()
+
+	event := &app.Event{Name: "My Birthday", MainLocation: "Golden Gate Park", EventDay: time.Now()}
+	event, err := eventService.CreateOrUpdate("dummy", event)
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	guestService := NewGuestService(eventService)
+	guest := &app.Guest{FirstName: "Mickey", LastName: "Mouse", Tentative: false, NumberOfSeats: 2}
+	_, err = guestService.CreateOrUpdate("dummy", event.Id, guest)
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	// Clone keeping the same name
+	nextYear := event.EventDay.AddDate(1, 0, 0)
+	cloned, err := eventService.Clone("dummy", &app.CloneEventRequest{FromEvent: event.Id, EventDay: nextYear})
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	// Assertion
+	if cloned.Id == event.Id {
+		t.Fatalf("A new event Id is expected")
+	}
+	if !cloned.EventDay.Equal(nextYear) || cloned.Name != event.Name {
+		t.Fatalf("Cloned event fields do not match %v", cloned)
+	}
+	guests, err := guestService.List("dummy", cloned.Id)
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if len(guests) != 1 || guests[0].NumberOfSeats != 2 {
+		t.Fatalf("A list guest of size 1 is expected")
+	}
+	source, _ := eventService.Get(event.Id)
+	if len(source.Guests) != 1 {
+		t.Fatalf("Source event guests must not change")
+	}
+}
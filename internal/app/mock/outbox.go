@@ -0,0 +1,108 @@
+package mock
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/craguilar/event-management-service/internal/app"
+	"golang.org/x/exp/slices"
+)
+
+// OutboxService keeps the messages in memory and sends them through the given channel.
+type OutboxService struct {
+	db      map[string]*app.OutboxMessage
+	channel app.Notifier
+	admins  []string
+	lock    sync.Mutex
+}
+
+func NewOutboxService(channel app.Notifier, admins []string) *OutboxService {
+	return &OutboxService{
+		db:      make(map[string]*app.OutboxMessage),
+		channel: channel,
+		admins:  admins,
+	}
+}
+
+func (c *OutboxService) Notify(notification *app.Notification) error {
+	message, err := app.NewOutboxMessage(notification, time.Now())
+	if err != nil {
+		return err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, exists := c.db[message.Id]; exists {
+		log.Printf("Notification %s already in the outbox", message.Id)
+		return nil
+	}
+	c.db[message.Id] = message
+	if !message.IsDue(time.Now()) {
+		return nil
+	}
+	return message.Deliver(c.channel, time.Now())
+}
+
+func (c *OutboxService) Dispatch(now time.Time) (*app.DispatchReport, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	report := &app.DispatchReport{}
+	for _, message := range c.db {
+		if !message.IsDue(now) {
+			continue
+		}
+		message.Deliver(c.channel, now)
+		report.Count(message)
+	}
+	return report, nil
+}
+
+func (c *OutboxService) Get(admin, id string) (*app.OutboxMessage, error) {
+	if !slices.Contains(c.admins, strings.ToUpper(admin)) {
+		return nil, errors.New("unauthorized")
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.db[id], nil
+}
+
+func (c *OutboxService) List(admin, status string) ([]*app.OutboxMessage, error) {
+	if !slices.Contains(c.admins, strings.ToUpper(admin)) {
+		return nil, errors.New("unauthorized")
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	list := []*app.OutboxMessage{}
+	for _, message := range c.db {
+		if status == "" || strings.EqualFold(message.Status, status) {
+			list = append(list, message)
+		}
+	}
+	slices.SortFunc(list, func(a, b *app.OutboxMessage) bool {
+		return a.TimeCreatedOn.After(b.TimeCreatedOn)
+	})
+	return list, nil
+}
+
+func (c *OutboxService) Replay(admin, id string) (*app.OutboxMessage, error) {
+	if !slices.Contains(c.admins, strings.ToUpper(admin)) {
+		return nil, errors.New("unauthorized")
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	message, exists := c.db[id]
+	if !exists {
+		return nil, nil
+	}
+	if err := message.Replay(time.Now()); err != nil {
+		return nil, err
+	}
+	message.Deliver(c.channel, time.Now())
+	return message, nil
+}
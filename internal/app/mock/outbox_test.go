@@ -0,0 +1,68 @@
+package mock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+// unavailableNotifier fails every notification with a temporary error until available
+type unavailableNotifier struct {
+	available bool
+	sent      int
+}
+
+func (n *unavailableNotifier) Notify(notification *app.Notification) error {
+	if !n.available {
+		return &app.NotificationError{Recipient: notification.Recipient, Kind: app.ErrNotificationUnavailable, Err: app.ErrNotificationUnavailable}
+	}
+	n.sent++
+	return nil
+}
+
+func TestOutboxSendsEveryKeyOnce(t *testing.T) {
+	channel := &unavailableNotifier{available: true}
+	outbox := NewOutboxService(channel, nil)
+	for i := 0; i < 3; i++ {
+		if err := outbox.Notify(&app.Notification{Key: "PENDING_TASKS#1", Recipient: "ana@example.com"}); err != nil {
+			t.Fatalf("Test failed with error %s", err)
+		}
+	}
+	if channel.sent != 1 {
+		t.Errorf("Expected a single notification got %d", channel.sent)
+	}
+}
+
+func TestOutboxDispatchRetriesAndReplays(t *testing.T) {
+	channel := &unavailableNotifier{}
+	outbox := NewOutboxService(channel, []string{"ADMIN@EXAMPLE.COM"})
+	if err := outbox.Notify(&app.Notification{Key: "KEY", Recipient: "ana@example.com"}); err == nil {
+		t.Fatal("Expected the error of the first attempt")
+	}
+	// Not due until the backoff is over
+	report, _ := outbox.Dispatch(time.Now())
+	if report.Failed != 0 {
+		t.Errorf("Expected nothing due got %v", report)
+	}
+	now := time.Now()
+	for attempt := 2; attempt <= app.OutboxMaxAttempts; attempt++ {
+		now = now.Add(app.OutboxBackoff(attempt - 1))
+		report, _ = outbox.Dispatch(now)
+	}
+	if report.Dead != 1 {
+		t.Errorf("Expected a dead message got %v", report)
+	}
+	if _, err := outbox.List("ana@example.com", ""); err == nil {
+		t.Error("Expected only admins to list the outbox")
+	}
+	dead, err := outbox.List("admin@example.com", app.OutboxDead)
+	if err != nil || len(dead) != 1 {
+		t.Fatalf("Expected the dead message got %v %v", dead, err)
+	}
+	channel.available = true
+	message, err := outbox.Replay("admin@example.com", "KEY")
+	if err != nil || message.Status != app.OutboxSent || channel.sent != 1 {
+		t.Errorf("Expected the replayed message sent got %v %v", message, err)
+	}
+}
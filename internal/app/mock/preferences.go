@@ -0,0 +1,74 @@
+package mock
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+// PreferencesService keeps the preferences in memory by email.
+type PreferencesService struct {
+	db    map[string]*app.NotificationPreferences
+	links *app.UnsubscribeLinks
+	lock  sync.RWMutex
+}
+
+func NewPreferencesService(links *app.UnsubscribeLinks) *PreferencesService {
+	return &PreferencesService{
+		db:    make(map[string]*app.NotificationPreferences),
+		links: links,
+	}
+}
+
+func (c *PreferencesService) Get(user string) (*app.NotificationPreferences, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	value, exists := c.db[strings.ToLower(user)]
+	if !exists {
+		return app.DefaultPreferences(user), nil
+	}
+	return value, nil
+}
+
+func (c *PreferencesService) CreateOrUpdate(user string, u *app.NotificationPreferences) (*app.NotificationPreferences, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	u.Email = strings.ToLower(user)
+	if u.Digest == "" {
+		u.Digest = app.DigestDaily
+	}
+	if u.DisabledTypes == nil {
+		u.DisabledTypes = []string{}
+	}
+	if u.MutedEventIds == nil {
+		u.MutedEventIds = []string{}
+	}
+	u.TimeUpdatedOn = time.Now()
+	c.db[u.Email] = u
+	return u, nil
+}
+
+func (c *PreferencesService) Unsubscribe(token string) error {
+	email, notificationType, err := c.links.Verify(token)
+	if err != nil {
+		return err
+	}
+	preferences, err := c.Get(email)
+	if err != nil {
+		return err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	preferences.Unsubscribe(notificationType)
+	preferences.TimeUpdatedOn = time.Now()
+	c.db[preferences.Email] = preferences
+	return nil
+}
@@ -0,0 +1,48 @@
+package mock
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+func TestUnsubscribe(t *testing.T) {
+	links, _ := app.NewUnsubscribeLinks("0123456789abcdef", "")
+	preferences := NewPreferencesService(links)
+	if err := preferences.Unsubscribe("invalid"); !errors.Is(err, app.ErrInvalidUnsubscribe) {
+		t.Errorf("Expected invalid tokens to fail got %v", err)
+	}
+	if err := preferences.Unsubscribe(links.Token("Ana@Example.com", app.NotificationBudgetAlert)); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	value, _ := preferences.Get("ana@example.com")
+	if value.Allows(app.NotificationBudgetAlert, "1") || !value.Allows(app.NotificationMention, "1") {
+		t.Errorf("Expected only budget alerts to be disabled got %v", value.DisabledTypes)
+	}
+}
+
+func TestQuietHoursHoldNotifications(t *testing.T) {
+	links, _ := app.NewUnsubscribeLinks("0123456789abcdef", "")
+	preferences := NewPreferencesService(links)
+	// Quiet the whole day but the next hour
+	next := time.Now().UTC().Add(time.Hour).Hour()
+	_, err := preferences.CreateOrUpdate("ana@example.com", &app.NotificationPreferences{QuietHours: &app.QuietHours{Start: (next + 1) % 24, End: next}})
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	channel := &unavailableNotifier{available: true}
+	outbox := NewOutboxService(channel, nil)
+	notifier := app.NewPreferencesNotifier(preferences, links, outbox)
+	if err = notifier.Notify(&app.Notification{Key: "KEY", Type: app.NotificationMention, Recipient: "ana@example.com"}); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if channel.sent != 0 {
+		t.Error("Expected the notification to be held during quiet hours")
+	}
+	report, _ := outbox.Dispatch(time.Now().Add(2 * time.Hour))
+	if report.Sent != 1 || channel.sent != 1 {
+		t.Errorf("Expected the notification to be sent after quiet hours got %v", report)
+	}
+}
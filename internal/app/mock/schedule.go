@@ -0,0 +1,133 @@
+package mock
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/craguilar/event-management-service/internal/app"
+	"golang.org/x/exp/slices"
+)
+
+// ScheduleService keeps the schedule of every event in memory, guests come from the given service.
+type ScheduleService struct {
+	db           map[string]map[string]*app.ScheduleItem
+	guestService app.GuestService
+	lock         sync.RWMutex
+}
+
+func NewScheduleService(guest app.GuestService) *ScheduleService {
+	return &ScheduleService{
+		db:           make(map[string]map[string]*app.ScheduleItem),
+		guestService: guest,
+	}
+}
+
+func (c *ScheduleService) Get(eventManager, eventId, id string) (*app.ScheduleItem, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	value, exists := c.db[eventId][id]
+	if !exists {
+		return nil, nil
+	}
+	guests, err := c.guestService.List(eventManager, eventId)
+	if err != nil {
+		return nil, err
+	}
+	value.ComputeHeadcount(guests)
+	return value, nil
+}
+
+func (c *ScheduleService) List(eventManager, eventId string) ([]*app.ScheduleItem, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	guests, err := c.guestService.List(eventManager, eventId)
+	if err != nil {
+		return nil, err
+	}
+	list := []*app.ScheduleItem{}
+	for _, value := range c.db[eventId] {
+		value.ComputeHeadcount(guests)
+		list = append(list, value)
+	}
+	app.SortSchedule(list)
+	return list, nil
+}
+
+func (c *ScheduleService) CreateOrUpdate(eventManager, eventId string, u *app.ScheduleItem) (*app.ScheduleItem, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	guests, err := c.guestService.List(eventManager, eventId)
+	if err != nil {
+		return nil, err
+	}
+	if err = u.CheckGuests(guests); err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	u.Responses = []*app.ScheduleRsvp{}
+	if u.Id == "" {
+		id, err := app.GenerateRandomId()
+		if err != nil {
+			return nil, err
+		}
+		u.Id = "SCHEDULE-" + id
+		u.TimeCreatedOn = time.Now()
+	} else {
+		value, exists := c.db[eventId][u.Id]
+		if !exists {
+			return nil, errors.New("schedule item not found")
+		}
+		u.TimeCreatedOn = value.TimeCreatedOn
+		for _, rsvp := range value.Responses {
+			i := slices.IndexFunc(guests, func(guest *app.Guest) bool { return guest.Id == rsvp.GuestId })
+			if i >= 0 && u.Invites(guests[i]) {
+				u.Responses = append(u.Responses, rsvp)
+			}
+		}
+	}
+	u.TimeUpdatedOn = time.Now()
+	if c.db[eventId] == nil {
+		c.db[eventId] = make(map[string]*app.ScheduleItem)
+	}
+	c.db[eventId][u.Id] = u
+	u.ComputeHeadcount(guests)
+	return u, nil
+}
+
+func (c *ScheduleService) Delete(eventManager, eventId, id string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.db[eventId], id)
+	return nil
+}
+
+func (c *ScheduleService) Respond(eventManager, eventId, id string, rsvp *app.ScheduleRsvp) (*app.ScheduleItem, error) {
+	guests, err := c.guestService.List(eventManager, eventId)
+	if err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	value, exists := c.db[eventId][id]
+	if !exists {
+		return nil, errors.New("schedule item not found")
+	}
+	i := slices.IndexFunc(guests, func(guest *app.Guest) bool { return guest.Id == rsvp.GuestId })
+	if i < 0 {
+		return nil, errors.New("guest not found")
+	}
+	if err = value.SetResponse(guests[i], rsvp); err != nil {
+		return nil, err
+	}
+	value.TimeUpdatedOn = time.Now()
+	value.ComputeHeadcount(guests)
+	return value, nil
+}
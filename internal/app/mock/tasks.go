@@ -2,29 +2,78 @@ package mock
 
 import (
 	"errors"
+	"sync"
+	"time"
 
 	"github.com/craguilar/event-management-service/internal/app"
 )
 
+// TaskService keeps the tasks in memory by event, the zero value is ready to use.
 type TaskService struct {
+	db   map[string]map[string]*app.Task
+	lock sync.RWMutex
 }
 
 func (c *TaskService) Get(eventId, id string) (*app.Task, error) {
-	return nil, errors.New("not implemented")
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	value, exists := c.db[eventId][id]
+	if !exists {
+		return nil, nil
+	}
+	return value, nil
 }
 
 func (c *TaskService) List(eventId string) ([]*app.Task, error) {
-	return nil, errors.New("not implemented")
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	list := []*app.Task{}
+	for _, value := range c.db[eventId] {
+		list = append(list, value)
+	}
+	return list, nil
 }
 
 func (c *TaskService) SendNotifications(eventId string) error {
 	return errors.New("not implemented")
 }
 
-func (c *TaskService) CreateOrUpdate(eventId string, u *app.Task) (*app.Task, error) {
-	return nil, errors.New("not implemented")
+func (c *TaskService) CreateOrUpdate(eventManager, eventId string, u *app.Task) (*app.Task, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if u.Id == "" {
+		id, err := app.GenerateRandomId()
+		if err != nil {
+			return nil, err
+		}
+		u.Id = id
+	}
+	if c.db == nil {
+		c.db = make(map[string]map[string]*app.Task)
+	}
+	if c.db[eventId] == nil {
+		c.db[eventId] = make(map[string]*app.Task)
+	}
+	if value, exists := c.db[eventId][u.Id]; exists {
+		u.TimeCreatedOn = value.TimeCreatedOn
+		u.TimeUpdatedOn = time.Now()
+	} else {
+		u.TimeCreatedOn = time.Now()
+	}
+	c.db[eventId][u.Id] = u
+	return u, nil
 }
 
-func (c *TaskService) Delete(eventId, id string) error {
-	return errors.New("not implemented")
+func (c *TaskService) Delete(eventManager, eventId, id string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.db[eventId], id)
+	return nil
 }
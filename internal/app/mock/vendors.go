@@ -0,0 +1,117 @@
+package mock
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+// VendorService keeps the vendors of every event and the shared vendors of every user in memory,
+// the summary is computed out of the given expense and task services.
+type VendorService struct {
+	db             map[string]map[string]*app.Vendor
+	expenseService app.ExpenseService
+	taskService    app.TaskService
+	lock           sync.RWMutex
+}
+
+func NewVendorService(expense app.ExpenseService, task app.TaskService) *VendorService {
+	return &VendorService{
+		db:             make(map[string]map[string]*app.Vendor),
+		expenseService: expense,
+		taskService:    task,
+	}
+}
+
+func (c *VendorService) Get(eventManager, eventId, id string) (*app.Vendor, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	value, exists := c.db[c.partition(eventManager, eventId, id)][id]
+	if !exists {
+		return nil, nil
+	}
+	return value, nil
+}
+
+func (c *VendorService) List(eventManager, eventId string) ([]*app.Vendor, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	list := []*app.Vendor{}
+	for _, value := range c.db[eventId] {
+		list = append(list, value)
+	}
+	for _, value := range c.db[strings.ToUpper(eventManager)] {
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+func (c *VendorService) CreateOrUpdate(eventManager, eventId string, u *app.Vendor) (*app.Vendor, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if u.Id == "" {
+		id, err := app.GenerateRandomId()
+		if err != nil {
+			return nil, err
+		}
+		u.Id = "VENDOR-" + id
+		if u.Shared {
+			u.Id = "SHARED_VENDOR-" + id
+		}
+		u.TimeCreatedOn = time.Now()
+	}
+	partition := c.partition(eventManager, eventId, u.Id)
+	value, exists := c.db[partition][u.Id]
+	if exists {
+		u.TimeCreatedOn = value.TimeCreatedOn
+	} else if u.TimeCreatedOn.IsZero() {
+		return nil, errors.New("vendor not found")
+	}
+	u.Shared = strings.HasPrefix(u.Id, "SHARED_VENDOR-")
+	u.TimeUpdatedOn = time.Now()
+	if c.db[partition] == nil {
+		c.db[partition] = make(map[string]*app.Vendor)
+	}
+	c.db[partition][u.Id] = u
+	return u, nil
+}
+
+func (c *VendorService) Delete(eventManager, eventId, id string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.db[c.partition(eventManager, eventId, id)], id)
+	return nil
+}
+
+func (c *VendorService) Summary(eventManager, eventId string) ([]*app.VendorSummary, error) {
+	vendors, err := c.List(eventManager, eventId)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := c.expenseService.List(eventId)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := c.taskService.List(eventId)
+	if err != nil {
+		return nil, err
+	}
+	return app.NewVendorSummaries(vendors, categories, tasks), nil
+}
+
+func (c *VendorService) partition(eventManager, eventId, id string) string {
+	if strings.HasPrefix(id, "SHARED_VENDOR-") {
+		return strings.ToUpper(eventManager)
+	}
+	return eventId
+}
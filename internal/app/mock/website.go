@@ -0,0 +1,156 @@
+package mock
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+// WebsiteService keeps the websites in memory by event Id.
+type WebsiteService struct {
+	db              map[string]*app.EventWebsite
+	eventService    app.EventService
+	guestService    app.GuestService
+	scheduleService app.ScheduleService
+	limiter         app.RateLimiter
+	lock            sync.RWMutex
+}
+
+func NewWebsiteService(event app.EventService, guest app.GuestService, schedule app.ScheduleService, limiter app.RateLimiter) *WebsiteService {
+	return &WebsiteService{
+		db:              make(map[string]*app.EventWebsite),
+		eventService:    event,
+		guestService:    guest,
+		scheduleService: schedule,
+		limiter:         limiter,
+	}
+}
+
+func (c *WebsiteService) Get(eventManager, eventId string) (*app.EventWebsite, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.db[eventId], nil
+}
+
+func (c *WebsiteService) CreateOrUpdate(eventManager, eventId string, u *app.EventWebsite) (*app.EventWebsite, error) {
+	u.Slug = strings.ToLower(strings.TrimSpace(u.Slug))
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for id, website := range c.db {
+		if id != eventId && website.Slug == u.Slug {
+			return nil, errors.New("slug already in use")
+		}
+	}
+	u.EventId = eventId
+	u.PublishedBy = strings.ToUpper(eventManager)
+	u.TimeCreatedOn = time.Now()
+	if previous, exists := c.db[eventId]; exists {
+		u.TimeCreatedOn = previous.TimeCreatedOn
+	}
+	u.TimeUpdatedOn = time.Now()
+	c.db[eventId] = u
+	return u, nil
+}
+
+func (c *WebsiteService) GetPublic(slug string) (*app.PublicEvent, error) {
+	website, event, err := c.published(slug)
+	if err != nil || website == nil {
+		return nil, err
+	}
+	schedule, err := c.scheduleService.List(website.PublishedBy, event.Id)
+	if err != nil {
+		return nil, err
+	}
+	return app.NewPublicEvent(website, event, schedule, time.Now()), nil
+}
+
+func (c *WebsiteService) Respond(slug, source string, rsvp *app.PublicRsvp) (*app.PublicRsvpResult, error) {
+	if err := rsvp.Validate(); err != nil {
+		return nil, err
+	}
+	allowed, err := c.limiter.Allow("RSVP#"+slug+"#"+source, app.RsvpSourceRateLimit)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, app.ErrRateLimited
+	}
+	website, event, err := c.published(slug)
+	if err != nil || website == nil {
+		return nil, err
+	}
+	if !website.IsRsvpOpen(event, time.Now()) {
+		return nil, app.ErrRsvpClosed
+	}
+	guests, err := c.guestService.List(website.PublishedBy, event.Id)
+	if err != nil {
+		return nil, err
+	}
+	guest := app.FindGuestByEmail(guests, rsvp.Email)
+	if guest == nil {
+		return nil, app.ErrGuestNotFound
+	}
+	schedule, err := c.scheduleService.List(website.PublishedBy, event.Id)
+	if err != nil {
+		return nil, err
+	}
+	invited, err := rsvp.Apply(guest, schedule)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = c.guestService.CreateOrUpdate(website.PublishedBy, event.Id, guest); err != nil {
+		return nil, err
+	}
+	for _, item := range invited {
+		answer := &app.ScheduleRsvp{GuestId: guest.Id, Attending: rsvp.Attending, Seats: rsvp.Seats}
+		if _, err = c.scheduleService.Respond(website.PublishedBy, event.Id, item.Id, answer); err != nil {
+			return nil, err
+		}
+	}
+	return &app.PublicRsvpResult{FirstName: guest.FirstName, Attending: rsvp.Attending, Seats: rsvp.Seats}, nil
+}
+
+func (c *WebsiteService) published(slug string) (*app.EventWebsite, *app.Event, error) {
+	c.lock.RLock()
+	var website *app.EventWebsite
+	for _, value := range c.db {
+		if value.Slug == strings.ToLower(slug) && value.Published {
+			website = value
+		}
+	}
+	c.lock.RUnlock()
+	if website == nil {
+		return nil, nil, nil
+	}
+	event, err := c.eventService.Get(website.EventId)
+	if err != nil || event == nil || event.Status == app.EventArchived {
+		return nil, nil, err
+	}
+	return website, event, nil
+}
+
+// RateLimiter counts the calls in memory, windows are never cleaned up.
+type RateLimiter struct {
+	counts map[string]int
+	lock   sync.Mutex
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{counts: make(map[string]int)}
+}
+
+func (c *RateLimiter) Allow(key string, limit app.RateLimit) (bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key = key + "#" + time.Now().Truncate(limit.Window).String()
+	c.counts[key]++
+	return c.counts[key] <= limit.Limit, nil
+}
@@ -0,0 +1,54 @@
+package mock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/craguilar/event-management-service/internal/app"
+)
+
+func TestWebsiteRsvp(t *testing.T) {
+	eventService := NewEventService()
+	event, err := eventService.CreateOrUpdate("dummy", &app.Event{Name: "Wedding", MainLocation: "Puebla", EventDay: time.Now().AddDate(0, 1, 0)})
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	guestService := NewGuestService(eventService)
+	_, err = guestService.CreateOrUpdate("dummy", event.Id, &app.Guest{FirstName: "Ana", LastName: "Lopez", Email: "ana@example.com", NumberOfSeats: 2})
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	websiteService := NewWebsiteService(eventService, guestService, NewScheduleService(guestService), NewRateLimiter())
+	_, err = websiteService.CreateOrUpdate("dummy", event.Id, &app.EventWebsite{Slug: "ana-and-luis", RsvpEnabled: true})
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	// Not published yet
+	if page, _ := websiteService.GetPublic("ana-and-luis"); page != nil {
+		t.Errorf("Expected unpublished websites not to be public")
+	}
+	_, err = websiteService.CreateOrUpdate("dummy", event.Id, &app.EventWebsite{Slug: "ana-and-luis", RsvpEnabled: true, Published: true})
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if page, err := websiteService.GetPublic("ana-and-luis"); err != nil || page == nil || page.Name != "Wedding" {
+		t.Fatalf("Expected the public page got %v %v", page, err)
+	}
+	result, err := websiteService.Respond("ana-and-luis", "127.0.0.1", &app.PublicRsvp{Email: "ana@example.com", Attending: false})
+	if err != nil || result.FirstName != "Ana" {
+		t.Fatalf("Expected the rsvp to be recorded got %v %v", result, err)
+	}
+	guests, _ := guestService.List("dummy", event.Id)
+	if !guests[0].NotAttending {
+		t.Errorf("Expected the guest not to attend")
+	}
+	if _, err = websiteService.Respond("ana-and-luis", "127.0.0.1", &app.PublicRsvp{Email: "luis@example.com"}); err != app.ErrGuestNotFound {
+		t.Errorf("Expected %v got %v", app.ErrGuestNotFound, err)
+	}
+	for i := 0; i < app.RsvpSourceRateLimit.Limit; i++ {
+		_, err = websiteService.Respond("ana-and-luis", "127.0.0.1", &app.PublicRsvp{Email: "ana@example.com", Attending: true})
+	}
+	if err != app.ErrRateLimited {
+		t.Errorf("Expected %v got %v", app.ErrRateLimited, err)
+	}
+}
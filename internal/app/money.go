@@ -0,0 +1,121 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Number of minor units in a major unit, every supported currency has 2 decimals
+const minorUnits = 100
+
+// Money is an amount in minor units (cents) so arithmetic is exact. It is represented as a decimal
+// number with 2 decimals both in JSON and in DynamoDB, values with more decimals (like the float64 sums
+// stored before Money existed) are rounded half away from zero.
+type Money int64
+
+// decimalPattern matches decimal amounts, big.Rat alone would accept fractions like 1/3 as well
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d{1,3})?$`)
+
+// ParseMoney parses a decimal amount exactly, exponents are accepted.
+func ParseMoney(value string) (Money, error) {
+	value = strings.TrimSpace(value)
+	if !decimalPattern.MatchString(value) {
+		return 0, fmt.Errorf("invalid amount %s", value)
+	}
+	amount, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return 0, fmt.Errorf("invalid amount %s", value)
+	}
+	return roundRat(amount.Mul(amount, big.NewRat(minorUnits, 1)))
+}
+
+// roundRat rounds to the nearest integer, half away from zero.
+func roundRat(r *big.Rat) (Money, error) {
+	quotient, remainder := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	// |2 * remainder| >= denominator means the fraction is at least half
+	if remainder.Mul(remainder, big.NewInt(2)).CmpAbs(r.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(r.Sign())))
+	}
+	if !quotient.IsInt64() {
+		return 0, fmt.Errorf("amount %s out of range", r.FloatString(2))
+	}
+	return Money(quotient.Int64()), nil
+}
+
+// NewMoney converts a float amount rounding to the closest minor unit, only meant for non exact inputs
+// like exchange rates results.
+func NewMoney(amount float64) Money {
+	value, _ := ParseMoney(strconv.FormatFloat(amount, 'f', -1, 64))
+	return value
+}
+
+// Convert multiplies the amount by rate rounding to the closest minor unit.
+func (m Money) Convert(rate float64) Money {
+	r := new(big.Rat).SetFloat64(rate)
+	if r == nil {
+		return 0
+	}
+	value, _ := roundRat(r.Mul(r, big.NewRat(int64(m), 1)))
+	return value
+}
+
+func (m Money) String() string {
+	sign := ""
+	value := uint64(m)
+	if m < 0 {
+		sign = "-"
+		value = -value
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, value/minorUnits, value%minorUnits)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts both numbers and strings.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "null" || value == "" {
+		*m = 0
+		return nil
+	}
+	// Let json reject anything that is not a number
+	if data[0] != '"' {
+		var number json.Number
+		if err := json.Unmarshal(data, &number); err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseMoney(value)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m Money) MarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
+	av.N = aws.String(m.String())
+	return nil
+}
+
+func (m *Money) UnmarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
+	if av.N == nil {
+		*m = 0
+		return nil
+	}
+	parsed, err := ParseMoney(*av.N)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/quick"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+func TestParseMoney(t *testing.T) {
+	cases := map[string]Money{
+		"0":                   0,
+		"12":                  1200,
+		"12.3":                1230,
+		"-12.34":              -1234,
+		"0.005":               1,
+		"-0.005":              -1,
+		"0.0049":              0,
+		"0.30000000000000004": 30,
+		"1e3":                 100000,
+	}
+	for value, expected := range cases {
+		parsed, err := ParseMoney(value)
+		if err != nil {
+			t.Fatalf("Test failed with error %s", err)
+		}
+		if parsed != expected {
+			t.Errorf("Expected %s to be %d got %d", value, expected, parsed)
+		}
+	}
+	for _, value := range []string{"12,5", "1/3", "0x10", "1e", "1_000", "Inf"} {
+		if _, err := ParseMoney(value); err == nil {
+			t.Errorf("Expected error for invalid amount %s", value)
+		}
+	}
+}
+
+// Formatting and parsing back MUST return the same amount
+func TestMoneyRoundTripProperty(t *testing.T) {
+	property := func(cents int64) bool {
+		parsed, err := ParseMoney(Money(cents).String())
+		return err == nil && parsed == Money(cents)
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// Adding amounts parsed from their decimal representation MUST never drift, unlike float64
+func TestMoneySumProperty(t *testing.T) {
+	property := func(amounts []int32) bool {
+		var sum Money
+		var expected int64
+		for _, cents := range amounts {
+			parsed, err := ParseMoney(Money(cents).String())
+			if err != nil {
+				return false
+			}
+			sum += parsed
+			expected += int64(cents)
+		}
+		return sum == Money(expected)
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// Rounding a third decimal is half away from zero
+func TestMoneyRoundingProperty(t *testing.T) {
+	property := func(cents int32, thousandth uint8) bool {
+		digit := int64(thousandth % 10)
+		value := Money(cents).String() + string(rune('0'+digit))
+		parsed, err := ParseMoney(value)
+		if err != nil {
+			return false
+		}
+		expected := Money(cents)
+		if digit >= 5 {
+			if cents < 0 {
+				expected--
+			} else {
+				expected++
+			}
+		}
+		return parsed == expected
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMoneyConvert(t *testing.T) {
+	if converted := Money(170000).Convert(1 / 17.0); converted != 10000 {
+		t.Errorf("Expected 100.00 got %s", converted)
+	}
+	if converted := Money(1).Convert(0.5); converted != 1 {
+		t.Errorf("Expected half a cent to round up got %s", converted)
+	}
+}
+
+func TestMoneySerialization(t *testing.T) {
+	var category ExpenseCategory
+	err := json.Unmarshal([]byte(`{"category": "Venue", "amountProjected": 1500.10, "amountPaid": "0.1"}`), &category)
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if category.AmountProjected != 150010 || category.AmountPaid != 10 {
+		t.Errorf("Unexpected amounts %d %d", category.AmountProjected, category.AmountPaid)
+	}
+	data, err := json.Marshal(Money(-5))
+	if err != nil || string(data) != "-0.05" {
+		t.Errorf("Unexpected JSON %s", data)
+	}
+	if err := json.Unmarshal([]byte(`{"amountPaid": true}`), &category); err == nil {
+		t.Error("Expected error for a non numeric amount")
+	}
+	// Legacy float64 values stored in dynamo are rounded
+	var legacy Money
+	if err := legacy.UnmarshalDynamoDBAttributeValue(&dynamodb.AttributeValue{N: &[]string{"19.999999999999996"}[0]}); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if legacy != 2000 {
+		t.Errorf("Expected 20.00 got %s", legacy)
+	}
+}
@@ -1,9 +1,26 @@
 package app
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ses"
 )
 
@@ -11,22 +28,138 @@ const (
 	CharSet = "UTF-8"
 )
 
-type EmailConfig struct {
-	SesService *ses.SES
+// Sender used unless NotifierConfig.Source is set
+const _EMAIL_SOURCE = "app@notifications.cmymesh.com"
+
+// EmailAttachment : ContentType may include parameters, like the method of a text/calendar invite.
+type EmailAttachment struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// Kinds of NotificationError, a rejected notification fails again if retried while an unavailable
+// channel may accept it later.
+var (
+	ErrNotificationRejected    = errors.New("notification rejected")
+	ErrNotificationUnavailable = errors.New("notification channel unavailable")
+)
+
+// NotificationError is returned by every Notifier, errors.Is matches its Kind.
+type NotificationError struct {
+	Recipient string
+	Kind      error
+	Err       error
+}
+
+func (e *NotificationError) Error() string {
+	return fmt.Sprintf("%s for %s: %s", e.Kind, e.Recipient, e.Err)
+}
+
+func (e *NotificationError) Unwrap() error {
+	return e.Err
+}
+
+func (e *NotificationError) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Temporary returns true if the notification may succeed if retried.
+func (e *NotificationError) Temporary() bool {
+	return e.Kind == ErrNotificationUnavailable
+}
+
+func notificationError(recipient string, temporary bool, err error) error {
+	kind := ErrNotificationRejected
+	if temporary {
+		kind = ErrNotificationUnavailable
+	}
+	return &NotificationError{Recipient: recipient, Kind: kind, Err: err}
+}
+
+// Notification is an HTML email, Attachments are optional. Key identifies the notification so it is
+// sent only once through an OutboxService, see NotificationKey, which holds it until NotBefore if set.
+// Type and EventId are matched against the preferences of the recipient.
+type Notification struct {
+	Key            string
+	Type           string
+	EventId        string
+	Recipient      string
+	Subject        string
+	Body           string
+	Attachments    []*EmailAttachment
+	UnsubscribeURL string
+	NotBefore      time.Time
 }
 
-type EmailNotificationService struct {
-	config *EmailConfig
+// Notifier delivers notifications through the channel configured, see NewNotifier.
+type Notifier interface {
+	Notify(notification *Notification) error
 }
 
-func NewEmailNotificationService(config *EmailConfig) *EmailNotificationService {
-	return &EmailNotificationService{
-		config: config,
+// NotifierConfig : Kind is ses, smtp, webhook or memory. Endpoint is the host:port of the SMTP server or
+// the URL of the webhook, Source the sender address and defaults to the one of the app.
+type NotifierConfig struct {
+	Kind     string
+	Source   string
+	Endpoint string
+	Username string
+	Password string
+	Region   string
+}
+
+// NewNotifier returns the notifier configured by config.Kind.
+func NewNotifier(config *NotifierConfig) (Notifier, error) {
+	source := config.Source
+	if source == "" {
+		source = _EMAIL_SOURCE
+	}
+	switch strings.ToLower(config.Kind) {
+	case "ses":
+		awsSession, err := session.NewSession(&aws.Config{Region: aws.String(config.Region)})
+		if err != nil {
+			return nil, err
+		}
+		return NewSESNotifier(ses.New(awsSession), source), nil
+	case "smtp":
+		if config.Endpoint == "" {
+			return nil, errors.New("smtp notifier requires an endpoint")
+		}
+		return NewSMTPNotifier(config.Endpoint, config.Username, config.Password, source), nil
+	case "webhook":
+		if config.Endpoint == "" {
+			return nil, errors.New("webhook notifier requires an endpoint")
+		}
+		return NewWebhookNotifier(config.Endpoint), nil
+	case "memory":
+		return NewMemoryNotifier(), nil
 	}
+	return nil, fmt.Errorf("unknown notifier %s", config.Kind)
+}
+
+// SESNotifier sends the notifications through Amazon SES.
+type SESNotifier struct {
+	client *ses.SES
+	source string
+}
+
+func NewSESNotifier(client *ses.SES, source string) *SESNotifier {
+	return &SESNotifier{
+		client: client,
+		source: source,
+	}
+}
+
+// Notify sends a raw email when it needs headers or attachments SES SendEmail does not support.
+func (e *SESNotifier) Notify(notification *Notification) error {
+	if len(notification.Attachments) == 0 && notification.UnsubscribeURL == "" {
+		return e.SendEmailNotification(notification.Recipient, notification.Subject, notification.Body)
+	}
+	return e.sendRaw(notification)
 }
 
 // From https://docs.aws.amazon.com/sdk-for-go/v1/developer-guide/ses-example-send-email.html
-func (e *EmailNotificationService) SendEmailNotification(recipient, subject string, body string) error {
+func (e *SESNotifier) SendEmailNotification(recipient, subject string, body string) error {
 
 	// Assemble the email.
 	input := &ses.SendEmailInput{
@@ -48,17 +181,233 @@ func (e *EmailNotificationService) SendEmailNotification(recipient, subject stri
 				Data:    aws.String(subject),
 			},
 		},
-		// TODO: Remove hardcoding.
-		Source: aws.String("app@notifications.cmymesh.com"),
+		Source: aws.String(e.source),
 	}
 
 	// Attempt to send the email.
-	result, err := e.config.SesService.SendEmail(input)
+	result, err := e.client.SendEmail(input)
 	if err != nil {
-		log.Fatal(err)
-		return err
+		return sesError(recipient, err)
 	}
 
 	log.Printf("Email Sent to address %s %v", recipient, result)
 	return nil
 }
+
+// SendEmailWithAttachments sends a raw MIME email as SES SendEmail does not support attachments.
+func (e *SESNotifier) SendEmailWithAttachments(recipient, subject, body string, attachments ...*EmailAttachment) error {
+	return e.sendRaw(&Notification{Recipient: recipient, Subject: subject, Body: body, Attachments: attachments})
+}
+
+func (e *SESNotifier) sendRaw(notification *Notification) error {
+	raw, err := rawEmail(e.source, notification)
+	if err != nil {
+		return notificationError(notification.Recipient, false, err)
+	}
+	result, err := e.client.SendRawEmail(&ses.SendRawEmailInput{
+		Destinations: []*string{aws.String(notification.Recipient)},
+		RawMessage:   &ses.RawMessage{Data: raw},
+		Source:       aws.String(e.source),
+	})
+	if err != nil {
+		return sesError(notification.Recipient, err)
+	}
+	log.Printf("Email Sent to address %s %v", notification.Recipient, result)
+	return nil
+}
+
+// sesError treats throttling and server errors as temporary, SES rejects invalid messages, addresses
+// not verified and paused accounts with a 400.
+func sesError(recipient string, err error) error {
+	var requestErr awserr.RequestFailure
+	if errors.As(err, &requestErr) {
+		return notificationError(recipient, requestErr.StatusCode() >= 500 || requestErr.Code() == "Throttling", err)
+	}
+	return notificationError(recipient, true, err)
+}
+
+// SMTPNotifier sends the notifications to a plain SMTP server, authenticating only if a username is
+// set. The server must support STARTTLS to authenticate unless it is localhost.
+type SMTPNotifier struct {
+	address  string
+	username string
+	password string
+	source   string
+}
+
+func NewSMTPNotifier(address, username, password, source string) *SMTPNotifier {
+	return &SMTPNotifier{
+		address:  address,
+		username: username,
+		password: password,
+		source:   source,
+	}
+}
+
+func (e *SMTPNotifier) Notify(notification *Notification) error {
+	raw, err := rawEmail(e.source, notification)
+	if err != nil {
+		return notificationError(notification.Recipient, false, err)
+	}
+	var auth smtp.Auth
+	if e.username != "" {
+		host, _, err := net.SplitHostPort(e.address)
+		if err != nil {
+			return notificationError(notification.Recipient, false, err)
+		}
+		auth = smtp.PlainAuth("", e.username, e.password, host)
+	}
+	if err = smtp.SendMail(e.address, auth, e.source, []string{notification.Recipient}, raw); err != nil {
+		// Only 5xx replies are permanent failures, anything else like a connection error may be retried
+		var reply *textproto.Error
+		return notificationError(notification.Recipient, !errors.As(err, &reply) || reply.Code < 500, err)
+	}
+	log.Printf("Email Sent to address %s through %s", notification.Recipient, e.address)
+	return nil
+}
+
+// WebhookNotifier posts every notification as JSON to an URL, the attachments content is base64
+// encoded. Any status other than 2xx is an error.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+type webhookAttachment struct {
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Content     []byte `json:"content"`
+}
+
+type webhookNotification struct {
+	Type           string               `json:"type"`
+	EventId        string               `json:"eventId"`
+	Recipient      string               `json:"recipient"`
+	Subject        string               `json:"subject"`
+	Body           string               `json:"body"`
+	Attachments    []*webhookAttachment `json:"attachments"`
+	UnsubscribeURL string               `json:"unsubscribeUrl"`
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (e *WebhookNotifier) Notify(notification *Notification) error {
+	payload := &webhookNotification{
+		Type:           notification.Type,
+		EventId:        notification.EventId,
+		Recipient:      notification.Recipient,
+		Subject:        notification.Subject,
+		Body:           notification.Body,
+		Attachments:    []*webhookAttachment{},
+		UnsubscribeURL: notification.UnsubscribeURL,
+	}
+	for _, attachment := range notification.Attachments {
+		payload.Attachments = append(payload.Attachments, &webhookAttachment{
+			FileName:    attachment.FileName,
+			ContentType: attachment.ContentType,
+			Content:     attachment.Content,
+		})
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return notificationError(notification.Recipient, false, err)
+	}
+	response, err := e.client.Post(e.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return notificationError(notification.Recipient, true, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		temporary := response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests
+		return notificationError(notification.Recipient, temporary, fmt.Errorf("webhook returned status %d", response.StatusCode))
+	}
+	log.Printf("Notification posted for address %s", notification.Recipient)
+	return nil
+}
+
+// MemoryNotifier records the notifications instead of sending them, for tests and local development.
+type MemoryNotifier struct {
+	notifications []*Notification
+	lock          sync.RWMutex
+}
+
+func NewMemoryNotifier() *MemoryNotifier {
+	return &MemoryNotifier{notifications: []*Notification{}}
+}
+
+func (e *MemoryNotifier) Notify(notification *Notification) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.notifications = append(e.notifications, notification)
+	log.Printf("Recorded notification for address %s", notification.Recipient)
+	return nil
+}
+
+// Notifications returns the notifications recorded so far, oldest first.
+func (e *MemoryNotifier) Notifications() []*Notification {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return append([]*Notification{}, e.notifications...)
+}
+
+// rawEmail builds a multipart/mixed message with the HTML body followed by the attachments, the
+// unsubscribe link is added as one-click List-Unsubscribe (RFC 8058).
+func rawEmail(source string, notification *Notification) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	writer := multipart.NewWriter(buf)
+	fmt.Fprintf(buf, "From: %s\r\n", source)
+	fmt.Fprintf(buf, "To: %s\r\n", notification.Recipient)
+	fmt.Fprintf(buf, "Subject: %s\r\n", mime.QEncoding.Encode(CharSet, notification.Subject))
+	if notification.UnsubscribeURL != "" {
+		fmt.Fprintf(buf, "List-Unsubscribe: <%s>\r\n", notification.UnsubscribeURL)
+		fmt.Fprintf(buf, "List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n")
+	}
+	fmt.Fprintf(buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(buf, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", writer.Boundary())
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/html; charset=" + CharSet},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = writeBase64(part, []byte(notification.Body)); err != nil {
+		return nil, err
+	}
+	for _, attachment := range notification.Attachments {
+		part, err = writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err = writeBase64(part, attachment.Content); err != nil {
+			return nil, err
+		}
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeBase64 wraps the encoded content at 76 characters as required by MIME.
+func writeBase64(w io.Writer, content []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(content)
+	for len(encoded) > 76 {
+		if _, err := fmt.Fprintf(w, "%s\r\n", encoded[:76]); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := fmt.Fprintf(w, "%s\r\n", encoded)
+	return err
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewNotifier(t *testing.T) {
+	notifier, err := NewNotifier(&NotifierConfig{Kind: "Memory"})
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if _, ok := notifier.(*MemoryNotifier); !ok {
+		t.Errorf("Expected a MemoryNotifier got %T", notifier)
+	}
+	if _, err = NewNotifier(&NotifierConfig{Kind: "webhook"}); err == nil {
+		t.Error("Expected error for a webhook without endpoint")
+	}
+	if _, err = NewNotifier(&NotifierConfig{Kind: "pigeon"}); err == nil {
+		t.Error("Expected error for an unknown notifier")
+	}
+}
+
+func TestMemoryNotifier(t *testing.T) {
+	notifier := NewMemoryNotifier()
+	for _, recipient := range []string{"ana@example.com", "luis@example.com"} {
+		if err := notifier.Notify(&Notification{Recipient: recipient, Subject: "Hello"}); err != nil {
+			t.Fatalf("Test failed with error %s", err)
+		}
+	}
+	notifications := notifier.Notifications()
+	if len(notifications) != 2 || notifications[1].Recipient != "luis@example.com" {
+		t.Errorf("Expected the notifications in order got %v", notifications)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var received webhookNotification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch received.Recipient {
+		case "unavailable@example.com":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "rejected@example.com":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL)
+	err := notifier.Notify(&Notification{
+		Recipient:   "ana@example.com",
+		Subject:     "Invitation",
+		Body:        "<p>Hello</p>",
+		Attachments: []*EmailAttachment{{FileName: "invite.ics", ContentType: "text/calendar", Content: []byte("BEGIN:VCALENDAR")}},
+	})
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if received.Subject != "Invitation" || len(received.Attachments) != 1 || string(received.Attachments[0].Content) != "BEGIN:VCALENDAR" {
+		t.Errorf("Unexpected payload %v", received)
+	}
+	err = notifier.Notify(&Notification{Recipient: "unavailable@example.com"})
+	var notificationErr *NotificationError
+	if !errors.As(err, &notificationErr) || !notificationErr.Temporary() || notificationErr.Recipient != "unavailable@example.com" {
+		t.Errorf("Expected a temporary error got %v", err)
+	}
+	if err = notifier.Notify(&Notification{Recipient: "rejected@example.com"}); !errors.Is(err, ErrNotificationRejected) {
+		t.Errorf("Expected a rejected notification got %v", err)
+	}
+	server.Close()
+	if err = notifier.Notify(&Notification{Recipient: "ana@example.com"}); !errors.Is(err, ErrNotificationUnavailable) {
+		t.Errorf("Expected an unavailable channel got %v", err)
+	}
+}
+
+func TestNotificationReport(t *testing.T) {
+	report := NewNotificationReport(time.Now())
+	report.Add(&EventNotificationReport{EventId: "1", Sent: 2, Failed: 1})
+	report.Skip(&EventSummary{Id: "2", Name: "Party"}, "nothing pending")
+	report.Add(&EventNotificationReport{EventId: "3", Sent: 1})
+	if len(report.Events) != 3 || report.Sent != 3 || report.Failed != 1 || report.Skipped != 1 {
+		t.Errorf("Unexpected totals %d sent %d failed %d skipped", report.Sent, report.Failed, report.Skipped)
+	}
+	if !report.Events[1].Skipped || report.Events[1].Reason != "nothing pending" {
+		t.Errorf("Expected the skipped event with its reason got %v", report.Events[1])
+	}
+}
+
+func TestRawEmail(t *testing.T) {
+	raw, err := rawEmail("app@example.com", &Notification{
+		Recipient:      "ana@example.com",
+		Subject:        "Invitation",
+		Body:           "<p>Hello</p>",
+		Attachments:    []*EmailAttachment{{FileName: "invite.ics", ContentType: "text/calendar; method=REQUEST", Content: []byte("BEGIN:VCALENDAR")}},
+		UnsubscribeURL: "https://example.com/unsubscribe?token=abc",
+	})
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	for _, expected := range []string{"To: ana@example.com\r\n", "Content-Type: multipart/mixed;", "attachment; filename=invite.ics",
+		"List-Unsubscribe: <https://example.com/unsubscribe?token=abc>\r\n", "List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n"} {
+		if !strings.Contains(string(raw), expected) {
+			t.Errorf("Expected %q in %q", expected, raw)
+		}
+	}
+}
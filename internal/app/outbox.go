@@ -0,0 +1,196 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// OutboxService stores every notification before sending it, so the ones failing are retried by
+// Dispatch with exponential backoff. Notifications with the same Key are sent only once. It is a
+// Notifier itself and replaces the channel in the services.
+type OutboxService interface {
+	// Notify stores the notification and attempts to send it unless held until NotBefore, the error of
+	// that attempt is returned
+	Notifier
+	// Dispatch sends the messages due at now
+	Dispatch(now time.Time) (*DispatchReport, error)
+	// Get, List and Replay are only allowed to the admins of the outbox
+	Get(admin, id string) (*OutboxMessage, error)
+	// List returns the messages with status, all of them if empty
+	List(admin, status string) ([]*OutboxMessage, error)
+	// Replay sends again a message not sent yet, its attempts start over
+	Replay(admin, id string) (*OutboxMessage, error)
+}
+
+// Outbox message status, PENDING messages are sent once due and DEAD ones only if replayed
+const (
+	OutboxPending = "PENDING"
+	OutboxSent    = "SENT"
+	OutboxDead    = "DEAD"
+)
+
+const (
+	// Attempts before a message is DEAD
+	OutboxMaxAttempts = 6
+	// Wait before the second attempt, doubled on every attempt after it
+	OutboxRetryDelay = time.Minute
+)
+
+var ErrOutboxSent = errors.New("message already sent")
+
+// OutboxMessage : Id is the Key of the notification or a random one. TimeNextAttemptOn is set when
+// an attempt starts, so an attempt interrupted before recording its result is retried as well.
+type OutboxMessage struct {
+	Id                string             `json:"id"`
+	Type              string             `json:"type"`
+	EventId           string             `json:"eventId"`
+	Recipient         string             `json:"recipient"`
+	Subject           string             `json:"subject"`
+	Body              string             `json:"body"`
+	Attachments       []*EmailAttachment `json:"attachments"`
+	UnsubscribeURL    string             `json:"unsubscribeUrl"`
+	Status            string             `json:"status"`
+	Attempts          int                `json:"attempts"`
+	LastError         string             `json:"lastError"`
+	TimeNextAttemptOn time.Time          `json:"timeNextAttemptOn"`
+	TimeSentOn        time.Time          `json:"timeSentOn"`
+	TimeCreatedOn     time.Time          `json:"timeCreatedOn"`
+	TimeUpdatedOn     time.Time          `json:"timeUpdatedOn"`
+}
+
+// DispatchReport counts the messages by the result of their attempt, Failed will be retried.
+type DispatchReport struct {
+	Sent   int `json:"sent"`
+	Failed int `json:"failed"`
+	Dead   int `json:"dead"`
+}
+
+// NewOutboxMessage returns the message of notification due at now, or at its NotBefore if later.
+func NewOutboxMessage(notification *Notification, now time.Time) (*OutboxMessage, error) {
+	id := notification.Key
+	if id == "" {
+		var err error
+		if id, err = GenerateRandomId(); err != nil {
+			return nil, err
+		}
+	}
+	nextAttemptOn := now
+	if notification.NotBefore.After(now) {
+		nextAttemptOn = notification.NotBefore
+	}
+	return &OutboxMessage{
+		Id:                id,
+		Type:              notification.Type,
+		EventId:           notification.EventId,
+		Recipient:         notification.Recipient,
+		Subject:           notification.Subject,
+		Body:              notification.Body,
+		Attachments:       notification.Attachments,
+		UnsubscribeURL:    notification.UnsubscribeURL,
+		Status:            OutboxPending,
+		TimeNextAttemptOn: nextAttemptOn,
+		TimeCreatedOn:     now,
+		TimeUpdatedOn:     now,
+	}, nil
+}
+
+// NotificationKey joins the parts identifying a notification into its idempotency key.
+func NotificationKey(parts ...string) string {
+	return strings.ToUpper(strings.Join(parts, "#"))
+}
+
+// OutboxBackoff returns the wait after the given number of attempts.
+func OutboxBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return 0
+	}
+	return OutboxRetryDelay << (attempts - 1)
+}
+
+func (m *OutboxMessage) Notification() *Notification {
+	return &Notification{
+		Key:            m.Id,
+		Type:           m.Type,
+		EventId:        m.EventId,
+		Recipient:      m.Recipient,
+		Subject:        m.Subject,
+		Body:           m.Body,
+		Attachments:    m.Attachments,
+		UnsubscribeURL: m.UnsubscribeURL,
+	}
+}
+
+// IsDue returns true if the message has to be sent at now.
+func (m *OutboxMessage) IsDue(now time.Time) bool {
+	return m.Status == OutboxPending && !now.Before(m.TimeNextAttemptOn)
+}
+
+// Claim starts an attempt, the next one is scheduled already in case this one never records its result.
+func (m *OutboxMessage) Claim(now time.Time) {
+	m.Attempts++
+	m.TimeNextAttemptOn = now.Add(OutboxBackoff(m.Attempts))
+	m.TimeUpdatedOn = now
+}
+
+// Record sets the result of the attempt, the message is DEAD if err is not temporary or there are no
+// attempts left.
+func (m *OutboxMessage) Record(err error, now time.Time) {
+	m.TimeUpdatedOn = now
+	if err == nil {
+		m.Status = OutboxSent
+		m.LastError = ""
+		m.TimeSentOn = now
+		return
+	}
+	m.LastError = err.Error()
+	var notificationErr *NotificationError
+	if errors.As(err, &notificationErr) && notificationErr.Temporary() && m.Attempts < OutboxMaxAttempts {
+		m.Status = OutboxPending
+		return
+	}
+	m.Status = OutboxDead
+}
+
+// Deliver claims the message, sends it through notifier and records the result.
+func (m *OutboxMessage) Deliver(notifier Notifier, now time.Time) error {
+	m.Claim(now)
+	err := notifier.Notify(m.Notification())
+	m.Record(err, now)
+	return err
+}
+
+// Replay makes a message not sent yet due at now with all its attempts.
+func (m *OutboxMessage) Replay(now time.Time) error {
+	if m.Status == OutboxSent {
+		return ErrOutboxSent
+	}
+	m.Status = OutboxPending
+	m.Attempts = 0
+	m.TimeNextAttemptOn = now
+	m.TimeUpdatedOn = now
+	return nil
+}
+
+// Count adds the result of the last attempt of message.
+func (r *DispatchReport) Count(message *OutboxMessage) {
+	switch message.Status {
+	case OutboxSent:
+		r.Sent++
+	case OutboxDead:
+		r.Dead++
+	default:
+		r.Failed++
+	}
+}
+
+// ParseOutboxAdmins returns the emails in a comma separated list, in upper case as every user.
+func ParseOutboxAdmins(value string) []string {
+	admins := []string{}
+	for _, admin := range strings.Split(value, ",") {
+		if admin = strings.TrimSpace(admin); admin != "" {
+			admins = append(admins, strings.ToUpper(admin))
+		}
+	}
+	return admins
+}
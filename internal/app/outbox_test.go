@@ -0,0 +1,72 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOutboxBackoff(t *testing.T) {
+	if OutboxBackoff(0) != 0 || OutboxBackoff(1) != time.Minute || OutboxBackoff(4) != 8*time.Minute {
+		t.Errorf("Unexpected backoff %s %s %s", OutboxBackoff(0), OutboxBackoff(1), OutboxBackoff(4))
+	}
+}
+
+func TestOutboxMessageRetriesTemporaryErrors(t *testing.T) {
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	message, err := NewOutboxMessage(&Notification{Key: "KEY", Recipient: "ana@example.com"}, now)
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if message.Id != "KEY" || !message.IsDue(now) {
+		t.Fatalf("Expected a message due with the key as id got %v", message)
+	}
+	unavailable := notificationError("ana@example.com", true, errors.New("throttled"))
+	for attempt := 1; attempt < OutboxMaxAttempts; attempt++ {
+		message.Claim(now)
+		message.Record(unavailable, now)
+		if message.Status != OutboxPending || !message.TimeNextAttemptOn.Equal(now.Add(OutboxBackoff(attempt))) {
+			t.Fatalf("Expected attempt %d to be retried got %s at %s", attempt, message.Status, message.TimeNextAttemptOn)
+		}
+		if message.IsDue(now) {
+			t.Fatalf("Expected attempt %d to wait for its backoff", attempt)
+		}
+	}
+	message.Claim(now)
+	message.Record(unavailable, now)
+	if message.Status != OutboxDead || message.LastError == "" {
+		t.Errorf("Expected a dead message after %d attempts got %s", OutboxMaxAttempts, message.Status)
+	}
+	if err = message.Replay(now); err != nil || message.Attempts != 0 || !message.IsDue(now) {
+		t.Errorf("Expected a replayed message to be due got %v", message)
+	}
+	message.Claim(now)
+	message.Record(nil, now)
+	if message.Status != OutboxSent || message.LastError != "" || !message.TimeSentOn.Equal(now) {
+		t.Errorf("Expected a sent message got %v", message)
+	}
+	if err = message.Replay(now); !errors.Is(err, ErrOutboxSent) {
+		t.Errorf("Expected ErrOutboxSent got %v", err)
+	}
+}
+
+func TestOutboxMessageDeadWhenRejected(t *testing.T) {
+	message, _ := NewOutboxMessage(&Notification{Recipient: "ana@example.com"}, time.Now())
+	if message.Id == "" {
+		t.Error("Expected a random id without key")
+	}
+	message.Claim(time.Now())
+	message.Record(notificationError("ana@example.com", false, errors.New("address blocked")), time.Now())
+	if message.Status != OutboxDead {
+		t.Errorf("Expected a dead message got %s", message.Status)
+	}
+}
+
+func TestNotificationKey(t *testing.T) {
+	if key := NotificationKey("PENDING_TASKS", "1", "ana@example.com", "2023W18"); key != "PENDING_TASKS#1#ANA@EXAMPLE.COM#2023W18" {
+		t.Errorf("Unexpected key %s", key)
+	}
+	if admins := ParseOutboxAdmins(" ana@example.com,, luis@example.com"); len(admins) != 2 || admins[1] != "LUIS@EXAMPLE.COM" {
+		t.Errorf("Unexpected admins %v", admins)
+	}
+}
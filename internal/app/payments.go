@@ -0,0 +1,55 @@
+package app
+
+import (
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// ScheduledPayment is a deposit or installment agreed with a vendor, Amount is in the currency of its
+// expense category. Paid is set by the owners once the payment is made, recording the actual expense
+// is up to them.
+type ScheduledPayment struct {
+	Id          string    `json:"id"`
+	Description string    `json:"description"`
+	Amount      Money     `json:"amount" validate:"gt=0"`
+	DueDate     time.Time `json:"dueDate" validate:"required"`
+	Paid        bool      `json:"isPaid"`
+}
+
+// UpcomingPayment is an unpaid ScheduledPayment of a category, Overdue when its due date already
+// passed.
+type UpcomingPayment struct {
+	CategoryId string            `json:"categoryId"`
+	Category   string            `json:"category"`
+	VendorId   string            `json:"vendorId"`
+	Currency   string            `json:"currency"`
+	Payment    *ScheduledPayment `json:"payment"`
+	Overdue    bool              `json:"isOverdue"`
+}
+
+// UpcomingPayments returns the unpaid payments of categories due before until, including the overdue
+// ones, sorted by due date. Due dates are calendar dates, overdue if before today, see
+// EventSummary.Today.
+func UpcomingPayments(categories []*ExpenseCategory, today, until time.Time) []*UpcomingPayment {
+	upcoming := []*UpcomingPayment{}
+	for _, category := range categories {
+		for _, payment := range category.Payments {
+			if payment.Paid || payment.DueDate.After(until) {
+				continue
+			}
+			upcoming = append(upcoming, &UpcomingPayment{
+				CategoryId: category.Id,
+				Category:   category.Category,
+				VendorId:   category.VendorId,
+				Currency:   category.Currency,
+				Payment:    payment,
+				Overdue:    payment.DueDate.Before(today),
+			})
+		}
+	}
+	slices.SortFunc(upcoming, func(a, b *UpcomingPayment) bool {
+		return a.Payment.DueDate.Before(b.Payment.DueDate)
+	})
+	return upcoming
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpcomingPayments(t *testing.T) {
+	now := time.Date(2023, 5, 10, 0, 0, 0, 0, time.UTC)
+	categories := []*ExpenseCategory{
+		{
+			Id:       "EXPENSE_CATEGORY-VENUE",
+			Category: "Venue",
+			Currency: "USD",
+			Payments: []*ScheduledPayment{
+				{Id: "deposit", Amount: 50000, DueDate: now.AddDate(0, -1, 0), Paid: true},
+				{Id: "second", Amount: 50000, DueDate: now.AddDate(0, 0, 5)},
+				{Id: "final", Amount: 100000, DueDate: now.AddDate(0, 2, 0)},
+			},
+		},
+		{
+			Id:       "EXPENSE_CATEGORY-FOOD",
+			Category: "Food",
+			Payments: []*ScheduledPayment{{Id: "late", Amount: 2000, DueDate: now.AddDate(0, 0, -2)}},
+		},
+	}
+	upcoming := UpcomingPayments(categories, now, now.AddDate(0, 0, 7))
+	if len(upcoming) != 2 {
+		t.Fatalf("Expected 2 payments got %d", len(upcoming))
+	}
+	if upcoming[0].Payment.Id != "late" || !upcoming[0].Overdue || upcoming[0].Category != "Food" {
+		t.Errorf("Expected the overdue payment first got %+v", upcoming[0])
+	}
+	if upcoming[1].Payment.Id != "second" || upcoming[1].Overdue {
+		t.Errorf("Unexpected payment %+v", upcoming[1])
+	}
+}
+
+func TestScheduledPaymentValidate(t *testing.T) {
+	category := &ExpenseCategory{Category: "Venue", Payments: []*ScheduledPayment{{Amount: 100}}}
+	if err := category.Validate(); err == nil {
+		t.Error("Expected error for a payment without due date")
+	}
+}
+
+func TestPendingTasksTemplateWithPayments(t *testing.T) {
+	payments := []*UpcomingPayment{{
+		Category: "Venue",
+		Currency: "USD",
+		Payment:  &ScheduledPayment{Description: "Deposit", Amount: 50000, DueDate: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)},
+		Overdue:  true,
+	}}
+	buf, err := TemplatePendingTasksNotifications(&EventSummary{Name: "Demo"}, nil, nil, payments)
+	if err != nil {
+		t.Fatalf("Expected a rendered template got %s", err)
+	}
+	if !strings.Contains(buf.String(), "500.00 USD") || !strings.Contains(buf.String(), "(overdue)") {
+		t.Errorf("Expected payments in template %s", buf.String())
+	}
+}
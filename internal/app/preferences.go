@@ -0,0 +1,249 @@
+package app
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/exp/slices"
+)
+
+// PreferencesService keeps the notification preferences of every email, owners and guests alike.
+type PreferencesService interface {
+	// Get returns the preferences of user, the defaults if there are none
+	Get(user string) (*NotificationPreferences, error)
+	CreateOrUpdate(user string, u *NotificationPreferences) (*NotificationPreferences, error)
+	// Unsubscribe disables the notifications of a signed unsubscribe token, see UnsubscribeLinks
+	Unsubscribe(token string) error
+}
+
+// Notification types
+const (
+	NotificationPendingTasks = "PENDING_TASKS"
+	NotificationBudgetAlert  = "BUDGET_ALERT"
+	NotificationMention      = "MENTION"
+	NotificationInvitation   = "INVITATION"
+	NotificationCampaign     = "CAMPAIGN"
+)
+
+// How often the pending tasks are sent at most, their schedule is set by every event, see
+// NotificationSchedule
+const (
+	DigestDaily   = "DAILY"
+	DigestWeekly  = "WEEKLY"
+	DigestMonthly = "MONTHLY"
+	DigestNone    = "NONE"
+)
+
+var (
+	ErrNotificationSuppressed = errors.New("notification disabled by the recipient")
+	ErrInvalidUnsubscribe     = errors.New("invalid unsubscribe token")
+)
+
+// NotificationPreferences : every notification is enabled unless Unsubscribed, its type is in
+// DisabledTypes or its event in MutedEventIds. QuietHours and the Digest periods are in TimeZone.
+type NotificationPreferences struct {
+	Email         string      `json:"email"`
+	Unsubscribed  bool        `json:"isUnsubscribed"`
+	DisabledTypes []string    `json:"disabledTypes" validate:"dive,oneof=PENDING_TASKS BUDGET_ALERT MENTION INVITATION CAMPAIGN"`
+	MutedEventIds []string    `json:"mutedEventIds"`
+	Digest        string      `json:"digest" validate:"omitempty,oneof=DAILY WEEKLY MONTHLY NONE"`
+	QuietHours    *QuietHours `json:"quietHours"`
+	TimeZone      string      `json:"timeZone"`
+	v             *validator.Validate
+	TimeUpdatedOn time.Time `json:"timeUpdatedOn"`
+}
+
+// QuietHours : notifications are held from Start until End, hours of the day ending the next day if
+// End is before Start. Disabled when both are the same.
+type QuietHours struct {
+	Start int `json:"start" validate:"min=0,max=23"`
+	End   int `json:"end" validate:"min=0,max=23"`
+}
+
+func DefaultPreferences(email string) *NotificationPreferences {
+	return &NotificationPreferences{
+		Email:         strings.ToLower(email),
+		DisabledTypes: []string{},
+		MutedEventIds: []string{},
+		Digest:        DigestDaily,
+	}
+}
+
+func (p *NotificationPreferences) Validate() error {
+	if p.v == nil {
+		p.v = validator.New()
+	}
+	if err := p.v.Struct(p); err != nil {
+		return err
+	}
+	if _, err := LoadTimeZone(p.TimeZone); err != nil {
+		return fmt.Errorf("invalid time zone %s", p.TimeZone)
+	}
+	return nil
+}
+
+// Allows returns true if the notification type about eventId, if any, is enabled.
+func (p *NotificationPreferences) Allows(notificationType, eventId string) bool {
+	if p.Unsubscribed || slices.Contains(p.DisabledTypes, notificationType) {
+		return false
+	}
+	if eventId != "" && slices.Contains(p.MutedEventIds, eventId) {
+		return false
+	}
+	return notificationType != NotificationPendingTasks || p.Digest != DigestNone
+}
+
+// DigestPeriod returns the period of now the pending tasks are sent once in.
+func (p *NotificationPreferences) DigestPeriod(now time.Time) string {
+	now = now.In(p.location())
+	switch p.Digest {
+	case DigestDaily:
+		return now.Format("2006-01-02")
+	case DigestMonthly:
+		return now.Format("2006-01")
+	}
+	year, week := now.ISOWeek()
+	return fmt.Sprintf("%dW%02d", year, week)
+}
+
+// QuietUntil returns when the quiet hours of now are over, the zero time if now is not in quiet hours.
+func (p *NotificationPreferences) QuietUntil(now time.Time) time.Time {
+	q := p.QuietHours
+	if q == nil || q.Start == q.End {
+		return time.Time{}
+	}
+	local := now.In(p.location())
+	hour := local.Hour()
+	quiet := hour >= q.Start && hour < q.End
+	if q.Start > q.End {
+		quiet = hour >= q.Start || hour < q.End
+	}
+	if !quiet {
+		return time.Time{}
+	}
+	end := time.Date(local.Year(), local.Month(), local.Day(), q.End, 0, 0, 0, local.Location())
+	if !end.After(local) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// Unsubscribe disables notificationType, or every notification if empty.
+func (p *NotificationPreferences) Unsubscribe(notificationType string) {
+	if notificationType == "" {
+		p.Unsubscribed = true
+		return
+	}
+	if !slices.Contains(p.DisabledTypes, notificationType) {
+		p.DisabledTypes = append(p.DisabledTypes, notificationType)
+	}
+}
+
+func (p *NotificationPreferences) location() *time.Location {
+	loc, err := LoadTimeZone(p.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// UnsubscribeLinks signs the email and notification type of the unsubscribe links with HMAC-SHA256, so
+// they work without authentication and can not be forged for other emails.
+type UnsubscribeLinks struct {
+	secret   []byte
+	endpoint string
+}
+
+// NewUnsubscribeLinks returns the links to endpoint, the absolute URL of the unsubscribe route.
+func NewUnsubscribeLinks(secret, endpoint string) (*UnsubscribeLinks, error) {
+	if len(secret) < 16 {
+		return nil, errors.New("unsubscribe secret must be at least 16 characters")
+	}
+	return &UnsubscribeLinks{secret: []byte(secret), endpoint: endpoint}, nil
+}
+
+func (l *UnsubscribeLinks) Token(email, notificationType string) string {
+	payload := []byte(strings.ToLower(email) + "\n" + notificationType)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(l.sign(payload))
+}
+
+func (l *UnsubscribeLinks) URL(email, notificationType string) string {
+	return l.endpoint + "?token=" + url.QueryEscape(l.Token(email, notificationType))
+}
+
+// Verify returns the email and notification type of a token signed by l.
+func (l *UnsubscribeLinks) Verify(token string) (string, string, error) {
+	encodedPayload, encodedSignature, found := strings.Cut(token, ".")
+	if !found {
+		return "", "", ErrInvalidUnsubscribe
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return "", "", ErrInvalidUnsubscribe
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, l.sign(payload)) {
+		return "", "", ErrInvalidUnsubscribe
+	}
+	email, notificationType, _ := strings.Cut(string(payload), "\n")
+	return email, notificationType, nil
+}
+
+func (l *UnsubscribeLinks) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// PreferencesNotifier drops the notifications disabled by the recipient and holds the ones in quiet
+// hours until they are over, the rest are sent through next with an unsubscribe link.
+type PreferencesNotifier struct {
+	preferences PreferencesService
+	links       *UnsubscribeLinks
+	next        Notifier
+}
+
+func NewPreferencesNotifier(preferences PreferencesService, links *UnsubscribeLinks, next Notifier) *PreferencesNotifier {
+	return &PreferencesNotifier{
+		preferences: preferences,
+		links:       links,
+		next:        next,
+	}
+}
+
+// Notify returns ErrNotificationSuppressed if the recipient disabled the notification.
+func (n *PreferencesNotifier) Notify(notification *Notification) error {
+	preferences, err := n.preferences.Get(notification.Recipient)
+	if err != nil {
+		return notificationError(notification.Recipient, true, err)
+	}
+	if !preferences.Allows(notification.Type, notification.EventId) {
+		log.Printf("Notification %s to %s disabled by its preferences", notification.Type, notification.Recipient)
+		return ErrNotificationSuppressed
+	}
+	if until := preferences.QuietUntil(time.Now()); until.After(notification.NotBefore) {
+		notification.NotBefore = until
+	}
+	notification.UnsubscribeURL = n.links.URL(notification.Recipient, notification.Type)
+	notification.Body = withUnsubscribeLink(notification.Body, notification.UnsubscribeURL)
+	return n.next.Notify(notification)
+}
+
+// withUnsubscribeLink adds the link at the end of the body of an HTML email.
+func withUnsubscribeLink(body, link string) string {
+	footer := `<p style="font-size: 12px; color: #999999; text-align: center;">Don't want these emails? <a href="` +
+		html.EscapeString(link) + `">Unsubscribe</a></p>`
+	if i := strings.LastIndex(strings.ToLower(body), "</body>"); i >= 0 {
+		return body[:i] + footer + body[i:]
+	}
+	return body + footer
+}
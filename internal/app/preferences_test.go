@@ -0,0 +1,143 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPreferencesAllows(t *testing.T) {
+	preferences := DefaultPreferences("Ana@Example.com")
+	if !preferences.Allows(NotificationPendingTasks, "1") || !preferences.Allows(NotificationMention, "") {
+		t.Error("Expected every notification to be enabled by default")
+	}
+	preferences.DisabledTypes = []string{NotificationBudgetAlert}
+	preferences.MutedEventIds = []string{"2"}
+	if preferences.Allows(NotificationBudgetAlert, "1") || preferences.Allows(NotificationMention, "2") {
+		t.Error("Expected disabled types and muted events to be suppressed")
+	}
+	preferences.Digest = DigestNone
+	if preferences.Allows(NotificationPendingTasks, "1") {
+		t.Error("Expected no pending tasks without digest")
+	}
+	preferences.Unsubscribe("")
+	if preferences.Allows(NotificationInvitation, "1") {
+		t.Error("Expected nothing once unsubscribed")
+	}
+}
+
+func TestPreferencesValidate(t *testing.T) {
+	preferences := DefaultPreferences("ana@example.com")
+	preferences.DisabledTypes = []string{"UNKNOWN"}
+	if preferences.Validate() == nil {
+		t.Error("Expected unknown notification types to fail")
+	}
+	preferences.DisabledTypes = []string{}
+	preferences.TimeZone = "Mars/Olympus"
+	if preferences.Validate() == nil {
+		t.Error("Expected invalid time zones to fail")
+	}
+}
+
+func TestPreferencesDigestPeriod(t *testing.T) {
+	now := time.Date(2023, 1, 2, 3, 0, 0, 0, time.UTC)
+	preferences := DefaultPreferences("ana@example.com")
+	preferences.Digest = DigestWeekly
+	if period := preferences.DigestPeriod(now); period != "2023W01" {
+		t.Errorf("Expected the ISO week got %s", period)
+	}
+	preferences.Digest = DigestDaily
+	if period := preferences.DigestPeriod(now); period != "2023-01-02" {
+		t.Errorf("Expected the day got %s", period)
+	}
+	// Still the 1st in Mexico City
+	preferences.TimeZone = "America/Mexico_City"
+	if period := preferences.DigestPeriod(now); period != "2023-01-01" {
+		t.Errorf("Expected the day in the time zone got %s", period)
+	}
+	preferences.Digest = DigestMonthly
+	if period := preferences.DigestPeriod(now); period != "2023-01" {
+		t.Errorf("Expected the month got %s", period)
+	}
+}
+
+func TestPreferencesQuietUntil(t *testing.T) {
+	preferences := DefaultPreferences("ana@example.com")
+	preferences.QuietHours = &QuietHours{Start: 22, End: 7}
+	if until := preferences.QuietUntil(time.Date(2023, 1, 2, 12, 0, 0, 0, time.UTC)); !until.IsZero() {
+		t.Errorf("Expected no quiet hours at noon got %s", until)
+	}
+	expected := time.Date(2023, 1, 3, 7, 0, 0, 0, time.UTC)
+	if until := preferences.QuietUntil(time.Date(2023, 1, 2, 23, 0, 0, 0, time.UTC)); !until.Equal(expected) {
+		t.Errorf("Expected %s before midnight got %s", expected, until)
+	}
+	if until := preferences.QuietUntil(time.Date(2023, 1, 3, 2, 0, 0, 0, time.UTC)); !until.Equal(expected) {
+		t.Errorf("Expected %s after midnight got %s", expected, until)
+	}
+}
+
+func TestUnsubscribeLinks(t *testing.T) {
+	if _, err := NewUnsubscribeLinks("short", ""); err == nil {
+		t.Error("Expected short secrets to fail")
+	}
+	links, _ := NewUnsubscribeLinks("0123456789abcdef", "https://example.com/unsubscribe")
+	if url := links.URL("Ana@Example.com", NotificationMention); !strings.HasPrefix(url, "https://example.com/unsubscribe?token=") {
+		t.Errorf("Unexpected url %s", url)
+	}
+	email, notificationType, err := links.Verify(links.Token("Ana@Example.com", NotificationMention))
+	if err != nil || email != "ana@example.com" || notificationType != NotificationMention {
+		t.Errorf("Unexpected %s %s %v", email, notificationType, err)
+	}
+	// Signed for another email
+	token := links.Token("ana@example.com", "")
+	forged := strings.Replace(token, strings.Split(token, ".")[0], strings.Split(links.Token("luis@example.com", ""), ".")[0], 1)
+	if _, _, err = links.Verify(forged); !errors.Is(err, ErrInvalidUnsubscribe) {
+		t.Errorf("Expected forged tokens to fail got %v", err)
+	}
+	other, _ := NewUnsubscribeLinks("fedcba9876543210", "")
+	if _, _, err = other.Verify(token); !errors.Is(err, ErrInvalidUnsubscribe) {
+		t.Errorf("Expected tokens of other secrets to fail got %v", err)
+	}
+}
+
+// fixedPreferences returns the same preferences for every user
+type fixedPreferences struct {
+	preferences *NotificationPreferences
+}
+
+func (p *fixedPreferences) Get(user string) (*NotificationPreferences, error) {
+	return p.preferences, nil
+}
+
+func (p *fixedPreferences) CreateOrUpdate(user string, u *NotificationPreferences) (*NotificationPreferences, error) {
+	return u, nil
+}
+
+func (p *fixedPreferences) Unsubscribe(token string) error {
+	return nil
+}
+
+func TestPreferencesNotifier(t *testing.T) {
+	links, _ := NewUnsubscribeLinks("0123456789abcdef", "https://example.com/unsubscribe")
+	preferences := DefaultPreferences("ana@example.com")
+	preferences.DisabledTypes = []string{NotificationCampaign}
+	channel := NewMemoryNotifier()
+	notifier := NewPreferencesNotifier(&fixedPreferences{preferences}, links, channel)
+
+	err := notifier.Notify(&Notification{Type: NotificationCampaign, Recipient: "ana@example.com", Body: "<html><body>Hi</body></html>"})
+	if !errors.Is(err, ErrNotificationSuppressed) {
+		t.Errorf("Expected disabled notifications to be suppressed got %v", err)
+	}
+	err = notifier.Notify(&Notification{Type: NotificationMention, Recipient: "ana@example.com", Body: "<html><body>Hi</body></html>"})
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	sent := channel.Notifications()
+	if len(sent) != 1 {
+		t.Fatalf("Expected a single notification got %d", len(sent))
+	}
+	if sent[0].UnsubscribeURL == "" || !strings.Contains(sent[0].Body, "Unsubscribe</a></p></body>") {
+		t.Errorf("Expected the unsubscribe link in %q", sent[0].Body)
+	}
+}
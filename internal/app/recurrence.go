@@ -0,0 +1,111 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FrequencyDaily   = "DAILY"
+	FrequencyWeekly  = "WEEKLY"
+	FrequencyMonthly = "MONTHLY"
+)
+
+// RecurrenceRule is the subset of RFC 5545 RRULE we support: FREQ (DAILY, WEEKLY, MONTHLY), INTERVAL,
+// COUNT and UNTIL (as YYYYMMDD or YYYYMMDDTHHMMSSZ), e.g. FREQ=WEEKLY;INTERVAL=2;COUNT=5
+type RecurrenceRule struct {
+	Frequency string
+	Interval  int
+	Count     int
+	Until     time.Time
+}
+
+// TaskOccurrence is a future occurrence of a recurring task, only used for display purposes.
+type TaskOccurrence struct {
+	Name    string
+	DueDate time.Time
+}
+
+func ParseRecurrence(rule string) (*RecurrenceRule, error) {
+	rule = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rule)), "RRULE:")
+	if rule == "" {
+		return nil, errors.New("empty recurrence rule")
+	}
+	r := &RecurrenceRule{Interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		keyValue := strings.SplitN(part, "=", 2)
+		if len(keyValue) != 2 {
+			return nil, fmt.Errorf("invalid recurrence rule part %s", part)
+		}
+		key, value := keyValue[0], keyValue[1]
+		switch key {
+		case "FREQ":
+			if value != FrequencyDaily && value != FrequencyWeekly && value != FrequencyMonthly {
+				return nil, fmt.Errorf("unsupported recurrence frequency %s", value)
+			}
+			r.Frequency = value
+		case "INTERVAL", "COUNT":
+			number, err := strconv.Atoi(value)
+			if err != nil || number < 1 {
+				return nil, fmt.Errorf("invalid recurrence %s %s", key, value)
+			}
+			if key == "INTERVAL" {
+				r.Interval = number
+			} else {
+				r.Count = number
+			}
+		case "UNTIL":
+			until, err := parseRecurrenceDate(value)
+			if err != nil {
+				return nil, err
+			}
+			r.Until = until
+		default:
+			return nil, fmt.Errorf("unsupported recurrence rule part %s", key)
+		}
+	}
+	if r.Frequency == "" {
+		return nil, errors.New("recurrence rule requires FREQ")
+	}
+	return r, nil
+}
+
+func parseRecurrenceDate(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// A date only UNTIL includes the whole day
+				date = date.Add(24*time.Hour - time.Nanosecond)
+			}
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid recurrence UNTIL %s", value)
+}
+
+// Next returns the occurrence following from.
+func (r *RecurrenceRule) Next(from time.Time) time.Time {
+	switch r.Frequency {
+	case FrequencyDaily:
+		return from.AddDate(0, 0, r.Interval)
+	case FrequencyWeekly:
+		return from.AddDate(0, 0, 7*r.Interval)
+	default:
+		return from.AddDate(0, r.Interval, 0)
+	}
+}
+
+// Allows returns true if the n-th occurrence (starting at 1) happening at the given time is within
+// the COUNT and UNTIL limits of the rule.
+func (r *RecurrenceRule) Allows(n int, at time.Time) bool {
+	if r.Count > 0 && n > r.Count {
+		return false
+	}
+	if !r.Until.IsZero() && at.After(r.Until) {
+		return false
+	}
+	return true
+}
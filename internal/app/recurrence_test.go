@@ -0,0 +1,63 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	rule, err := ParseRecurrence("RRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=5;UNTIL=20230601")
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if rule.Frequency != FrequencyWeekly || rule.Interval != 2 || rule.Count != 5 {
+		t.Errorf("Unexpected rule %v", rule)
+	}
+	if rule.Until.Format("2006-01-02") != "2023-06-01" {
+		t.Errorf("Unexpected until %s", rule.Until)
+	}
+	for _, invalid := range []string{"", "FREQ=YEARLY", "INTERVAL=2", "FREQ=DAILY;COUNT=0", "FREQ=DAILY;BYDAY=MO"} {
+		if _, err := ParseRecurrence(invalid); err == nil {
+			t.Errorf("Expected error for rule %s", invalid)
+		}
+	}
+}
+
+func TestTaskNextOccurrence(t *testing.T) {
+	due := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	task := &Task{Id: "TASK-1", Name: "Vendor check-in", Status: "DONE", DueDate: due, Recurrence: "FREQ=WEEKLY;COUNT=2"}
+
+	next, err := task.NextOccurrence(time.Time{})
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if next == nil || !next.DueDate.Equal(due.AddDate(0, 0, 7)) || next.Status != "PENDING" {
+		t.Fatalf("Unexpected next occurrence %v", next)
+	}
+	if next.RecurrenceId != "TASK-1" || next.Occurrence != 2 {
+		t.Errorf("Unexpected series %s %d", next.RecurrenceId, next.Occurrence)
+	}
+	// COUNT=2 is exhausted
+	last, err := next.NextOccurrence(time.Time{})
+	if err != nil || last != nil {
+		t.Errorf("No more occurrences expected got %v", last)
+	}
+}
+
+func TestTaskRelativeToEventDay(t *testing.T) {
+	eventDay := time.Date(2023, 6, 10, 18, 0, 0, 0, time.UTC)
+	days := 14
+	task := &Task{Name: "Send invites", Status: "PENDING", DaysBeforeEvent: &days, Recurrence: "FREQ=DAILY;INTERVAL=7"}
+	if err := task.Validate(); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	due, ok := task.ResolveDueDate(eventDay)
+	if !ok || !due.Equal(time.Date(2023, 5, 27, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected due date %s", due)
+	}
+	// Occurrences stop at the event day
+	upcoming := UpcomingOccurrences([]*Task{task}, eventDay, eventDay.AddDate(1, 0, 0))
+	if len(upcoming) != 2 {
+		t.Errorf("Expected 2 upcoming occurrences got %d", len(upcoming))
+	}
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"time"
+)
+
+// Reminder frequencies
+const (
+	ReminderDaily  = "DAILY"
+	ReminderWeekly = "WEEKLY"
+	ReminderNone   = "NONE"
+)
+
+// Reminders due longer than ReminderMaxDelay ago are skipped, so new events or ticks missed for a long
+// time do not send a late reminder.
+const ReminderMaxDelay = 24 * time.Hour
+
+// NotificationSchedule : when the pending tasks of an event are sent to its owners. The rule with the
+// lowest WithinDays the event is within applies, Frequency otherwise. Reminders are sent at Hour in the
+// time zone of the event, weekly ones on Weekday (0 is Sunday).
+type NotificationSchedule struct {
+	Frequency string          `json:"frequency" validate:"required,oneof=DAILY WEEKLY NONE"`
+	Rules     []*ReminderRule `json:"rules" validate:"dive"`
+	Weekday   time.Weekday    `json:"weekday" validate:"min=0,max=6"`
+	Hour      int             `json:"hour" validate:"min=0,max=23"`
+}
+
+// ReminderRule : the Frequency of the reminders once the event is WithinDays away or less, e.g.
+// {30, DAILY} sends them every day the last 30 days.
+type ReminderRule struct {
+	WithinDays int    `json:"withinDays" validate:"min=1"`
+	Frequency  string `json:"frequency" validate:"required,oneof=DAILY WEEKLY NONE"`
+}
+
+// DefaultNotificationSchedule of the events without one, every Monday at noon.
+var DefaultNotificationSchedule = &NotificationSchedule{Frequency: ReminderWeekly, Weekday: time.Monday, Hour: 12}
+
+// ReminderSchedule returns the NotificationSchedule of the event, the default one if it has none.
+func (e *Event) ReminderSchedule() *NotificationSchedule {
+	if e.NotificationSchedule == nil {
+		return DefaultNotificationSchedule
+	}
+	return e.NotificationSchedule
+}
+
+// FrequencyAt returns the frequency of the reminders of event at now.
+func (s *NotificationSchedule) FrequencyAt(event *EventSummary, now time.Time) string {
+	days := int(event.Date().Sub(event.Today(now)).Hours() / 24)
+	frequency, within := s.Frequency, 0
+	for _, rule := range s.Rules {
+		if days <= rule.WithinDays && (within == 0 || rule.WithinDays < within) {
+			frequency, within = rule.Frequency, rule.WithinDays
+		}
+	}
+	return frequency
+}
+
+// LastReminder returns the time of the last reminder of event due at or before now, the zero time if
+// reminders are disabled at now.
+func (s *NotificationSchedule) LastReminder(event *EventSummary, now time.Time) time.Time {
+	frequency := s.FrequencyAt(event, now)
+	if frequency == ReminderNone {
+		return time.Time{}
+	}
+	local := now.In(event.Location())
+	reminder := time.Date(local.Year(), local.Month(), local.Day(), s.Hour, 0, 0, 0, local.Location())
+	if reminder.After(local) {
+		reminder = reminder.AddDate(0, 0, -1)
+	}
+	if frequency == ReminderWeekly {
+		for reminder.Weekday() != s.Weekday {
+			reminder = reminder.AddDate(0, 0, -1)
+		}
+	}
+	return reminder
+}
+
+// IsDue returns true if a reminder of event is due at now and was not sent yet, notifiedOn is the time
+// the last reminder was sent.
+func (s *NotificationSchedule) IsDue(event *EventSummary, notifiedOn, now time.Time) bool {
+	reminder := s.LastReminder(event, now)
+	if reminder.IsZero() || now.Sub(reminder) > ReminderMaxDelay {
+		return false
+	}
+	return reminder.After(notifiedOn)
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReminderScheduleDefault(t *testing.T) {
+	event := &Event{Id: "1", Name: "Wedding", MainLocation: "Puebla", EventDay: time.Date(2023, 6, 10, 0, 0, 0, 0, time.UTC), AllDay: true}
+	if event.ReminderSchedule() != DefaultNotificationSchedule {
+		t.Error("Expected the default schedule")
+	}
+	// Monday 1st of May
+	monday := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	schedule, summary := event.ReminderSchedule(), event.ToSummary()
+	if !schedule.IsDue(summary, time.Time{}, monday.Add(10*time.Minute)) {
+		t.Error("Expected the event due on Monday at noon")
+	}
+	if schedule.IsDue(summary, time.Time{}, monday.Add(-time.Minute)) {
+		t.Error("Expected no reminder before noon")
+	}
+	if schedule.IsDue(summary, monday.Add(time.Minute), monday.Add(15*time.Minute)) {
+		t.Error("Expected a single reminder per week")
+	}
+	// Reminders due days ago are not sent late
+	if schedule.IsDue(summary, time.Time{}, monday.AddDate(0, 0, 2)) {
+		t.Error("Expected late reminders to be skipped")
+	}
+}
+
+func TestReminderScheduleRules(t *testing.T) {
+	event := &Event{
+		Id:           "1",
+		Name:         "Wedding",
+		MainLocation: "Puebla",
+		EventDay:     time.Date(2023, 6, 10, 0, 0, 0, 0, time.UTC),
+		AllDay:       true,
+		TimeZone:     "America/Mexico_City",
+		// Weekly until 30 days out, then daily and nothing the last day
+		NotificationSchedule: &NotificationSchedule{
+			Frequency: ReminderWeekly,
+			Weekday:   time.Monday,
+			Hour:      9,
+			Rules:     []*ReminderRule{{WithinDays: 30, Frequency: ReminderDaily}, {WithinDays: 1, Frequency: ReminderNone}},
+		},
+	}
+	if err := event.Validate(); err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	schedule, summary := event.ReminderSchedule(), event.ToSummary()
+	mexico, _ := LoadTimeZone("America/Mexico_City")
+	if frequency := schedule.FrequencyAt(summary, time.Date(2023, 5, 1, 9, 0, 0, 0, mexico)); frequency != ReminderWeekly {
+		t.Errorf("Expected weekly reminders 40 days out got %s", frequency)
+	}
+	// Wednesday 17th of May, 24 days out
+	wednesday := time.Date(2023, 5, 17, 9, 5, 0, 0, mexico)
+	if reminder := schedule.LastReminder(summary, wednesday); !reminder.Equal(time.Date(2023, 5, 17, 9, 0, 0, 0, mexico)) {
+		t.Errorf("Expected a daily reminder at 9 in the time zone of the event got %s", reminder)
+	}
+	if !schedule.IsDue(summary, wednesday.AddDate(0, 0, -1), wednesday) {
+		t.Error("Expected the event due every day")
+	}
+	if schedule.IsDue(summary, time.Time{}, time.Date(2023, 6, 9, 9, 5, 0, 0, mexico)) {
+		t.Error("Expected no reminder the day before")
+	}
+}
+
+func TestReminderScheduleValidate(t *testing.T) {
+	event := &Event{
+		Name:                 "Wedding",
+		MainLocation:         "Puebla",
+		EventDay:             time.Now(),
+		NotificationSchedule: &NotificationSchedule{Frequency: ReminderDaily, Rules: []*ReminderRule{{WithinDays: 0, Frequency: "HOURLY"}}},
+	}
+	if event.Validate() == nil {
+		t.Error("Expected invalid rules to fail")
+	}
+}
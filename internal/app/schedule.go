@@ -0,0 +1,144 @@
+package app
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/exp/slices"
+)
+
+// ScheduleService manages the sub-events of an event, like the rehearsal dinner, the ceremony or the
+// brunch, each one with its own venue, invited guests and RSVPs.
+type ScheduleService interface {
+	Get(eventManager, eventId, id string) (*ScheduleItem, error)
+	// List returns the schedule items ordered by StartTime
+	List(eventManager, eventId string) ([]*ScheduleItem, error)
+	CreateOrUpdate(eventManager, eventId string, u *ScheduleItem) (*ScheduleItem, error)
+	Delete(eventManager, eventId, id string) error
+	// Respond records the answer of an invited guest, replacing any previous one
+	Respond(eventManager, eventId, id string, rsvp *ScheduleRsvp) (*ScheduleItem, error)
+}
+
+// ScheduleItem : Required Name and StartTime. Every guest not attending the event is invited when
+// AllGuests is set, otherwise only GuestIds. Responses are only changed through Respond and Headcount
+// is computed out of them when reading the item.
+type ScheduleItem struct {
+	Id            string          `json:"id"`
+	Name          string          `json:"name" validate:"required"`
+	Description   string          `json:"description"`
+	Venue         string          `json:"venue"`
+	Address       string          `json:"address"`
+	StartTime     time.Time       `json:"startTime" validate:"required"`
+	EndTime       time.Time       `json:"endTime" validate:"omitempty,gtfield=StartTime"`
+	AllGuests     bool            `json:"isAllGuests"`
+	GuestIds      []string        `json:"guestIds" validate:"max=1000"`
+	Responses     []*ScheduleRsvp `json:"responses"`
+	Headcount     *Headcount      `json:"headcount" dynamodbav:"-"`
+	v             *validator.Validate
+	TimeCreatedOn time.Time `json:"timeCreatedOn"`
+	TimeUpdatedOn time.Time `json:"timeUpdatedOn"`
+}
+
+// ScheduleRsvp : Seats attending, at most the NumberOfSeats of the guest and all of them when zero.
+type ScheduleRsvp struct {
+	GuestId         string    `json:"guestId" validate:"required"`
+	Attending       bool      `json:"isAttending"`
+	Seats           int       `json:"seats" validate:"gte=0"`
+	TimeRespondedOn time.Time `json:"timeRespondedOn"`
+}
+
+// Headcount : Invited, Attending, Declined and Pending count guests, the seats count people.
+type Headcount struct {
+	Invited        int `json:"invited"`
+	InvitedSeats   int `json:"invitedSeats"`
+	Attending      int `json:"attending"`
+	AttendingSeats int `json:"attendingSeats"`
+	Declined       int `json:"declined"`
+	Pending        int `json:"pending"`
+}
+
+func (s *ScheduleItem) Validate() error {
+	if s.v == nil {
+		s.v = validator.New()
+	}
+	return s.v.Struct(s)
+}
+
+func (r *ScheduleRsvp) Validate() error {
+	return validator.New().Struct(r)
+}
+
+// CheckGuests fails if any of GuestIds is not a guest of the event.
+func (s *ScheduleItem) CheckGuests(guests []*Guest) error {
+	for _, id := range s.GuestIds {
+		if !slices.ContainsFunc(guests, func(guest *Guest) bool { return guest.Id == id }) {
+			return errors.New("invited guest not found " + id)
+		}
+	}
+	return nil
+}
+
+// Invites returns true if the guest is invited to the schedule item.
+func (s *ScheduleItem) Invites(guest *Guest) bool {
+	if s.AllGuests {
+		return !guest.NotAttending
+	}
+	return slices.Contains(s.GuestIds, guest.Id)
+}
+
+// SetResponse validates the response of guest and replaces its previous one.
+func (s *ScheduleItem) SetResponse(guest *Guest, rsvp *ScheduleRsvp) error {
+	if err := rsvp.Validate(); err != nil {
+		return err
+	}
+	if !s.Invites(guest) {
+		return errors.New("guest not invited")
+	}
+	if rsvp.Seats > guest.NumberOfSeats {
+		return errors.New("more seats than the guest has")
+	}
+	if !rsvp.Attending {
+		rsvp.Seats = 0
+	} else if rsvp.Seats == 0 {
+		rsvp.Seats = guest.NumberOfSeats
+	}
+	rsvp.TimeRespondedOn = time.Now()
+	if i := slices.IndexFunc(s.Responses, func(r *ScheduleRsvp) bool { return r.GuestId == rsvp.GuestId }); i >= 0 {
+		s.Responses[i] = rsvp
+		return nil
+	}
+	s.Responses = append(s.Responses, rsvp)
+	return nil
+}
+
+// ComputeHeadcount counts the answers of the invited guests, responses of guests no longer invited
+// are ignored.
+func (s *ScheduleItem) ComputeHeadcount(guests []*Guest) {
+	headcount := &Headcount{}
+	for _, guest := range guests {
+		if !s.Invites(guest) {
+			continue
+		}
+		headcount.Invited++
+		headcount.InvitedSeats += guest.NumberOfSeats
+		i := slices.IndexFunc(s.Responses, func(r *ScheduleRsvp) bool { return r.GuestId == guest.Id })
+		switch {
+		case i < 0:
+			headcount.Pending++
+		case s.Responses[i].Attending:
+			headcount.Attending++
+			headcount.AttendingSeats += s.Responses[i].Seats
+		default:
+			headcount.Declined++
+		}
+	}
+	s.Headcount = headcount
+}
+
+// SortSchedule orders the items by StartTime.
+func SortSchedule(items []*ScheduleItem) {
+	slices.SortStableFunc(items, func(a, b *ScheduleItem) bool {
+		return a.StartTime.Before(b.StartTime)
+	})
+}
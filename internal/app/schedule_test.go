@@ -0,0 +1,63 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleItemValidate(t *testing.T) {
+	start := time.Date(2023, 6, 10, 18, 0, 0, 0, time.UTC)
+	item := &ScheduleItem{Name: "Rehearsal dinner", StartTime: start, EndTime: start.Add(-time.Hour)}
+	if err := item.Validate(); err == nil {
+		t.Errorf("Expected an error when ending before starting")
+	}
+	item = &ScheduleItem{Name: "Rehearsal dinner", StartTime: start}
+	if err := item.Validate(); err != nil {
+		t.Errorf("Expected no error without end time got %s", err)
+	}
+}
+
+func TestScheduleItemHeadcount(t *testing.T) {
+	guests := []*Guest{
+		{Id: "GUEST-A", NumberOfSeats: 2},
+		{Id: "GUEST-B", NumberOfSeats: 4},
+		{Id: "GUEST-C", NumberOfSeats: 1},
+		{Id: "GUEST-D", NumberOfSeats: 3, NotAttending: true},
+	}
+	item := &ScheduleItem{Name: "Brunch", GuestIds: []string{"GUEST-A", "GUEST-B", "GUEST-C"}}
+	if err := item.CheckGuests(guests); err != nil {
+		t.Fatalf("Expected all guests to exist got %s", err)
+	}
+	if err := item.SetResponse(guests[0], &ScheduleRsvp{GuestId: "GUEST-A", Attending: true}); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if err := item.SetResponse(guests[1], &ScheduleRsvp{GuestId: "GUEST-B", Attending: true, Seats: 3}); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	// Changing the answer replaces the previous one
+	if err := item.SetResponse(guests[1], &ScheduleRsvp{GuestId: "GUEST-B", Attending: false, Seats: 3}); err != nil {
+		t.Fatalf("Unexpected error %s", err)
+	}
+	if err := item.SetResponse(guests[2], &ScheduleRsvp{GuestId: "GUEST-C", Attending: true, Seats: 2}); err == nil {
+		t.Errorf("Expected an error when attending with more seats than the guest has")
+	}
+	if err := item.SetResponse(guests[3], &ScheduleRsvp{GuestId: "GUEST-D", Attending: true}); err == nil {
+		t.Errorf("Expected an error when the guest is not invited")
+	}
+	item.ComputeHeadcount(guests)
+	expected := Headcount{Invited: 3, InvitedSeats: 7, Attending: 1, AttendingSeats: 2, Declined: 1, Pending: 1}
+	if *item.Headcount != expected {
+		t.Errorf("Expected %+v got %+v", expected, item.Headcount)
+	}
+
+	// Every guest attending the event is invited
+	item.AllGuests = true
+	item.ComputeHeadcount(guests)
+	if item.Headcount.Invited != 3 {
+		t.Errorf("Expected 3 invited guests got %d", item.Headcount.Invited)
+	}
+	item = &ScheduleItem{Name: "Brunch", GuestIds: []string{"GUEST-X"}}
+	if err := item.CheckGuests(guests); err == nil {
+		t.Errorf("Expected an error inviting an unknown guest")
+	}
+}
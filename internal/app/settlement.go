@@ -0,0 +1,167 @@
+package app
+
+import (
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// CostShare is the Weight of a shared email in the costs of a category, a category split 1:1 between
+// two families has two shares with Weight 1.
+type CostShare struct {
+	Email  string `json:"email" validate:"required,email"`
+	Weight int    `json:"weight" validate:"gt=0"`
+}
+
+// Settlement : every amount is in Currency. Unassigned is what was paid by someone that is not a
+// shared email of the event (including expenses recorded before PaidBy existed) and is left out.
+type Settlement struct {
+	EventId    string          `json:"eventId"`
+	Currency   string          `json:"currency"`
+	Balances   []*OwnerBalance `json:"balances"`
+	Transfers  []*Transfer     `json:"transfers"`
+	Unassigned Money           `json:"unassigned"`
+}
+
+// OwnerBalance : Net is what the owner paid minus its share of the costs, positive means the owner is
+// owed money.
+type OwnerBalance struct {
+	Email string `json:"email"`
+	Paid  Money  `json:"paid"`
+	Share Money  `json:"share"`
+	Net   Money  `json:"net"`
+}
+
+// Transfer of Amount from From to To, applying every transfer settles the event.
+type Transfer struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Amount Money  `json:"amount"`
+}
+
+// NewSettlement computes the balance of every owner out of the expenses of categories, each expense
+// is shared according to the Split of its category or equally between owners when it has none.
+func NewSettlement(event *Event, owners []string, categories []*ExpenseCategory) *Settlement {
+	settlement := &Settlement{
+		EventId:   event.Id,
+		Currency:  event.Currency(),
+		Balances:  []*OwnerBalance{},
+		Transfers: []*Transfer{},
+	}
+	balances := map[string]*OwnerBalance{}
+	balance := func(email string) *OwnerBalance {
+		email = strings.ToUpper(email)
+		if _, ok := balances[email]; !ok {
+			balances[email] = &OwnerBalance{Email: email}
+			settlement.Balances = append(settlement.Balances, balances[email])
+		}
+		return balances[email]
+	}
+	equal := []*CostShare{}
+	for _, owner := range owners {
+		balance(owner)
+		equal = append(equal, &CostShare{Email: owner, Weight: 1})
+	}
+	for _, category := range categories {
+		split := category.Split
+		if len(split) == 0 {
+			split = equal
+		}
+		for _, expense := range category.Expenses {
+			payer := strings.ToUpper(expense.PaidBy)
+			if _, ok := balances[payer]; !ok || len(split) == 0 {
+				settlement.Unassigned += expense.AmountPaidBase
+				continue
+			}
+			balances[payer].Paid += expense.AmountPaidBase
+			for i, share := range SplitMoney(expense.AmountPaidBase, split) {
+				balance(split[i].Email).Share += share
+			}
+		}
+	}
+	for _, b := range settlement.Balances {
+		b.Net = b.Paid - b.Share
+	}
+	settlement.Transfers = settle(settlement.Balances)
+	return settlement
+}
+
+// SplitMoney splits amount proportionally to the weights of shares, the cents left by rounding down
+// go to the largest remainders so the parts always add up to amount.
+func SplitMoney(amount Money, shares []*CostShare) []Money {
+	total := int64(0)
+	for _, share := range shares {
+		total += int64(share.Weight)
+	}
+	parts := make([]Money, len(shares))
+	if total == 0 {
+		return parts
+	}
+	remainders := make([]int64, len(shares))
+	assigned := Money(0)
+	for i, share := range shares {
+		product := int64(amount) * int64(share.Weight)
+		parts[i] = Money(product / total)
+		remainders[i] = product % total
+		assigned += parts[i]
+	}
+	order := make([]int, len(shares))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) bool {
+		return remainders[a] > remainders[b]
+	})
+	step := Money(1)
+	if amount < 0 {
+		step = -1
+	}
+	for i := 0; assigned != amount; i++ {
+		parts[order[i%len(order)]] += step
+		assigned += step
+	}
+	return parts
+}
+
+// settle greedily matches the largest debtor with the largest creditor, it needs at most one transfer
+// less than the number of owners with a balance.
+func settle(balances []*OwnerBalance) []*Transfer {
+	type pending struct {
+		email  string
+		amount Money
+	}
+	debtors, creditors := []*pending{}, []*pending{}
+	for _, b := range balances {
+		if b.Net < 0 {
+			debtors = append(debtors, &pending{b.Email, -b.Net})
+		} else if b.Net > 0 {
+			creditors = append(creditors, &pending{b.Email, b.Net})
+		}
+	}
+	largest := func(a, b *pending) bool {
+		if a.amount != b.amount {
+			return a.amount > b.amount
+		}
+		return a.email < b.email
+	}
+	transfers := []*Transfer{}
+	for len(debtors) > 0 && len(creditors) > 0 {
+		slices.SortFunc(debtors, largest)
+		slices.SortFunc(creditors, largest)
+		debtor, creditor := debtors[0], creditors[0]
+		amount := debtor.amount
+		if creditor.amount < amount {
+			amount = creditor.amount
+		}
+		transfers = append(transfers, &Transfer{From: debtor.email, To: creditor.email, Amount: amount})
+		debtor.amount -= amount
+		creditor.amount -= amount
+		if debtor.amount == 0 {
+			debtors = debtors[1:]
+		}
+		if creditor.amount == 0 {
+			creditors = creditors[1:]
+		}
+	}
+	return transfers
+}
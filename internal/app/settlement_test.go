@@ -0,0 +1,92 @@
+package app
+
+import (
+	"testing"
+	"testing/quick"
+)
+
+func TestNewSettlement(t *testing.T) {
+	owners := []string{"BRIDE@FAMILY.COM", "GROOM@FAMILY.COM"}
+	categories := []*ExpenseCategory{
+		{
+			// Equally split
+			Id: "EXPENSE_CATEGORY-VENUE",
+			Expenses: []*Expense{
+				{PaidBy: "bride@family.com", AmountPaidBase: 100000},
+			},
+		},
+		{
+			// The groom family pays two thirds of the food
+			Id:    "EXPENSE_CATEGORY-FOOD",
+			Split: []*CostShare{{Email: "BRIDE@FAMILY.COM", Weight: 1}, {Email: "GROOM@FAMILY.COM", Weight: 2}},
+			Expenses: []*Expense{
+				{PaidBy: "BRIDE@FAMILY.COM", AmountPaidBase: 30000},
+				{WhoPaid: "Grandma", AmountPaidBase: 5000},
+			},
+		},
+	}
+	settlement := NewSettlement(&Event{Id: "event"}, owners, categories)
+	bride, groom := settlement.Balances[0], settlement.Balances[1]
+	if bride.Paid != 130000 || bride.Share != 60000 || bride.Net != 70000 {
+		t.Errorf("Unexpected bride balance %+v", bride)
+	}
+	if groom.Paid != 0 || groom.Share != 70000 || groom.Net != -70000 {
+		t.Errorf("Unexpected groom balance %+v", groom)
+	}
+	if settlement.Unassigned != 5000 {
+		t.Errorf("Expected 50.00 unassigned got %s", settlement.Unassigned)
+	}
+	if len(settlement.Transfers) != 1 {
+		t.Fatalf("Expected 1 transfer got %d", len(settlement.Transfers))
+	}
+	transfer := settlement.Transfers[0]
+	if transfer.From != "GROOM@FAMILY.COM" || transfer.To != "BRIDE@FAMILY.COM" || transfer.Amount != 70000 {
+		t.Errorf("Unexpected transfer %+v", transfer)
+	}
+}
+
+func TestSettlementTransfersSettleBalances(t *testing.T) {
+	owners := []string{"A@X.COM", "B@X.COM", "C@X.COM", "D@X.COM"}
+	property := func(amounts [8]uint16) bool {
+		category := &ExpenseCategory{}
+		for i, amount := range amounts {
+			category.Expenses = append(category.Expenses, &Expense{PaidBy: owners[i%len(owners)], AmountPaidBase: Money(amount)})
+		}
+		settlement := NewSettlement(&Event{}, owners, []*ExpenseCategory{category})
+		net := map[string]Money{}
+		for _, balance := range settlement.Balances {
+			net[balance.Email] = balance.Net
+		}
+		for _, transfer := range settlement.Transfers {
+			net[transfer.From] += transfer.Amount
+			net[transfer.To] -= transfer.Amount
+		}
+		for _, value := range net {
+			if value != 0 {
+				return false
+			}
+		}
+		return len(settlement.Transfers) < len(owners)
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSplitMoney(t *testing.T) {
+	parts := SplitMoney(100, []*CostShare{{Weight: 1}, {Weight: 1}, {Weight: 1}})
+	if parts[0]+parts[1]+parts[2] != 100 || parts[0] != 34 || parts[2] != 33 {
+		t.Errorf("Unexpected parts %v", parts)
+	}
+	property := func(amount uint32, a, b, c uint8) bool {
+		shares := []*CostShare{{Weight: int(a) + 1}, {Weight: int(b) + 1}, {Weight: int(c) + 1}}
+		sum := Money(0)
+		for _, part := range SplitMoney(Money(amount), shares) {
+			sum += part
+		}
+		return sum == Money(amount)
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,213 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// ErrBlobNotFound is returned by BlobStorage.Stat when nothing was stored under the key
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStorage stores the content of the attachments, clients upload and download the content directly
+// using presigned URLs.
+type BlobStorage interface {
+	PresignUpload(key, contentType string, size int64, expires time.Duration) (string, error)
+	PresignDownload(key, fileName string, expires time.Duration) (string, error)
+	Stat(key string) (*BlobInfo, error)
+	Delete(key string) error
+}
+
+type BlobInfo struct {
+	Size        int64
+	ContentType string
+}
+
+// NewBlobStorage returns the storage configured by kind (s3 or file), location is the bucket or the
+// directory. endpoint is only used by s3 to point to an S3-compatible storage.
+func NewBlobStorage(kind, location, endpoint, region string) (BlobStorage, error) {
+	switch strings.ToLower(kind) {
+	case "s3":
+		config := &aws.Config{Region: aws.String(region)}
+		if endpoint != "" {
+			config.Endpoint = aws.String(endpoint)
+			config.S3ForcePathStyle = aws.Bool(true)
+		}
+		awsSession, err := session.NewSession(config)
+		if err != nil {
+			return nil, err
+		}
+		return NewS3BlobStorage(s3.New(awsSession), location), nil
+	case "file":
+		storage, err := NewFileBlobStorage(location)
+		if err != nil {
+			return nil, err
+		}
+		return storage, nil
+	}
+	return nil, fmt.Errorf("unknown blob storage %s", kind)
+}
+
+// S3BlobStorage works with S3 or any S3-compatible storage, for the latter create the client with the
+// endpoint and path style addressing.
+type S3BlobStorage struct {
+	client *s3.S3
+	bucket string
+}
+
+func NewS3BlobStorage(client *s3.S3, bucket string) *S3BlobStorage {
+	return &S3BlobStorage{
+		client: client,
+		bucket: bucket,
+	}
+}
+
+// PresignUpload signs both content type and length, so the upload fails if they do not match.
+func (s *S3BlobStorage) PresignUpload(key, contentType string, size int64, expires time.Duration) (string, error) {
+	request, _ := s.client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+	})
+	return request.Presign(expires)
+}
+
+func (s *S3BlobStorage) PresignDownload(key, fileName string, expires time.Duration) (string, error) {
+	request, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket:                     aws.String(s.bucket),
+		Key:                        aws.String(key),
+		ResponseContentDisposition: aws.String(contentDisposition(fileName)),
+	})
+	return request.Presign(expires)
+}
+
+func (s *S3BlobStorage) Stat(key string) (*BlobInfo, error) {
+	output, err := s.client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var awsErr awserr.RequestFailure
+		if errors.As(err, &awsErr) && awsErr.StatusCode() == http.StatusNotFound {
+			return nil, ErrBlobNotFound
+		}
+		return nil, err
+	}
+	return &BlobInfo{
+		Size:        aws.Int64Value(output.ContentLength),
+		ContentType: aws.StringValue(output.ContentType),
+	}, nil
+}
+
+func (s *S3BlobStorage) Delete(key string) error {
+	_, err := s.client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// FileBlobStorage stores blobs under a local directory and returns file:// URLs, it is meant for tests
+// and local development only as nothing is actually signed.
+type FileBlobStorage struct {
+	root string
+}
+
+func NewFileBlobStorage(root string) (*FileBlobStorage, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(root, 0750); err != nil {
+		return nil, err
+	}
+	return &FileBlobStorage{root: root}, nil
+}
+
+func (s *FileBlobStorage) PresignUpload(key, contentType string, size int64, expires time.Duration) (string, error) {
+	return s.url(key)
+}
+
+func (s *FileBlobStorage) PresignDownload(key, fileName string, expires time.Duration) (string, error) {
+	return s.url(key)
+}
+
+// Stat detects the content type out of the content as files have no metadata.
+func (s *FileBlobStorage) Stat(key string) (*BlobInfo, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	head := make([]byte, 512)
+	n, err := file.Read(head)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return &BlobInfo{Size: info.Size(), ContentType: http.DetectContentType(head[:n])}, nil
+}
+
+func (s *FileBlobStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// path returns where the content of key is stored, it is also where clients upload to.
+func (s *FileBlobStorage) path(key string) (string, error) {
+	path := filepath.Join(s.root, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, s.root+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid key %s", key)
+	}
+	return path, nil
+}
+
+func (s *FileBlobStorage) url(key string) (string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return "", err
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String(), nil
+}
+
+func contentDisposition(fileName string) string {
+	return fmt.Sprintf("attachment; filename=%q", strings.Map(func(r rune) rune {
+		// Keep the header valid, the original name is still in the metadata
+		if r < 0x20 || r > 0x7e {
+			return '_'
+		}
+		return r
+	}, fileName))
+}
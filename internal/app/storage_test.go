@@ -0,0 +1,30 @@
+package app
+
+import (
+	"testing"
+)
+
+func TestFileBlobStorageRejectsKeysOutsideRoot(t *testing.T) {
+	storage, err := NewFileBlobStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if _, err = storage.PresignUpload("../../etc/passwd", "application/pdf", 1, 0); err == nil {
+		t.Error("Expected error for a key outside of the root")
+	}
+	if _, err = storage.Stat("event/missing"); err != ErrBlobNotFound {
+		t.Errorf("Expected ErrBlobNotFound got %v", err)
+	}
+}
+
+func TestAttachmentLimitsCheck(t *testing.T) {
+	if err := DefaultAttachmentLimits.Check("Image/PNG; charset=binary", 1024); err != nil {
+		t.Errorf("Test failed with error %s", err)
+	}
+	if err := DefaultAttachmentLimits.Check("text/html", 1024); err == nil {
+		t.Error("Expected error for a content type not allowed")
+	}
+	if err := DefaultAttachmentLimits.Check("application/pdf", DefaultAttachmentLimits.MaxSize+1); err == nil {
+		t.Error("Expected error for an attachment over the size limit")
+	}
+}
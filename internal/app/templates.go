@@ -2,10 +2,16 @@ package app
 
 import (
 	"bytes"
-	"log"
+	"errors"
+	"fmt"
+	htmlTemplate "html/template"
 	"text/template"
+	"time"
 )
 
+// ErrTemplate is wrapped by the errors rendering a notification
+var ErrTemplate = errors.New("unable to render template")
+
 // I had to do this as a quick workaround to embed the content in the final binary :()
 const TEMPLATE = `
 <!DOCTYPE html>
@@ -33,6 +39,7 @@ const TEMPLATE = `
             <tr>
               <th style="border-bottom: 2px solid #dddddd;">Name</th>
               <th style="border-bottom: 2px solid #dddddd;">Status</th>
+              <th style="border-bottom: 2px solid #dddddd;">Due</th>
               <th style="border-bottom: 2px solid #dddddd;">Created</th>
             </tr>
           </thead>
@@ -41,14 +48,253 @@ const TEMPLATE = `
             <tr>
               <td>{{.Name}}</td>
               <td style="color: #ffc107; padding: 5px 10px; border-radius: 3px;">{{.Status}}</td>
-              <td>{{.TimeCreatedOn}}</td>
+              <td>{{if not .DueDate.IsZero}}{{date .DueDate}}{{end}}</td>
+              <td>{{datetime .TimeCreatedOn}}</td>
+            </tr>
+            {{end}}
+          </tbody>
+
+        </table>
+      </td>
+    </tr>
+    {{if .Upcoming}}
+    <tr>
+      <td style="padding: 20px;">
+        <h2 style="margin: 0 0 10px 0;">Upcoming</h2>
+        <table border="0" cellpadding="10" cellspacing="0" width="100%">
+          <thead>
+            <tr>
+              <th style="border-bottom: 2px solid #dddddd;">Name</th>
+              <th style="border-bottom: 2px solid #dddddd;">Due</th>
+            </tr>
+          </thead>
+          <tbody>
+            {{range .Upcoming}}
+            <tr>
+              <td>{{.Name}}</td>
+              <td>{{date .DueDate}}</td>
+            </tr>
+            {{end}}
+          </tbody>
+        </table>
+      </td>
+    </tr>
+    {{end}}
+    {{if .Payments}}
+    <tr>
+      <td style="padding: 20px;">
+        <h2 style="margin: 0 0 10px 0;">Payments due</h2>
+        <table border="0" cellpadding="10" cellspacing="0" width="100%">
+          <thead>
+            <tr>
+              <th style="border-bottom: 2px solid #dddddd;">Category</th>
+              <th style="border-bottom: 2px solid #dddddd;">Description</th>
+              <th style="border-bottom: 2px solid #dddddd;">Amount</th>
+              <th style="border-bottom: 2px solid #dddddd;">Due</th>
+            </tr>
+          </thead>
+          <tbody>
+            {{range .Payments}}
+            <tr>
+              <td>{{.Category}}</td>
+              <td>{{.Payment.Description}}</td>
+              <td>{{.Payment.Amount}} {{.Currency}}</td>
+              <td{{if .Overdue}} style="color: #dc3545;"{{end}}>{{date .Payment.DueDate}}{{if .Overdue}} (overdue){{end}}</td>
+            </tr>
+            {{end}}
+          </tbody>
+        </table>
+      </td>
+    </tr>
+    {{end}}
+  </table>
+
+</body>
+
+</html>
+`
+
+// Comment bodies are user input so this template is rendered with html/template
+const MENTION_TEMPLATE = `
+<!DOCTYPE html>
+<html>
+
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>You were mentioned</title>
+</head>
+
+<body
+  style="margin: 0; padding: 0; font-family: 'Helvetica Neue', Arial, sans-serif, sans-serif; background-color: #f4f4f4;">
+  <table align="center" border="0" cellpadding="0" cellspacing="0" width="600"
+    style="border-collapse: collapse; margin: 20px auto; background-color: #ffffff; border: 1px solid #dddddd; box-shadow: 0px 0px 10px rgba(0, 0, 0, 0.1);">
+    <tr>
+      <td style="padding: 20px; text-align: left; background-color: #9494b8; color: white;">
+        <h1 style="margin: 0;">{{.Author}} mentioned you in: {{.EventName}}</h1>
+      </td>
+    </tr>
+    <tr>
+      <td style="padding: 20px;">
+        <p style="margin: 0 0 10px 0; color: #666666;">On {{.EntityType}}</p>
+        <p style="margin: 0; white-space: pre-wrap;">{{.Body}}</p>
+      </td>
+    </tr>
+  </table>
+
+</body>
+
+</html>
+`
+
+// Category names are user input so this template is rendered with html/template
+const BUDGET_ALERT_TEMPLATE = `
+<!DOCTYPE html>
+<html>
+
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Budget alert</title>
+</head>
+
+<body
+  style="margin: 0; padding: 0; font-family: 'Helvetica Neue', Arial, sans-serif, sans-serif; background-color: #f4f4f4;">
+  <table align="center" border="0" cellpadding="0" cellspacing="0" width="600"
+    style="border-collapse: collapse; margin: 20px auto; background-color: #ffffff; border: 1px solid #dddddd; box-shadow: 0px 0px 10px rgba(0, 0, 0, 0.1);">
+    <tr>
+      <td style="padding: 20px; text-align: left; background-color: #9494b8; color: white;">
+        <h1 style="margin: 0;">Budget alert for: {{.EventName}}</h1>
+      </td>
+    </tr>
+    <tr>
+      <td style="padding: 20px;">
+        <table border="0" cellpadding="10" cellspacing="0" width="100%">
+          <thead>
+            <tr>
+              <th style="border-bottom: 2px solid #dddddd;">Budget</th>
+              <th style="border-bottom: 2px solid #dddddd;">Amount</th>
+              <th style="border-bottom: 2px solid #dddddd;">Limit</th>
+              <th style="border-bottom: 2px solid #dddddd;">Reached</th>
+            </tr>
+          </thead>
+          <tbody>
+            {{range .Alerts}}
+            <tr>
+              <td>{{if .Category}}{{.Category}}{{else}}Event{{end}} {{.Metric}}</td>
+              <td>{{.Amount}} {{$.Currency}}</td>
+              <td>{{.Limit}} {{$.Currency}}</td>
+              <td style="color: #dc3545;">{{.Threshold}}%</td>
+            </tr>
+            {{end}}
+          </tbody>
+        </table>
+      </td>
+    </tr>
+  </table>
+
+</body>
+
+</html>
+`
+
+// Event, guest and schedule names are user input so this template is rendered with html/template
+const INVITATION_TEMPLATE = `
+<!DOCTYPE html>
+<html>
+
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>You are invited</title>
+</head>
+
+<body
+  style="margin: 0; padding: 0; font-family: 'Helvetica Neue', Arial, sans-serif, sans-serif; background-color: #f4f4f4;">
+  <table align="center" border="0" cellpadding="0" cellspacing="0" width="600"
+    style="border-collapse: collapse; margin: 20px auto; background-color: #ffffff; border: 1px solid #dddddd; box-shadow: 0px 0px 10px rgba(0, 0, 0, 0.1);">
+    <tr>
+      <td style="padding: 20px; text-align: left; background-color: #9494b8; color: white;">
+        <h1 style="margin: 0;">You are invited to: {{.EventName}}</h1>
+      </td>
+    </tr>
+    <tr>
+      <td style="padding: 20px;">
+        <p style="margin: 0 0 10px 0;">Dear {{.GuestName}},</p>
+        <p style="margin: 0 0 10px 0;">{{if .AllDay}}{{date .EventDay}}{{else}}{{datetime .EventDay}}{{end}} at {{.Location}}</p>
+        {{if .Description}}<p style="margin: 0; white-space: pre-wrap;">{{.Description}}</p>{{end}}
+      </td>
+    </tr>
+    {{if .Schedule}}
+    <tr>
+      <td style="padding: 20px;">
+        <h2 style="margin: 0 0 10px 0;">Schedule</h2>
+        <table border="0" cellpadding="10" cellspacing="0" width="100%">
+          <tbody>
+            {{range .Schedule}}
+            <tr>
+              <td>{{.Name}}</td>
+              <td>{{datetime .StartTime}}</td>
+              <td>{{.Venue}}</td>
             </tr>
             {{end}}
           </tbody>
+        </table>
+      </td>
+    </tr>
+    {{end}}
+  </table>
+
+</body>
+
+</html>
+`
+
+// Campaigns to guests, Message is written by the owners so it is rendered with html/template as well
+const CAMPAIGN_TEMPLATE = `
+<!DOCTYPE html>
+<html>
+
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Heading}}</title>
+</head>
 
+<body
+  style="margin: 0; padding: 0; font-family: 'Helvetica Neue', Arial, sans-serif, sans-serif; background-color: #f4f4f4;">
+  <table align="center" border="0" cellpadding="0" cellspacing="0" width="600"
+    style="border-collapse: collapse; margin: 20px auto; background-color: #ffffff; border: 1px solid #dddddd; box-shadow: 0px 0px 10px rgba(0, 0, 0, 0.1);">
+    <tr>
+      <td style="padding: 20px; text-align: left; background-color: #9494b8; color: white;">
+        <h1 style="margin: 0;">{{.Heading}}</h1>
+      </td>
+    </tr>
+    <tr>
+      <td style="padding: 20px;">
+        <p style="margin: 0 0 10px 0;">Dear {{.GuestName}},</p>
+        <p style="margin: 0 0 10px 0;">{{.EventName}}, {{if .AllDay}}{{date .EventDay}}{{else}}{{datetime .EventDay}}{{end}} at {{.Location}}</p>
+        {{if .Message}}<p style="margin: 0; white-space: pre-wrap;">{{.Message}}</p>{{end}}
+      </td>
+    </tr>
+    {{if .Schedule}}
+    <tr>
+      <td style="padding: 20px;">
+        <h2 style="margin: 0 0 10px 0;">Schedule</h2>
+        <table border="0" cellpadding="10" cellspacing="0" width="100%">
+          <tbody>
+            {{range .Schedule}}
+            <tr>
+              <td>{{.Name}}</td>
+              <td>{{datetime .StartTime}}</td>
+              <td>{{.Venue}}</td>
+            </tr>
+            {{end}}
+          </tbody>
         </table>
       </td>
     </tr>
+    {{end}}
   </table>
 
 </body>
@@ -56,33 +302,233 @@ const TEMPLATE = `
 </html>
 `
 
+// The token comes from the query of the link so this template is rendered with html/template. Opening
+// the link only shows the form, mail clients and scanners following links do not unsubscribe anyone.
+const UNSUBSCRIBE_TEMPLATE = `
+<!DOCTYPE html>
+<html>
+
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Unsubscribe</title>
+</head>
+
+<body
+  style="margin: 0; padding: 0; font-family: 'Helvetica Neue', Arial, sans-serif, sans-serif; background-color: #f4f4f4;">
+  <table align="center" border="0" cellpadding="0" cellspacing="0" width="600"
+    style="border-collapse: collapse; margin: 20px auto; background-color: #ffffff; border: 1px solid #dddddd; box-shadow: 0px 0px 10px rgba(0, 0, 0, 0.1);">
+    <tr>
+      <td style="padding: 20px; text-align: left; background-color: #9494b8; color: white;">
+        <h1 style="margin: 0;">Unsubscribe</h1>
+      </td>
+    </tr>
+    <tr>
+      <td style="padding: 20px;">
+        <p style="margin: 0 0 10px 0;">Do you want to stop receiving these emails?</p>
+        <form method="post" action="?token={{.Token}}">
+          <button type="submit">Unsubscribe</button>
+        </form>
+      </td>
+    </tr>
+  </table>
+
+</body>
+
+</html>
+`
+
+type UnsubscribeTemplate struct {
+	Token string
+}
+
+type CampaignTemplate struct {
+	Heading   string
+	EventName string
+	GuestName string
+	EventDay  time.Time
+	AllDay    bool
+	Location  string
+	Message   string
+	Schedule  []*ScheduleItem
+}
+
+type InvitationTemplate struct {
+	EventName   string
+	GuestName   string
+	EventDay    time.Time
+	AllDay      bool
+	Location    string
+	Description string
+	Schedule    []*ScheduleItem
+}
+
+type BudgetAlertTemplate struct {
+	EventName string
+	Currency  string
+	Alerts    []*BudgetAlert
+}
+
+type CommentMentionTemplate struct {
+	EventName  string
+	Author     string
+	EntityType string
+	Body       string
+}
+
 type EventTasksTemplate struct {
 	EventName string
 	Tasks     []Task
+	Upcoming  []TaskOccurrence
+	Payments  []UpcomingPayment
 }
 
-func TemplatePendingTasksNotifications(eventName string, tasks []*Task) *bytes.Buffer {
+// TemplatePendingTasksNotifications renders the calendar dates as they are and the other times in the
+// time zone of the event, see FormatDate and FormatDateTime.
+func TemplatePendingTasksNotifications(event *EventSummary, tasks []*Task, upcoming []*TaskOccurrence, payments []*UpcomingPayment) (*bytes.Buffer, error) {
 
-	temp, err := template.New("pending-tasks").Parse(TEMPLATE)
+	loc := event.Location()
+	temp, err := template.New("pending-tasks").Funcs(template.FuncMap{
+		"date":     FormatDate,
+		"datetime": func(t time.Time) string { return FormatDateTime(t, loc) },
+	}).Parse(TEMPLATE)
 	if err != nil {
-		log.Fatal(err)
-		return nil
+		return nil, fmt.Errorf("%w pending-tasks: %s", ErrTemplate, err)
 	}
 	// Copy pointers into objects
 	dataTasks := make([]Task, len(tasks))
 	for i, ptr := range tasks {
 		dataTasks[i] = *ptr
 	}
+	dataUpcoming := make([]TaskOccurrence, len(upcoming))
+	for i, ptr := range upcoming {
+		dataUpcoming[i] = *ptr
+	}
+	dataPayments := make([]UpcomingPayment, len(payments))
+	for i, ptr := range payments {
+		dataPayments[i] = *ptr
+	}
 	// prepare data
 	data := EventTasksTemplate{
-		EventName: eventName,
+		EventName: event.Name,
 		Tasks:     dataTasks,
+		Upcoming:  dataUpcoming,
+		Payments:  dataPayments,
 	}
 	buf := new(bytes.Buffer)
 	err = temp.Execute(buf, data)
 	if err != nil {
-		log.Fatal(err)
-		return nil
+		return nil, fmt.Errorf("%w pending-tasks: %s", ErrTemplate, err)
+	}
+	return buf, nil
+}
+
+func TemplateCommentMentionNotification(eventName string, comment *Comment) (*bytes.Buffer, error) {
+	temp, err := htmlTemplate.New("comment-mention").Parse(MENTION_TEMPLATE)
+	if err != nil {
+		return nil, err
+	}
+	data := CommentMentionTemplate{
+		EventName:  eventName,
+		Author:     comment.Author,
+		EntityType: comment.EntityType,
+		Body:       comment.Body,
+	}
+	buf := new(bytes.Buffer)
+	if err = temp.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func TemplateBudgetAlertNotification(eventName, currency string, alerts []*BudgetAlert) (*bytes.Buffer, error) {
+	temp, err := htmlTemplate.New("budget-alert").Parse(BUDGET_ALERT_TEMPLATE)
+	if err != nil {
+		return nil, err
+	}
+	data := BudgetAlertTemplate{
+		EventName: eventName,
+		Currency:  currency,
+		Alerts:    alerts,
+	}
+	buf := new(bytes.Buffer)
+	if err = temp.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// TemplateInvitation renders the invitation of guest to event and the schedule items the guest is
+// invited to, dates are rendered in the time zone of the event.
+func TemplateInvitation(event *Event, guest *Guest, schedule []*ScheduleItem) (*bytes.Buffer, error) {
+	loc := event.ToSummary().Location()
+	temp, err := htmlTemplate.New("invitation").Funcs(htmlTemplate.FuncMap{
+		"date":     FormatDate,
+		"datetime": func(t time.Time) string { return FormatDateTime(t, loc) },
+	}).Parse(INVITATION_TEMPLATE)
+	if err != nil {
+		return nil, err
+	}
+	data := InvitationTemplate{
+		EventName:   event.Name,
+		GuestName:   guest.FirstName + " " + guest.LastName,
+		EventDay:    event.EventDay,
+		AllDay:      event.AllDay,
+		Location:    event.MainLocation,
+		Description: event.Description,
+		Schedule:    schedule,
+	}
+	buf := new(bytes.Buffer)
+	if err = temp.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// TemplateCampaign renders the email of campaign to guest, the schedule is only listed in invitations
+// and reminders.
+func TemplateCampaign(campaign *Campaign, event *Event, guest *Guest, schedule []*ScheduleItem) (*bytes.Buffer, error) {
+	loc := event.ToSummary().Location()
+	temp, err := htmlTemplate.New("campaign").Funcs(htmlTemplate.FuncMap{
+		"date":     FormatDate,
+		"datetime": func(t time.Time) string { return FormatDateTime(t, loc) },
+	}).Parse(CAMPAIGN_TEMPLATE)
+	if err != nil {
+		return nil, err
+	}
+	data := CampaignTemplate{
+		Heading:   "You are invited",
+		EventName: event.Name,
+		GuestName: guest.FirstName + " " + guest.LastName,
+		EventDay:  event.EventDay,
+		AllDay:    event.AllDay,
+		Location:  event.MainLocation,
+		Message:   campaign.Message,
+		Schedule:  schedule,
+	}
+	switch campaign.Template {
+	case CampaignSaveTheDate:
+		data.Heading = "Save the date"
+		data.Schedule = nil
+	case CampaignReminder:
+		data.Heading = "See you soon"
+	}
+	buf := new(bytes.Buffer)
+	if err = temp.Execute(buf, data); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// TemplateUnsubscribe renders the page confirming the unsubscribe of token, the form posts it back.
+func TemplateUnsubscribe(token string) (*bytes.Buffer, error) {
+	temp, err := htmlTemplate.New("unsubscribe").Parse(UNSUBSCRIBE_TEMPLATE)
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if err = temp.Execute(buf, UnsubscribeTemplate{Token: token}); err != nil {
+		return nil, err
 	}
-	return buf
+	return buf, nil
 }
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -18,8 +19,50 @@ func TestPendingTasksTemplate(t *testing.T) {
 			TimeCreatedOn: time.Now(),
 		},
 	}
-	buf := TemplatePendingTasksNotifications("Demo", tasks)
-	if buf == nil {
+	buf, err := TemplatePendingTasksNotifications(&EventSummary{Name: "Demo"}, tasks, nil, nil)
+	if err != nil || buf == nil {
 		t.Fail()
 	}
 }
+
+func TestPendingTasksTemplateWithUpcoming(t *testing.T) {
+	due := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	tasks := []*Task{
+		{
+			Name:          "Vendor check-in",
+			Status:        "PENDING",
+			DueDate:       due,
+			Recurrence:    "FREQ=WEEKLY;COUNT=3",
+			TimeCreatedOn: time.Now(),
+		},
+	}
+	upcoming := UpcomingOccurrences(tasks, time.Time{}, due.AddDate(0, 1, 0))
+	buf, err := TemplatePendingTasksNotifications(&EventSummary{Name: "Demo"}, tasks, upcoming, nil)
+	if err != nil {
+		t.Fatalf("Expected a rendered template got %s", err)
+	}
+	if !strings.Contains(buf.String(), "Upcoming") || !strings.Contains(buf.String(), "May 15, 2023") {
+		t.Errorf("Expected upcoming occurrences in template %s", buf.String())
+	}
+}
+
+func TestInvitationTemplateEventDay(t *testing.T) {
+	// Starts at midnight UTC, the evening before in Mexico City
+	event := &Event{Name: "Wedding", MainLocation: "Puebla", EventDay: time.Date(2023, 6, 10, 0, 0, 0, 0, time.UTC), TimeZone: "America/Mexico_City"}
+	guest := &Guest{FirstName: "Ana", LastName: "Lopez"}
+	buf, err := TemplateInvitation(event, guest, nil)
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if !strings.Contains(buf.String(), "Jun 09, 2023 18:00 CST at Puebla") {
+		t.Errorf("Expected the time of the event in its time zone %s", buf.String())
+	}
+	event.AllDay = true
+	buf, err = TemplateInvitation(event, guest, nil)
+	if err != nil {
+		t.Fatalf("Test failed with error %s", err)
+	}
+	if !strings.Contains(buf.String(), "Jun 10, 2023 at Puebla") {
+		t.Errorf("Expected the day of the all-day event %s", buf.String())
+	}
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"time"
+	// The Lambda runtime does not ship the IANA time zone database
+	_ "time/tzdata"
+)
+
+// Calendar dates, like the day of an all-day event or the due date of a task, are stored at midnight
+// UTC of the local date and compared against Today in the time zone of the event. Any other time is an
+// instant and converted to the zone of the event to be displayed.
+
+const DefaultTimeZone = "UTC"
+
+const _DATE_FORMAT = "Jan 02, 2006"
+const _DATE_TIME_FORMAT = "Jan 02, 2006 15:04 MST"
+
+// LoadTimeZone returns the location of an IANA time zone name, UTC when empty.
+func LoadTimeZone(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultTimeZone
+	}
+	return time.LoadLocation(name)
+}
+
+// CalendarDate returns the date of t in loc at midnight UTC, so it can be compared with the stored
+// calendar dates.
+func CalendarDate(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// IsCalendarDate returns true if t is a date without time, as stored by clients for dates.
+func IsCalendarDate(t time.Time) bool {
+	return t.UTC().Equal(CalendarDate(t, time.UTC))
+}
+
+// FormatDate prints a calendar date, e.g. the due date of a task or the day of an all-day event.
+func FormatDate(t time.Time) string {
+	return t.UTC().Format(_DATE_FORMAT)
+}
+
+// FormatDateTime prints an instant in loc, e.g. when an item of the schedule starts.
+func FormatDateTime(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(_DATE_TIME_FORMAT)
+}
+
+// Location returns the time zone of the event, UTC if it has none or it is unknown.
+func (e *EventSummary) Location() *time.Location {
+	loc, err := LoadTimeZone(e.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Today returns the calendar date of now in the time zone of the event.
+func (e *EventSummary) Today(now time.Time) time.Time {
+	return CalendarDate(now, e.Location())
+}
+
+// Date returns the calendar date of the event, the EventDay itself for all-day events or the date it
+// happens in the time zone of the event otherwise.
+func (e *EventSummary) Date() time.Time {
+	if e.AllDay {
+		return CalendarDate(e.EventDay, time.UTC)
+	}
+	return CalendarDate(e.EventDay, e.Location())
+}
+
+// IsUpcoming returns true if the event did not happen yet, all-day events are upcoming until their day
+// is over in the time zone of the event.
+func (e *EventSummary) IsUpcoming(now time.Time) bool {
+	if e.AllDay {
+		return !e.Date().Before(e.Today(now))
+	}
+	return e.EventDay.After(now)
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventIsUpcomingInItsTimeZone(t *testing.T) {
+	event := &EventSummary{
+		EventDay: time.Date(2023, 6, 10, 0, 0, 0, 0, time.UTC),
+		TimeZone: "America/Mexico_City",
+		AllDay:   true,
+	}
+	// 8 pm of the 10th in Mexico City is already the 11th in UTC
+	evening := time.Date(2023, 6, 11, 2, 0, 0, 0, time.UTC)
+	if !event.IsUpcoming(evening) {
+		t.Errorf("Expected the event to be upcoming until its day is over in Mexico City")
+	}
+	if event.IsUpcoming(evening.Add(6 * time.Hour)) {
+		t.Errorf("Expected the event to be over the next day")
+	}
+	// Without time zone the day is over at midnight UTC
+	event.TimeZone = ""
+	if event.IsUpcoming(evening) {
+		t.Errorf("Expected the event to be over in UTC")
+	}
+	// Not all-day events are upcoming until they start
+	event.AllDay = false
+	if event.IsUpcoming(event.EventDay.Add(time.Minute)) {
+		t.Errorf("Expected the event to be over once started")
+	}
+}
+
+func TestEventDate(t *testing.T) {
+	event := &EventSummary{
+		EventDay: time.Date(2023, 6, 11, 1, 0, 0, 0, time.UTC),
+		TimeZone: "America/Mexico_City",
+	}
+	expected := time.Date(2023, 6, 10, 0, 0, 0, 0, time.UTC)
+	if !event.Date().Equal(expected) {
+		t.Errorf("Expected %s got %s", expected, event.Date())
+	}
+	event.TimeZone = "Unknown/Zone"
+	if event.Location() != time.UTC {
+		t.Errorf("Expected UTC for an unknown time zone")
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	loc, err := LoadTimeZone("America/Mexico_City")
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := time.Date(2023, 6, 10, 0, 0, 0, 0, time.UTC)
+	if formatted := FormatDate(date); formatted != "Jun 10, 2023" {
+		t.Errorf("Expected calendar dates as they are got %s", formatted)
+	}
+	// An instant at midnight UTC is not a date
+	if formatted := FormatDateTime(date, loc); formatted != "Jun 09, 2023 18:00 CST" {
+		t.Errorf("Expected times in the event time zone got %s", formatted)
+	}
+	if formatted := FormatDateTime(date.Add(time.Hour), loc); formatted != "Jun 09, 2023 19:00 CST" {
+		t.Errorf("Expected times in the event time zone got %s", formatted)
+	}
+}
+
+func TestEventValidateTimeZone(t *testing.T) {
+	event := &Event{Name: "Wedding", MainLocation: "CDMX", EventDay: time.Now(), TimeZone: "America/Mexico_City"}
+	if err := event.Validate(); err != nil {
+		t.Errorf("Expected a valid time zone got %s", err)
+	}
+	event.TimeZone = "Mexico City"
+	if err := event.Validate(); err == nil {
+		t.Errorf("Expected an error for an invalid time zone")
+	}
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/exp/slices"
+)
+
+// VendorService manages the vendors of an event and the vendors a user shares across all of its
+// events, Shared tells them apart.
+type VendorService interface {
+	Get(eventManager, eventId, id string) (*Vendor, error)
+	// List returns the vendors of the event and the shared vendors of eventManager
+	List(eventManager, eventId string) ([]*Vendor, error)
+	CreateOrUpdate(eventManager, eventId string, u *Vendor) (*Vendor, error)
+	Delete(eventManager, eventId, id string) error
+	// Summary returns what has been paid and what is still owed to every vendor of the event
+	Summary(eventManager, eventId string) ([]*VendorSummary, error)
+}
+
+// Vendor : Required Name. Service is free text like caterer, DJ or florist, Contract is a link to the
+// signed contract and DepositTerms describes the deposit and payment terms agreed.
+type Vendor struct {
+	Id            string `json:"id"`
+	Name          string `json:"name" validate:"required"`
+	Service       string `json:"service"`
+	ContactName   string `json:"contactName"`
+	Email         string `json:"email" validate:"omitempty,email"`
+	Phone         string `json:"phone"`
+	Website       string `json:"website" validate:"omitempty,url"`
+	Contract      string `json:"contract" validate:"omitempty,url"`
+	DepositTerms  string `json:"depositTerms"`
+	Notes         string `json:"notes"`
+	Shared        bool   `json:"isShared"`
+	v             *validator.Validate
+	TimeCreatedOn time.Time `json:"timeCreatedOn"`
+	TimeUpdatedOn time.Time `json:"timeUpdatedOn"`
+}
+
+// VendorSummary : Paid is the sum of the expenses paid to the vendor, an expense is paid to the vendor
+// of its category unless it sets its own VendorId. Owed is what remains to be paid of the categories
+// linked to the vendor, all amounts are in the event base currency.
+type VendorSummary struct {
+	Vendor     *Vendor  `json:"vendor"`
+	Categories []string `json:"categories"`
+	Tasks      []string `json:"tasks"`
+	Paid       Money    `json:"paid"`
+	Owed       Money    `json:"owed"`
+}
+
+func (v *Vendor) Validate() error {
+	if v.v == nil {
+		v.v = validator.New()
+	}
+	return v.v.Struct(v)
+}
+
+// NewVendorSummaries links categories, their expenses and tasks to vendors, links to unknown
+// vendors are ignored.
+func NewVendorSummaries(vendors []*Vendor, categories []*ExpenseCategory, tasks []*Task) []*VendorSummary {
+	summaries := []*VendorSummary{}
+	byId := map[string]*VendorSummary{}
+	for _, vendor := range vendors {
+		summary := &VendorSummary{Vendor: vendor, Categories: []string{}, Tasks: []string{}}
+		byId[vendor.Id] = summary
+		summaries = append(summaries, summary)
+	}
+	for _, category := range categories {
+		if summary, ok := byId[category.VendorId]; ok {
+			summary.Categories = append(summary.Categories, category.Id)
+			summary.Owed += category.AmountRemaining
+		}
+		for _, expense := range category.Expenses {
+			vendorId := expense.VendorId
+			if vendorId == "" {
+				vendorId = category.VendorId
+			}
+			summary, ok := byId[vendorId]
+			if !ok {
+				continue
+			}
+			summary.Paid += expense.AmountPaidBase
+			if !slices.Contains(summary.Categories, category.Id) {
+				summary.Categories = append(summary.Categories, category.Id)
+			}
+		}
+	}
+	for _, task := range tasks {
+		if summary, ok := byId[task.VendorId]; ok {
+			summary.Tasks = append(summary.Tasks, task.Id)
+		}
+	}
+	return summaries
+}
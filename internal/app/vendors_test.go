@@ -0,0 +1,44 @@
+package app
+
+import (
+	"testing"
+)
+
+func TestNewVendorSummaries(t *testing.T) {
+	vendors := []*Vendor{{Id: "VENDOR-catering", Name: "Catering"}, {Id: "SHARED_VENDOR-dj", Name: "DJ", Shared: true}}
+	categories := []*ExpenseCategory{
+		{
+			Id:              "EXPENSE_CATEGORY-FOOD",
+			VendorId:        "VENDOR-catering",
+			AmountRemaining: 20000,
+			Expenses: []*Expense{
+				{Id: "1", AmountPaidBase: 50000},
+				// Tip paid directly to the DJ
+				{Id: "2", AmountPaidBase: 1000, VendorId: "SHARED_VENDOR-dj"},
+			},
+		},
+		{Id: "EXPENSE_CATEGORY-FLOWERS", VendorId: "VENDOR-unknown", AmountRemaining: 5000},
+	}
+	tasks := []*Task{{Id: "TASK-menu", VendorId: "VENDOR-catering"}, {Id: "TASK-other"}}
+
+	summaries := NewVendorSummaries(vendors, categories, tasks)
+	if len(summaries) != 2 {
+		t.Fatalf("Expected 2 summaries got %d", len(summaries))
+	}
+	catering, dj := summaries[0], summaries[1]
+	if catering.Paid != 50000 || catering.Owed != 20000 || len(catering.Tasks) != 1 || len(catering.Categories) != 1 {
+		t.Errorf("Unexpected catering summary %+v", catering)
+	}
+	if dj.Paid != 1000 || dj.Owed != 0 || len(dj.Categories) != 1 || len(dj.Tasks) != 0 {
+		t.Errorf("Unexpected DJ summary %+v", dj)
+	}
+}
+
+func TestVendorValidate(t *testing.T) {
+	if err := (&Vendor{Name: "Florist", Email: "not an email"}).Validate(); err == nil {
+		t.Error("Expected error for an invalid email")
+	}
+	if err := (&Vendor{Name: "Florist", Website: "https://florist.example.com"}).Validate(); err != nil {
+		t.Errorf("Test failed with error %s", err)
+	}
+}
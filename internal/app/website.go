@@ -0,0 +1,206 @@
+package app
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// WebsiteService manages the public page of an event. Get and CreateOrUpdate are for the owners,
+// GetPublic and Respond are called without authentication so they only expose a PublicEvent.
+type WebsiteService interface {
+	// Get returns the website settings of the event, nil if there are none
+	Get(eventManager, eventId string) (*EventWebsite, error)
+	CreateOrUpdate(eventManager, eventId string, u *EventWebsite) (*EventWebsite, error)
+	// GetPublic returns the page published under slug, nil if there is none
+	GetPublic(slug string) (*PublicEvent, error)
+	// Respond records the RSVP of the guest with the email of rsvp, source identifies the client to
+	// rate limit it
+	Respond(slug, source string, rsvp *PublicRsvp) (*PublicRsvpResult, error)
+}
+
+var (
+	ErrRsvpClosed    = errors.New("rsvp is closed")
+	ErrGuestNotFound = errors.New("guest not found")
+	ErrRateLimited   = errors.New("too many requests")
+	ErrTooManySeats  = errors.New("more seats than the guest has")
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// EventWebsite : Slug is unique across events, lowercase letters, digits and hyphens. The page is
+// only public while Published, Schedule lists the schedule items every guest is invited to when
+// ScheduleVisible and the RSVP form is open while RsvpEnabled until RsvpDeadline, if any. RSVPs are
+// recorded as PublishedBy, the owner that last saved the website.
+type EventWebsite struct {
+	EventId         string    `json:"eventId"`
+	Slug            string    `json:"slug" validate:"required,min=3,max=64"`
+	Published       bool      `json:"isPublished"`
+	Headline        string    `json:"headline" validate:"max=200"`
+	Message         string    `json:"message" validate:"max=5000"`
+	ScheduleVisible bool      `json:"isScheduleVisible"`
+	RsvpEnabled     bool      `json:"isRsvpEnabled"`
+	RsvpDeadline    time.Time `json:"rsvpDeadline"`
+	PublishedBy     string    `json:"publishedBy"`
+	v               *validator.Validate
+	TimeCreatedOn   time.Time `json:"timeCreatedOn"`
+	TimeUpdatedOn   time.Time `json:"timeUpdatedOn"`
+}
+
+// PublicEvent is the only projection of an event returned without authentication, new fields of
+// Event MUST NOT be added here unless they are meant to be public.
+type PublicEvent struct {
+	Slug         string                `json:"slug"`
+	Name         string                `json:"name"`
+	Headline     string                `json:"headline"`
+	Message      string                `json:"message"`
+	MainLocation string                `json:"mainLocation"`
+	EventDay     time.Time             `json:"eventDay"`
+	TimeZone     string                `json:"timeZone"`
+	AllDay       bool                  `json:"isAllDay"`
+	Cancelled    bool                  `json:"isCancelled"`
+	Schedule     []*PublicScheduleItem `json:"schedule"`
+	RsvpOpen     bool                  `json:"isRsvpOpen"`
+	RsvpDeadline time.Time             `json:"rsvpDeadline"`
+}
+
+type PublicScheduleItem struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Venue       string    `json:"venue"`
+	Address     string    `json:"address"`
+	StartTime   time.Time `json:"startTime"`
+	EndTime     time.Time `json:"endTime"`
+}
+
+// PublicRsvp : the guest is identified by Email, Seats attending are at most the NumberOfSeats of the
+// guest and all of them when zero.
+type PublicRsvp struct {
+	Email     string `json:"email" validate:"required,email"`
+	Attending bool   `json:"isAttending"`
+	Seats     int    `json:"seats" validate:"gte=0"`
+}
+
+// PublicRsvpResult only confirms the answer, nothing else of the guest is returned.
+type PublicRsvpResult struct {
+	FirstName string `json:"firstName"`
+	Attending bool   `json:"isAttending"`
+	Seats     int    `json:"seats"`
+}
+
+// RateLimit allows Limit calls per Window.
+type RateLimit struct {
+	Limit  int
+	Window time.Duration
+}
+
+// RSVPs allowed per client and page, and per page from any client
+var (
+	RsvpSourceRateLimit = RateLimit{Limit: 10, Window: 10 * time.Minute}
+	RsvpPageRateLimit   = RateLimit{Limit: 300, Window: time.Hour}
+)
+
+// RateLimiter counts the calls of key in fixed windows, Allow returns false once over the limit.
+type RateLimiter interface {
+	Allow(key string, limit RateLimit) (bool, error)
+}
+
+func (w *EventWebsite) Validate() error {
+	if w.v == nil {
+		w.v = validator.New()
+	}
+	if err := w.v.Struct(w); err != nil {
+		return err
+	}
+	if !slugPattern.MatchString(w.Slug) {
+		return errors.New("slug must be lowercase letters, digits and hyphens")
+	}
+	return nil
+}
+
+func (r *PublicRsvp) Validate() error {
+	return validator.New().Struct(r)
+}
+
+// IsRsvpOpen returns true if the RSVP form accepts answers at now.
+func (w *EventWebsite) IsRsvpOpen(event *Event, now time.Time) bool {
+	if !w.RsvpEnabled || event.Status == EventCancelled {
+		return false
+	}
+	return w.RsvpDeadline.IsZero() || now.Before(w.RsvpDeadline)
+}
+
+// NewPublicEvent returns the public page of event, the schedule items only some guests are invited to
+// are never listed.
+func NewPublicEvent(website *EventWebsite, event *Event, schedule []*ScheduleItem, now time.Time) *PublicEvent {
+	page := &PublicEvent{
+		Slug:         website.Slug,
+		Name:         event.Name,
+		Headline:     website.Headline,
+		Message:      website.Message,
+		MainLocation: event.MainLocation,
+		EventDay:     event.EventDay,
+		TimeZone:     event.TimeZone,
+		AllDay:       event.AllDay,
+		Cancelled:    event.Status == EventCancelled,
+		Schedule:     []*PublicScheduleItem{},
+		RsvpOpen:     website.IsRsvpOpen(event, now),
+		RsvpDeadline: website.RsvpDeadline,
+	}
+	if !website.ScheduleVisible {
+		return page
+	}
+	for _, item := range schedule {
+		if !item.AllGuests {
+			continue
+		}
+		page.Schedule = append(page.Schedule, &PublicScheduleItem{
+			Name:        item.Name,
+			Description: item.Description,
+			Venue:       item.Venue,
+			Address:     item.Address,
+			StartTime:   item.StartTime,
+			EndTime:     item.EndTime,
+		})
+	}
+	return page
+}
+
+// FindGuestByEmail returns the guest with email ignoring case, nil if there is none.
+func FindGuestByEmail(guests []*Guest, email string) *Guest {
+	for _, guest := range guests {
+		if guest.Email != "" && strings.EqualFold(guest.Email, strings.TrimSpace(email)) {
+			return guest
+		}
+	}
+	return nil
+}
+
+// Apply sets the answer of rsvp on guest, the seats confirmed are kept in ConfirmedSeats so the
+// NumberOfSeats allocated by the owners is never changed by the guest.
+// The schedule items the guest is invited to once answered are returned so the same answer is recorded
+// on them, guests not attending are no longer invited to the items of every guest.
+func (r *PublicRsvp) Apply(guest *Guest, schedule []*ScheduleItem) ([]*ScheduleItem, error) {
+	if r.Seats > guest.NumberOfSeats {
+		return nil, ErrTooManySeats
+	}
+	guest.NotAttending = !r.Attending
+	guest.Tentative = false
+	guest.TimeRespondedOn = time.Now()
+	if !r.Attending {
+		r.Seats = 0
+	} else if r.Seats == 0 {
+		r.Seats = guest.NumberOfSeats
+	}
+	guest.ConfirmedSeats = r.Seats
+	invited := []*ScheduleItem{}
+	for _, item := range schedule {
+		if item.Invites(guest) {
+			invited = append(invited, item)
+		}
+	}
+	return invited, nil
+}
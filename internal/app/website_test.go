@@ -0,0 +1,85 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWebsiteSlug(t *testing.T) {
+	for slug, valid := range map[string]bool{
+		"ana-and-luis-2023": true,
+		"wedding":           true,
+		"Ana-Luis":          false,
+		"ana--luis":         false,
+		"-ana":              false,
+		"ab":                false,
+		"ana_luis":          false,
+	} {
+		website := &EventWebsite{Slug: slug}
+		if err := website.Validate(); (err == nil) != valid {
+			t.Errorf("Expected slug %s valid %t got %v", slug, valid, err)
+		}
+	}
+}
+
+func TestPublicEventOnlyListsScheduleOfEveryGuest(t *testing.T) {
+	now := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	event := &Event{Name: "Wedding", MainLocation: "Puebla", Description: "Internal notes", EventDay: now.AddDate(0, 1, 0)}
+	schedule := []*ScheduleItem{
+		{Name: "Ceremony", Venue: "Church", AllGuests: true},
+		{Name: "Rehearsal dinner", GuestIds: []string{"GUEST-1"}},
+	}
+	website := &EventWebsite{Slug: "wedding", RsvpEnabled: true, RsvpDeadline: now.AddDate(0, 0, 10)}
+	page := NewPublicEvent(website, event, schedule, now)
+	if len(page.Schedule) != 0 {
+		t.Errorf("Expected no schedule unless visible")
+	}
+	website.ScheduleVisible = true
+	page = NewPublicEvent(website, event, schedule, now)
+	if len(page.Schedule) != 1 || page.Schedule[0].Name != "Ceremony" {
+		t.Errorf("Expected only the items of every guest got %v", page.Schedule)
+	}
+	if !page.RsvpOpen {
+		t.Errorf("Expected the rsvp to be open")
+	}
+	if NewPublicEvent(website, event, schedule, now.AddDate(0, 0, 11)).RsvpOpen {
+		t.Errorf("Expected the rsvp to be closed after the deadline")
+	}
+	event.Status = EventCancelled
+	if page = NewPublicEvent(website, event, schedule, now); page.RsvpOpen || !page.Cancelled {
+		t.Errorf("Expected cancelled events to close the rsvp")
+	}
+}
+
+func TestPublicRsvpApply(t *testing.T) {
+	guests := []*Guest{{Id: "GUEST-1", FirstName: "Ana", Email: "Ana@Example.com", NumberOfSeats: 3, Tentative: true}}
+	guest := FindGuestByEmail(guests, " ana@example.com")
+	if guest == nil {
+		t.Fatalf("Expected the guest to be found ignoring case")
+	}
+	schedule := []*ScheduleItem{
+		{Id: "SCHEDULE-1", AllGuests: true},
+		{Id: "SCHEDULE-2", GuestIds: []string{"GUEST-1"}},
+	}
+	if _, err := (&PublicRsvp{Attending: true, Seats: 4}).Apply(guest, schedule); err != ErrTooManySeats {
+		t.Errorf("Expected %v got %v", ErrTooManySeats, err)
+	}
+	rsvp := &PublicRsvp{Attending: true, Seats: 2}
+	invited, err := rsvp.Apply(guest, schedule)
+	if err != nil || len(invited) != 2 {
+		t.Fatalf("Expected both items got %v %v", invited, err)
+	}
+	if guest.NumberOfSeats != 3 || guest.ConfirmedSeats != 2 || guest.AttendingSeats() != 2 || guest.Tentative || guest.NotAttending {
+		t.Errorf("Unexpected guest %+v", guest)
+	}
+	// The allocation of the owners is kept, the guest can confirm every seat later
+	if _, err = (&PublicRsvp{Attending: true, Seats: 3}).Apply(guest, schedule); err != nil || guest.ConfirmedSeats != 3 {
+		t.Errorf("Expected every seat to be confirmed got %d %v", guest.ConfirmedSeats, err)
+	}
+	// Declining leaves the guest out of the items of every guest
+	rsvp = &PublicRsvp{Attending: false, Seats: 2}
+	invited, err = rsvp.Apply(guest, schedule)
+	if err != nil || len(invited) != 1 || invited[0].Id != "SCHEDULE-2" || rsvp.Seats != 0 {
+		t.Errorf("Expected only the item of the guest got %v %v", invited, err)
+	}
+}